@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/auth"
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+// Audit actions recorded for state-changing requests
+const (
+	auditActionDeploy       = "deploy"
+	auditActionTeardown     = "teardown"
+	auditActionScale        = "scale"
+	auditActionBenchmarkRun = "benchmark_run"
+	auditActionPause        = "pause"
+	auditActionResume       = "resume"
+	auditActionRestart      = "restart"
+	auditActionBaseline     = "baseline"
+)
+
+// defaultAuditLogRate is the maximum number of audit log lines written per second
+const defaultAuditLogRate = 50
+
+// auditLimiter caps how many audit lines are written to the log per second. Events over the
+// limit are counted and the count is reported on the next line that is written.
+type auditLimiter struct {
+	mu          sync.Mutex
+	limit       int
+	windowStart time.Time
+	count       int
+	dropped     int
+}
+
+// allow reports whether a line may be written now, along with the number dropped since the last one
+func (l *auditLimiter) allow(now time.Time) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.windowStart) >= time.Second {
+		l.windowStart = now
+		l.count = 0
+	}
+	if l.limit > 0 && l.count >= l.limit {
+		l.dropped++
+		return false, 0
+	}
+	l.count++
+	dropped := l.dropped
+	l.dropped = 0
+	return true, dropped
+}
+
+var defaultAuditLimiter = &auditLimiter{limit: envInt("AUDIT_LOG_RATE_LIMIT", defaultAuditLogRate)}
+
+// auditPersistEnabled reports whether audit events are also stored in the audit table
+func auditPersistEnabled() bool {
+	return os.Getenv("AUDIT_PERSIST") == "true"
+}
+
+// requestIdentity returns the identity the auth middleware attached to the request. Requests
+// that did not pass through it are identified by a fingerprint of their API key, so the key
+// itself never reaches the logs, or by their address when unauthenticated.
+func requestIdentity(r *http.Request) string {
+	if identity, ok := auth.FromContext(r.Context()); ok {
+		return identity.Subject
+	}
+	key := auth.APIKey(r)
+	if key == "" {
+		return "anonymous@" + r.RemoteAddr
+	}
+	return "key:" + auth.Fingerprint(key)
+}
+
+// audit writes a structured audit line for an action and, when enabled, persists it.
+// The log is rate limited; persisted events are never dropped.
+func audit(r *http.Request, dbClient *db.Client, event db.AuditEvent) {
+	event.Time = time.Now().UTC()
+	event.Identity = requestIdentity(r)
+
+	if ok, dropped := defaultAuditLimiter.allow(event.Time); ok {
+		line := struct {
+			db.AuditEvent
+			Dropped int `json:"dropped,omitempty"`
+		}{event, dropped}
+		if encoded, err := json.Marshal(line); err == nil {
+			log.Printf("audit %s", encoded)
+		}
+	}
+
+	if !auditPersistEnabled() || dbClient == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := dbClient.RecordAuditEvent(ctx, event); err != nil {
+			log.Printf("Failed to record audit event: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLimiterDropsOverLimitAndReportsCount(t *testing.T) {
+	limiter := &auditLimiter{limit: 2}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.allow(now); !ok {
+			t.Fatalf("Expected event %d to be allowed", i)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.allow(now); ok {
+			t.Fatalf("Expected event over the limit to be dropped")
+		}
+	}
+
+	ok, dropped := limiter.allow(now.Add(time.Second))
+	if !ok {
+		t.Fatalf("Expected event in the next window to be allowed")
+	}
+	if dropped != 3 {
+		t.Errorf("Expected 3 dropped events to be reported, got %d", dropped)
+	}
+}
+
+func TestRequestIdentityDoesNotLeakKey(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/v1/deploy", nil)
+	req.Header.Set("X-API-Key", "secret-key-value")
+
+	identity := requestIdentity(req)
+	if !strings.HasPrefix(identity, "key:") {
+		t.Errorf("Expected key fingerprint identity, got %s", identity)
+	}
+	if strings.Contains(identity, "secret") {
+		t.Errorf("Identity %s contains the raw API key", identity)
+	}
+
+	bearer := httptest.NewRequest("POST", "/api/v1/deploy", nil)
+	bearer.Header.Set("Authorization", "Bearer secret-key-value")
+	if got := requestIdentity(bearer); got != identity {
+		t.Errorf("Expected bearer token to map to the same identity, got %s and %s", got, identity)
+	}
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+// artifactClient fetches run artifacts from the artifact store
+var artifactClient = &http.Client{Timeout: 5 * time.Minute}
+
+// BenchmarkArtifactsZipHandler streams a zip of a run's artifacts and its config YAML.
+// Artifacts are fetched from the artifact store and written to the zip as they arrive.
+func BenchmarkArtifactsZipHandler(dbClient *db.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dbClient == nil {
+			http.Error(w, "Database not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		runID := chi.URLParam(r, "id")
+		run, err := dbClient.GetRun(r.Context(), runID)
+		if err != nil {
+			http.Error(w, "failed to get run: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if run == nil {
+			http.Error(w, "run not found", http.StatusNotFound)
+			return
+		}
+
+		var artifacts []string
+		for _, u := range []string{run.HTMLUrl, run.CSVUrl, run.RawUrl} {
+			if u != "" {
+				artifacts = append(artifacts, u)
+			}
+		}
+		if len(artifacts) == 0 {
+			http.Error(w, "run has no artifacts", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-artifacts.zip"`, runID))
+		w.WriteHeader(http.StatusOK)
+
+		zw := zip.NewWriter(w)
+		defer zw.Close()
+
+		if run.ConfigYAML != "" {
+			f, err := zw.Create("config.yaml")
+			if err != nil {
+				log.Printf("Failed to add config to artifacts zip for run %s: %v", runID, err)
+				return
+			}
+			if _, err := io.WriteString(f, run.ConfigYAML); err != nil {
+				log.Printf("Failed to write config to artifacts zip for run %s: %v", runID, err)
+				return
+			}
+		}
+
+		for _, u := range artifacts {
+			// Headers are already sent, so failures can only be logged and the zip truncated
+			if err := addArtifactToZip(r, zw, u); err != nil {
+				log.Printf("Failed to add artifact %s to zip for run %s: %v", u, runID, err)
+				return
+			}
+		}
+	}
+}
+
+// addArtifactToZip downloads an artifact and copies it into the zip under its base name
+func addArtifactToZip(r *http.Request, zw *zip.Writer, artifactURL string) error {
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, artifactURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := artifactClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("artifact store returned status %d", resp.StatusCode)
+	}
+
+	f, err := zw.Create(path.Base(req.URL.Path))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// defaultBenchmarkWarmupS approximates the harness warmup before each variant's workloads
+const defaultBenchmarkWarmupS = 30
+
+// BenchmarkEstimateVariant is the estimate for one runtime/quant variant of a run
+type BenchmarkEstimateVariant struct {
+	Runtime   string   `json:"runtime"`
+	Quant     string   `json:"quant,omitempty"`
+	GPUs      int      `json:"gpus"`
+	DurationS int      `json:"duration_s"`
+	GPUHours  float64  `json:"gpu_hours"`
+	Cost      *float64 `json:"cost,omitempty"`
+}
+
+// BenchmarkEstimateResponse is the estimated duration and resource use of a benchmark run
+type BenchmarkEstimateResponse struct {
+	DurationS int                        `json:"duration_s"`
+	GPUHours  float64                    `json:"gpu_hours"`
+	Cost      *float64                   `json:"cost,omitempty"`
+	Variants  []BenchmarkEstimateVariant `json:"variants"`
+}
+
+// gpuHourCost returns the configured price of a runtime's GPU-hour, falling back to
+// GPU_HOUR_COST, and whether any price is configured
+func gpuHourCost(configured float64) (float64, bool) {
+	if configured > 0 {
+		return configured, true
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("GPU_HOUR_COST"), 64); err == nil && v > 0 {
+		return v, true
+	}
+	return 0, false
+}
+
+// roundTo rounds v to the given number of decimal places
+func roundTo(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
+
+// estimateBenchmark estimates a run the way the harness executes it: every runtime/quant
+// variant is deployed in turn, warmed up and run through all workloads sequentially
+func estimateBenchmark(req *BenchmarkRunRequest, config *RuntimesConfig, warmupS int) (*BenchmarkEstimateResponse, error) {
+	workloadS := 0
+	for _, workload := range req.Workloads {
+		if workload.DurationS <= 0 {
+			return nil, fmt.Errorf("workload %s: duration_s must be positive", workload.Name)
+		}
+		workloadS += workload.DurationS
+	}
+
+	resp := &BenchmarkEstimateResponse{Variants: []BenchmarkEstimateVariant{}}
+	totalCost, costKnown := 0.0, false
+	for _, runtime := range req.Runtimes {
+		found := false
+		gpus, replicas, hourCost := 0, 1, 0.0
+		for _, rt := range config.Runtimes {
+			if rt.Name == runtime {
+				found = true
+				gpus, hourCost = rt.GPU, rt.GPUHourCost
+				if rt.Replicas > 0 {
+					replicas = rt.Replicas
+				}
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("runtime %s not found in config", runtime)
+		}
+
+		quants := req.Quants[runtime]
+		if len(quants) == 0 {
+			quants = []string{""}
+		}
+		for _, quant := range quants {
+			variant := BenchmarkEstimateVariant{
+				Runtime:   runtime,
+				Quant:     quant,
+				GPUs:      gpus * replicas,
+				DurationS: warmupS + workloadS,
+			}
+			gpuHours := float64(variant.GPUs) * float64(variant.DurationS) / 3600
+			variant.GPUHours = roundTo(gpuHours, 3)
+			if price, ok := gpuHourCost(hourCost); ok {
+				cost := roundTo(gpuHours*price, 2)
+				variant.Cost = &cost
+				totalCost += gpuHours * price
+				costKnown = true
+			}
+
+			resp.DurationS += variant.DurationS
+			resp.GPUHours += gpuHours
+			resp.Variants = append(resp.Variants, variant)
+		}
+	}
+
+	resp.GPUHours = roundTo(resp.GPUHours, 3)
+	if costKnown {
+		cost := roundTo(totalCost, 2)
+		resp.Cost = &cost
+	}
+	return resp, nil
+}
+
+// BenchmarkEstimateHandler estimates a benchmark run's duration, GPU-hours and cost
+// without creating the run
+func BenchmarkEstimateHandler(configPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req BenchmarkRunRequest
+		if err := decodeJSON(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Model == "" || len(req.Runtimes) == 0 || len(req.Workloads) == 0 {
+			http.Error(w, "model, runtimes, and workloads are required", http.StatusBadRequest)
+			return
+		}
+		if err := expandRampWorkloads(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		runtimes, err := loadRuntimesConfig(configPath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := validateBenchmarkQuants(&req, runtimes); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		estimate, err := estimateBenchmark(&req, runtimes, envInt("BENCHMARK_WARMUP_S", defaultBenchmarkWarmupS))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(estimate)
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestRuntimesConfig(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	testConfig := `runtimes:
+  - name: vllm
+    gpu: 2
+    quants: [fp16, int8]
+    gpu_hour_cost: 2.5
+  - name: transformers
+    gpu: 1
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "runtimes.yaml"), []byte(testConfig), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	return tempDir
+}
+
+func TestBenchmarkEstimateHandler(t *testing.T) {
+	t.Setenv("BENCHMARK_WARMUP_S", "60")
+	handler := BenchmarkEstimateHandler(writeTestRuntimesConfig(t))
+
+	body := `{"model":"test-model","runtimes":["vllm","transformers"],"quants":{"vllm":["fp16","int8"]},
+		"workloads":[{"name":"short","qps":1,"duration_s":600},{"name":"long","qps":1,"duration_s":1140}]}`
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/benchmarks/estimate", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp BenchmarkEstimateResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	// Three variants, each 60s warmup plus 1740s of workloads: half an hour apiece
+	if len(resp.Variants) != 3 || resp.DurationS != 3*1800 {
+		t.Fatalf("Expected 3 variants totalling 5400s, got %d variants and %ds", len(resp.Variants), resp.DurationS)
+	}
+	// vllm uses 2 GPUs for two half-hour variants, transformers 1 GPU for one
+	if resp.GPUHours != 2.5 {
+		t.Errorf("Expected 2.5 GPU-hours, got %v", resp.GPUHours)
+	}
+	if resp.Variants[0].Cost == nil || *resp.Variants[0].Cost != 2.5 {
+		t.Errorf("Expected a vllm variant cost of 2.5, got %v", resp.Variants[0].Cost)
+	}
+	if resp.Variants[2].Cost != nil {
+		t.Errorf("Expected no cost for a runtime without pricing, got %v", *resp.Variants[2].Cost)
+	}
+	if resp.Cost == nil || *resp.Cost != 5 {
+		t.Errorf("Expected a total cost of 5 from the priced variants, got %v", resp.Cost)
+	}
+}
+
+func TestBenchmarkEstimateHandlerRejectsUnknownRuntime(t *testing.T) {
+	handler := BenchmarkEstimateHandler(writeTestRuntimesConfig(t))
+
+	body := `{"model":"test-model","runtimes":["tgi"],"workloads":[{"name":"short","qps":1,"duration_s":60}]}`
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("POST", "/api/v1/benchmarks/estimate", strings.NewReader(body)))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
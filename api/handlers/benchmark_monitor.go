@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/db"
+	"github.com/tokenforge/llm-infra-bench/events"
+)
+
+const (
+	// runHeartbeatInterval is how often a running benchmark touches its run row
+	runHeartbeatInterval = 30 * time.Second
+	// defaultStaleRunThreshold is how long a queued/running run may go without a heartbeat
+	defaultStaleRunThreshold = 10 * time.Minute
+	// defaultStaleRunSweepInterval is how often stale runs are swept
+	defaultStaleRunSweepInterval = time.Minute
+)
+
+// harnessResults is the subset of the harness raw.json output used to record run results
+type harnessResults struct {
+	Workloads map[string][]struct {
+		Runtime   string `json:"runtime"`
+		Quant     string `json:"quant"`
+		DurationS int    `json:"duration_s"`
+		Stream    bool   `json:"stream"`
+		Summary   struct {
+			SuccessfulRequests int     `json:"successful_requests"`
+			AvgLatencyMs       float64 `json:"avg_latency_ms"`
+			P50LatencyMs       float64 `json:"p50_latency_ms"`
+			P95LatencyMs       float64 `json:"p95_latency_ms"`
+			P99LatencyMs       float64 `json:"p99_latency_ms"`
+			TokensPerSecond    float64 `json:"tokens_per_second"`
+			ErrorRate          float64 `json:"error_rate"`
+			P50TTFTMs          float64 `json:"p50_ttft_ms"`
+			P95TTFTMs          float64 `json:"p95_ttft_ms"`
+			P99TTFTMs          float64 `json:"p99_ttft_ms"`
+			P50ITLMs           float64 `json:"p50_inter_token_latency_ms"`
+			P95ITLMs           float64 `json:"p95_inter_token_latency_ms"`
+			P99ITLMs           float64 `json:"p99_inter_token_latency_ms"`
+		} `json:"summary"`
+	} `json:"workloads"`
+}
+
+// newTraceID returns a random trace ID in the W3C trace context format: 32 lowercase hex
+// characters, so it can also be propagated in a traceparent header
+func newTraceID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// runBenchmark executes the harness for a run and records its outcome in the database. The
+// harness tags every request it sends with the run and trace IDs.
+func runBenchmark(dbClient *db.Client, runID, traceID, configPath string) {
+	ctx := context.Background()
+
+	if err := dbClient.UpdateRunStatus(ctx, runID, "running", nil, nil, nil); err != nil {
+		log.Printf("Failed to mark run %s as running: %v", runID, err)
+	}
+	events.Publish(ctx, events.Event{Type: events.RunStarted, RunID: runID, Data: map[string]interface{}{"trace_id": traceID}})
+
+	cmd := exec.Command("python", "harness/run_bench.py", "--run-id", runID, "--trace-id", traceID, "--config", configPath)
+
+	// Heartbeat while the harness runs so the stale run sweeper can tell it is alive
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(runHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := dbClient.TouchRun(ctx, runID); err != nil {
+					log.Printf("Failed to record heartbeat for run %s: %v", runID, err)
+				}
+			}
+		}
+	}()
+	err := cmd.Run()
+	close(done)
+
+	if err != nil {
+		log.Printf("Benchmark run %s (trace %s) failed: %v", runID, traceID, err)
+		if err := dbClient.UpdateRunStatus(ctx, runID, "failed", nil, nil, nil); err != nil {
+			log.Printf("Failed to mark run %s as failed: %v", runID, err)
+		}
+		events.Publish(ctx, events.Event{Type: events.RunFailed, RunID: runID, Data: map[string]interface{}{"error": err.Error()}})
+		return
+	}
+
+	results, err := loadHarnessResults(filepath.Join("/tmp", runID, "raw.json"))
+	if err != nil {
+		log.Printf("Failed to load results for run %s: %v", runID, err)
+	} else if err := dbClient.SaveRunResults(ctx, runID, results); err != nil {
+		log.Printf("Failed to save results for run %s: %v", runID, err)
+	}
+
+	htmlURL, csvURL, rawURL := artifactURLs(runID)
+	if err := dbClient.UpdateRunStatus(ctx, runID, "completed", htmlURL, csvURL, rawURL); err != nil {
+		log.Printf("Failed to mark run %s as completed: %v", runID, err)
+	}
+	events.Publish(ctx, events.Event{Type: events.RunCompleted, RunID: runID})
+}
+
+// artifactURLs returns the URLs the harness uploads a run's artifacts to,
+// or nils when no artifact store is configured
+func artifactURLs(runID string) (htmlURL, csvURL, rawURL *string) {
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		return nil, nil, nil
+	}
+	bucket := os.Getenv("S3_BUCKET")
+	if bucket == "" {
+		bucket = "tokenforge-benchmarks"
+	}
+
+	artifactURL := func(name string) *string {
+		u := strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/" + runID + "/" + name
+		return &u
+	}
+	return artifactURL("report.html"), artifactURL("summary.csv"), artifactURL("raw.json")
+}
+
+// loadHarnessResults reads the harness raw results and flattens them into result summaries
+func loadHarnessResults(path string) ([]db.ResultSummary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw harnessResults
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	var results []db.ResultSummary
+	for workload, entries := range raw.Workloads {
+		for _, entry := range entries {
+			throughput := 0.0
+			if entry.DurationS > 0 {
+				throughput = float64(entry.Summary.SuccessfulRequests) / float64(entry.DurationS)
+			}
+			result := db.ResultSummary{
+				Runtime:         entry.Runtime,
+				Quant:           entry.Quant,
+				Workload:        workload,
+				AvgLatencyMs:    entry.Summary.AvgLatencyMs,
+				P50LatencyMs:    entry.Summary.P50LatencyMs,
+				P95LatencyMs:    entry.Summary.P95LatencyMs,
+				P99LatencyMs:    entry.Summary.P99LatencyMs,
+				ThroughputRPS:   throughput,
+				TokensPerSecond: entry.Summary.TokensPerSecond,
+				ErrorRate:       entry.Summary.ErrorRate,
+			}
+			// TTFT and inter-token latency only mean something for streaming workloads
+			if entry.Stream {
+				result.Streaming = &db.StreamingSummary{
+					P50TTFTMs: entry.Summary.P50TTFTMs,
+					P95TTFTMs: entry.Summary.P95TTFTMs,
+					P99TTFTMs: entry.Summary.P99TTFTMs,
+					P50ITLMs:  entry.Summary.P50ITLMs,
+					P95ITLMs:  entry.Summary.P95ITLMs,
+					P99ITLMs:  entry.Summary.P99ITLMs,
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// StartStaleRunSweeper marks runs left queued or running without a heartbeat (e.g. after
+// an API crash) as failed, once at startup and then periodically. The threshold and
+// interval are configurable via STALE_RUN_THRESHOLD and STALE_RUN_SWEEP_INTERVAL.
+func StartStaleRunSweeper(ctx context.Context, dbClient *db.Client) {
+	if dbClient == nil {
+		return
+	}
+
+	threshold := envDuration("STALE_RUN_THRESHOLD", defaultStaleRunThreshold)
+	interval := envDuration("STALE_RUN_SWEEP_INTERVAL", defaultStaleRunSweepInterval)
+
+	backgroundLoops.register("stale_run_sweeper", interval)
+	sweep := func() {
+		defer backgroundLoops.ran("stale_run_sweeper")
+		ids, err := dbClient.FailStaleRuns(ctx, threshold)
+		if err != nil {
+			log.Printf("Failed to sweep stale runs: %v", err)
+			return
+		}
+		for _, id := range ids {
+			log.Printf("Marked orphaned run %s as failed", id)
+			events.Publish(ctx, events.Event{Type: events.RunFailed, RunID: id, Data: map[string]interface{}{"error": "orphaned"}})
+		}
+	}
+
+	go func() {
+		sweep()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				sweep()
+			}
+		}
+	}()
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadHarnessResultsStreamingMetricsOnlyForStreamWorkloads(t *testing.T) {
+	raw := `{"workloads": {
+		"chat": [{"runtime": "vllm", "duration_s": 10, "stream": true, "summary": {
+			"successful_requests": 20, "p50_latency_ms": 900, "p50_ttft_ms": 120, "p95_ttft_ms": 180,
+			"p50_inter_token_latency_ms": 12, "p95_inter_token_latency_ms": 30}}],
+		"batch": [{"runtime": "vllm", "duration_s": 10, "summary": {
+			"successful_requests": 10, "p50_latency_ms": 800, "p50_ttft_ms": 99}}]
+	}}`
+	path := filepath.Join(t.TempDir(), "raw.json")
+	if err := os.WriteFile(path, []byte(raw), 0644); err != nil {
+		t.Fatalf("Failed to write raw results: %v", err)
+	}
+
+	results, err := loadHarnessResults(path)
+	if err != nil {
+		t.Fatalf("Expected results to load, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	for _, result := range results {
+		switch result.Workload {
+		case "chat":
+			if result.Streaming == nil {
+				t.Fatalf("Expected streaming metrics for the streaming workload")
+			}
+			if result.Streaming.P50TTFTMs != 120 || result.Streaming.P95ITLMs != 30 {
+				t.Errorf("Expected TTFT and ITL from the summary, got %+v", result.Streaming)
+			}
+		case "batch":
+			if result.Streaming != nil {
+				t.Errorf("Expected no streaming metrics for a non-streaming workload, got %+v", result.Streaming)
+			}
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+// defaultRegressionThresholds are the allowed regressions, in percent of the baseline, used
+// when a regression request does not set its own
+var defaultRegressionThresholds = map[string]float64{
+	"p95_latency_ms":    10,
+	"tokens_per_second": 10,
+}
+
+// RegressionRequest is the optional body of a regression check. Thresholds maps metrics to the
+// regression they may show, in percent of the baseline, and replaces the defaults when set.
+type RegressionRequest struct {
+	Thresholds map[string]float64 `json:"thresholds"`
+}
+
+// RegressionCheck compares one metric of a runtime/quant/workload combination to the baseline
+type RegressionCheck struct {
+	Runtime  string  `json:"runtime"`
+	Quant    string  `json:"quant"`
+	Workload string  `json:"workload"`
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	// RegressionPct is how much worse the run is than the baseline, in percent of the baseline;
+	// negative when it improved. It is omitted when the baseline value is 0.
+	RegressionPct *float64 `json:"regression_pct,omitempty"`
+	ThresholdPct  float64  `json:"threshold_pct"`
+	Passed        bool     `json:"passed"`
+}
+
+// RegressionReport is the result of comparing a run to its model's baseline
+type RegressionReport struct {
+	RunID         string             `json:"run_id"`
+	BaselineRunID string             `json:"baseline_run_id"`
+	Model         string             `json:"model"`
+	Passed        bool               `json:"passed"`
+	Thresholds    map[string]float64 `json:"thresholds"`
+	Checks        []RegressionCheck  `json:"checks"`
+	// Unmatched lists the runtime/quant/workload combinations only one of the runs has
+	Unmatched []string `json:"unmatched,omitempty"`
+}
+
+// resultMetric returns a metric of a result summary, or false when the result does not
+// have it, such as streaming metrics of a non-streaming workload
+func resultMetric(res db.ResultSummary, metric string) (float64, bool) {
+	switch metric {
+	case "avg_latency_ms":
+		return res.AvgLatencyMs, true
+	case "p50_latency_ms":
+		return res.P50LatencyMs, true
+	case "p95_latency_ms":
+		return res.P95LatencyMs, true
+	case "p99_latency_ms":
+		return res.P99LatencyMs, true
+	case "throughput_rps":
+		return res.ThroughputRPS, true
+	case "tokens_per_second":
+		return res.TokensPerSecond, true
+	case "error_rate":
+		return res.ErrorRate, true
+	}
+	s := res.Streaming
+	if s == nil {
+		return 0, false
+	}
+	switch metric {
+	case "p50_ttft_ms":
+		return s.P50TTFTMs, true
+	case "p95_ttft_ms":
+		return s.P95TTFTMs, true
+	case "p99_ttft_ms":
+		return s.P99TTFTMs, true
+	case "p50_itl_ms":
+		return s.P50ITLMs, true
+	case "p95_itl_ms":
+		return s.P95ITLMs, true
+	case "p99_itl_ms":
+		return s.P99ITLMs, true
+	}
+	return 0, false
+}
+
+// validateThresholds checks that thresholds only name ranked metrics and are not negative
+func validateThresholds(thresholds map[string]float64) error {
+	if len(thresholds) == 0 {
+		return errors.New("thresholds must name at least one metric")
+	}
+	for metric, pct := range thresholds {
+		if _, ok := db.ResultMetrics[metric]; !ok {
+			return fmt.Errorf("unknown metric %s", metric)
+		}
+		if pct < 0 {
+			return fmt.Errorf("threshold for %s must not be negative", metric)
+		}
+	}
+	return nil
+}
+
+// resultKey identifies a runtime/quant/workload combination across runs
+func resultKey(res db.ResultSummary) string {
+	return res.Runtime + "/" + res.Quant + "/" + res.Workload
+}
+
+// checkMetric compares a metric against the baseline. Whether an increase is a regression
+// depends on the metric; a zero baseline only passes when the run is no worse.
+func checkMetric(metric string, baseline, current, thresholdPct float64) RegressionCheck {
+	check := RegressionCheck{Metric: metric, Baseline: baseline, Current: current, ThresholdPct: thresholdPct}
+	worse := current - baseline
+	if db.ResultMetrics[metric] {
+		worse = baseline - current
+	}
+	if baseline == 0 {
+		check.Passed = worse <= 0
+		return check
+	}
+	pct := worse / baseline * 100
+	check.RegressionPct = &pct
+	check.Passed = pct <= thresholdPct
+	return check
+}
+
+// compareResults checks every threshold metric of the results the two runs have in common
+func compareResults(baseline, current []db.ResultSummary, thresholds map[string]float64) (checks []RegressionCheck, unmatched []string) {
+	metrics := make([]string, 0, len(thresholds))
+	for metric := range thresholds {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	baselineByKey := make(map[string]db.ResultSummary, len(baseline))
+	for _, res := range baseline {
+		baselineByKey[resultKey(res)] = res
+	}
+
+	checks = []RegressionCheck{}
+	matched := map[string]bool{}
+	for _, res := range current {
+		key := resultKey(res)
+		base, ok := baselineByKey[key]
+		if !ok {
+			unmatched = append(unmatched, key)
+			continue
+		}
+		matched[key] = true
+		for _, metric := range metrics {
+			baseValue, ok := resultMetric(base, metric)
+			if !ok {
+				continue
+			}
+			value, ok := resultMetric(res, metric)
+			if !ok {
+				continue
+			}
+			check := checkMetric(metric, baseValue, value, thresholds[metric])
+			check.Runtime, check.Quant, check.Workload = res.Runtime, res.Quant, res.Workload
+			checks = append(checks, check)
+		}
+	}
+	for _, res := range baseline {
+		if key := resultKey(res); !matched[key] {
+			unmatched = append(unmatched, key)
+		}
+	}
+	return checks, unmatched
+}
+
+// loadCompletedRun returns a run when it exists and has completed, writing the error response
+// and returning nil otherwise
+func loadCompletedRun(w http.ResponseWriter, r *http.Request, dbClient *db.Client, runID string) *db.Run {
+	run, err := dbClient.GetRun(r.Context(), runID)
+	if err != nil {
+		http.Error(w, "failed to get run: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	if run == nil {
+		http.Error(w, "run not found", http.StatusNotFound)
+		return nil
+	}
+	if run.Status != "completed" {
+		http.Error(w, fmt.Sprintf("run %s is %s, only completed runs can be compared", run.ID, run.Status), http.StatusConflict)
+		return nil
+	}
+	return run
+}
+
+// BenchmarkBaselineHandler marks a completed run as the baseline of its model, replacing the
+// previous baseline
+func BenchmarkBaselineHandler(dbClient *db.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dbClient == nil {
+			http.Error(w, "Database not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		runID := chi.URLParam(r, "id")
+		run := loadCompletedRun(w, r, dbClient, runID)
+		if run == nil {
+			return
+		}
+
+		baseline, err := dbClient.SetBaseline(r.Context(), run.Model, run.ID)
+		status := http.StatusOK
+		if err != nil {
+			status = http.StatusInternalServerError
+		}
+		audit(r, dbClient, db.AuditEvent{
+			Action:     auditActionBaseline,
+			Model:      run.Model,
+			RunID:      run.ID,
+			StatusCode: status,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(baseline)
+	}
+}
+
+// BenchmarkRegressionHandler compares a completed run to the baseline of its model. It responds
+// 200 with passed set to false when a metric regressed past its threshold, so CI decides on
+// the body rather than the status code.
+func BenchmarkRegressionHandler(dbClient *db.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dbClient == nil {
+			http.Error(w, "Database not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		var req RegressionRequest
+		if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		thresholds := req.Thresholds
+		if thresholds == nil {
+			thresholds = defaultRegressionThresholds
+		}
+		if err := validateThresholds(thresholds); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		run := loadCompletedRun(w, r, dbClient, chi.URLParam(r, "id"))
+		if run == nil {
+			return
+		}
+		baseline, err := dbClient.GetBaseline(r.Context(), run.Model)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if baseline == nil {
+			http.Error(w, "no baseline set for model "+run.Model, http.StatusConflict)
+			return
+		}
+
+		baselineResults, err := dbClient.GetRunResults(r.Context(), baseline.RunID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		results, err := dbClient.GetRunResults(r.Context(), run.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		checks, unmatched := compareResults(baselineResults, results, thresholds)
+		if len(checks) == 0 {
+			http.Error(w, fmt.Sprintf("run %s has no results in common with baseline %s (unmatched: %s)",
+				run.ID, baseline.RunID, strings.Join(unmatched, ", ")), http.StatusConflict)
+			return
+		}
+
+		report := RegressionReport{
+			RunID:         run.ID,
+			BaselineRunID: baseline.RunID,
+			Model:         run.Model,
+			Passed:        true,
+			Thresholds:    thresholds,
+			Checks:        checks,
+			Unmatched:     unmatched,
+		}
+		for _, check := range checks {
+			if !check.Passed {
+				report.Passed = false
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
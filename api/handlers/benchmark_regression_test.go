@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+func TestCheckMetricUsesMetricDirection(t *testing.T) {
+	cases := []struct {
+		metric            string
+		baseline, current float64
+		threshold         float64
+		wantPct           float64
+		wantPassed        bool
+	}{
+		{"p95_latency_ms", 100, 109, 10, 9, true},
+		{"p95_latency_ms", 100, 120, 10, 20, false},
+		{"p95_latency_ms", 100, 80, 10, -20, true},
+		{"tokens_per_second", 200, 170, 10, 15, false},
+		{"tokens_per_second", 200, 250, 10, -25, true},
+	}
+	for _, c := range cases {
+		check := checkMetric(c.metric, c.baseline, c.current, c.threshold)
+		if check.RegressionPct == nil || *check.RegressionPct != c.wantPct || check.Passed != c.wantPassed {
+			t.Errorf("%s %v -> %v: got %+v, want regression %v passed %v", c.metric, c.baseline, c.current, check, c.wantPct, c.wantPassed)
+		}
+	}
+}
+
+func TestCheckMetricWithZeroBaseline(t *testing.T) {
+	if check := checkMetric("error_rate", 0, 0, 5); !check.Passed || check.RegressionPct != nil {
+		t.Errorf("Expected an unchanged zero error rate to pass without a percentage, got %+v", check)
+	}
+	if check := checkMetric("error_rate", 0, 0.01, 5); check.Passed {
+		t.Errorf("Expected any errors over a zero baseline to fail, got %+v", check)
+	}
+}
+
+func TestCompareResultsMatchesCombinations(t *testing.T) {
+	baseline := []db.ResultSummary{
+		{Runtime: "vllm", Quant: "fp16", Workload: "chat", P95LatencyMs: 100, TokensPerSecond: 500},
+		{Runtime: "vllm", Quant: "fp16", Workload: "batch", P95LatencyMs: 300, TokensPerSecond: 900},
+	}
+	current := []db.ResultSummary{
+		{Runtime: "vllm", Quant: "fp16", Workload: "chat", P95LatencyMs: 130, TokensPerSecond: 510},
+		{Runtime: "tgi", Quant: "fp16", Workload: "chat", P95LatencyMs: 90, TokensPerSecond: 400},
+	}
+
+	checks, unmatched := compareResults(baseline, current, map[string]float64{"p95_latency_ms": 10, "tokens_per_second": 10, "p95_ttft_ms": 10})
+	if len(checks) != 2 {
+		t.Fatalf("Expected a check per metric of the shared result, skipping streaming metrics, got %+v", checks)
+	}
+	if checks[0].Metric != "p95_latency_ms" || checks[0].Passed || checks[0].Workload != "chat" {
+		t.Errorf("Expected the p95 regression to fail, got %+v", checks[0])
+	}
+	if checks[1].Metric != "tokens_per_second" || !checks[1].Passed {
+		t.Errorf("Expected the throughput improvement to pass, got %+v", checks[1])
+	}
+	if len(unmatched) != 2 || unmatched[0] != "tgi/fp16/chat" || unmatched[1] != "vllm/fp16/batch" {
+		t.Errorf("Expected the results only one run has to be unmatched, got %v", unmatched)
+	}
+}
+
+func TestValidateThresholds(t *testing.T) {
+	if err := validateThresholds(defaultRegressionThresholds); err != nil {
+		t.Errorf("Expected the default thresholds to be valid, got %v", err)
+	}
+	for _, thresholds := range []map[string]float64{
+		{},
+		{"p95": 10},
+		{"p95_latency_ms": -1},
+	} {
+		if err := validateThresholds(thresholds); err == nil {
+			t.Errorf("Expected %v to be rejected", thresholds)
+		}
+	}
+}
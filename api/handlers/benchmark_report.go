@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+// BenchmarkReport is the summary of a run shared by every report format
+type BenchmarkReport struct {
+	RunID     string    `json:"run_id"`
+	Model     string    `json:"model"`
+	Status    string    `json:"status"`
+	Runtimes  []string  `json:"runtimes"`
+	StartTime time.Time `json:"start_time"`
+	// EndTime is when the run completed or failed; nil while it is still running
+	EndTime *time.Time     `json:"end_time,omitempty"`
+	Results []ReportResult `json:"results"`
+}
+
+// ReportResult holds the metrics of one runtime/quant/workload combination in a report
+type ReportResult struct {
+	Runtime         string  `json:"runtime"`
+	Quant           string  `json:"quant"`
+	Workload        string  `json:"workload"`
+	AvgLatencyMs    float64 `json:"avg_latency_ms"`
+	P50LatencyMs    float64 `json:"p50_latency_ms"`
+	P95LatencyMs    float64 `json:"p95_latency_ms"`
+	P99LatencyMs    float64 `json:"p99_latency_ms"`
+	ThroughputRPS   float64 `json:"throughput_rps"`
+	TokensPerSecond float64 `json:"tokens_per_second"`
+	ErrorRate       float64 `json:"error_rate"`
+	// Streaming holds time-to-first-token and inter-token latency for streaming workloads
+	Streaming *ReportStreaming `json:"streaming,omitempty"`
+}
+
+// ReportStreaming holds the latency percentiles only measured for streaming workloads
+type ReportStreaming struct {
+	P50TTFTMs float64 `json:"p50_ttft_ms"`
+	P95TTFTMs float64 `json:"p95_ttft_ms"`
+	P99TTFTMs float64 `json:"p99_ttft_ms"`
+	P50ITLMs  float64 `json:"p50_itl_ms"`
+	P95ITLMs  float64 `json:"p95_itl_ms"`
+	P99ITLMs  float64 `json:"p99_itl_ms"`
+}
+
+// HasStreaming reports whether any result has streaming metrics
+func (r *BenchmarkReport) HasStreaming() bool {
+	for _, result := range r.Results {
+		if result.Streaming != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// loadBenchmarkReport builds the report for a run from its saved results, returning nil
+// when the run does not exist
+func loadBenchmarkReport(ctx context.Context, dbClient *db.Client, runID string) (*BenchmarkReport, error) {
+	run, err := dbClient.GetRun(ctx, runID)
+	if err != nil || run == nil {
+		return nil, err
+	}
+	results, err := dbClient.GetRunResults(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return newBenchmarkReport(run, results), nil
+}
+
+// newBenchmarkReport assembles a report from a run and its result summaries
+func newBenchmarkReport(run *db.Run, results []db.ResultSummary) *BenchmarkReport {
+	report := &BenchmarkReport{
+		RunID:     run.ID,
+		Model:     run.Model,
+		Status:    run.Status,
+		Runtimes:  run.Runtimes,
+		StartTime: run.CreatedAt,
+		Results:   []ReportResult{},
+	}
+	if run.Status == "completed" || run.Status == "failed" {
+		endTime := run.UpdatedAt
+		report.EndTime = &endTime
+	}
+
+	for _, res := range results {
+		result := ReportResult{
+			Runtime:         res.Runtime,
+			Quant:           res.Quant,
+			Workload:        res.Workload,
+			AvgLatencyMs:    res.AvgLatencyMs,
+			P50LatencyMs:    res.P50LatencyMs,
+			P95LatencyMs:    res.P95LatencyMs,
+			P99LatencyMs:    res.P99LatencyMs,
+			ThroughputRPS:   res.ThroughputRPS,
+			TokensPerSecond: res.TokensPerSecond,
+			ErrorRate:       res.ErrorRate,
+		}
+		if s := res.Streaming; s != nil {
+			result.Streaming = &ReportStreaming{
+				P50TTFTMs: s.P50TTFTMs,
+				P95TTFTMs: s.P95TTFTMs,
+				P99TTFTMs: s.P99TTFTMs,
+				P50ITLMs:  s.P50ITLMs,
+				P95ITLMs:  s.P95ITLMs,
+				P99ITLMs:  s.P99ITLMs,
+			}
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// markdownCell escapes text so it cannot break out of a Markdown table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// formatFloat formats a metric with a fixed number of decimals
+func formatFloat(v float64, decimals int) string {
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+var markdownReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"cell": markdownCell,
+	"join": strings.Join,
+	"ms":   func(v float64) string { return formatFloat(v, 1) },
+	"num":  func(v float64) string { return formatFloat(v, 2) },
+	"pct":  func(v float64) string { return formatFloat(v*100, 2) + "%" },
+	"time": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}).Parse(`# Benchmark report {{cell .RunID}}
+
+| | |
+|---|---|
+| Model | {{cell .Model}} |
+| Runtimes | {{cell (join .Runtimes ", ")}} |
+| Status | {{cell .Status}} |
+| Started | {{time .StartTime}} |
+| Finished | {{if .EndTime}}{{time .EndTime}}{{else}}-{{end}} |
+
+## Results
+{{if .Results}}
+| Runtime | Quant | Workload | Avg (ms) | p50 (ms) | p95 (ms) | p99 (ms) | Throughput (req/s) | Tokens/s | Error rate |
+|---|---|---|--:|--:|--:|--:|--:|--:|--:|
+{{range .Results}}| {{cell .Runtime}} | {{cell .Quant}} | {{cell .Workload}} | {{ms .AvgLatencyMs}} | {{ms .P50LatencyMs}} | {{ms .P95LatencyMs}} | {{ms .P99LatencyMs}} | {{num .ThroughputRPS}} | {{num .TokensPerSecond}} | {{pct .ErrorRate}} |
+{{end}}{{else}}
+No results have been recorded for this run.
+{{end}}{{if .HasStreaming}}
+## Streaming
+
+| Runtime | Quant | Workload | p50 TTFT (ms) | p95 TTFT (ms) | p99 TTFT (ms) | p50 ITL (ms) | p95 ITL (ms) | p99 ITL (ms) |
+|---|---|---|--:|--:|--:|--:|--:|--:|
+{{range .Results}}{{if .Streaming}}| {{cell .Runtime}} | {{cell .Quant}} | {{cell .Workload}} | {{ms .Streaming.P50TTFTMs}} | {{ms .Streaming.P95TTFTMs}} | {{ms .Streaming.P99TTFTMs}} | {{ms .Streaming.P50ITLMs}} | {{ms .Streaming.P95ITLMs}} | {{ms .Streaming.P99ITLMs}} |
+{{end}}{{end}}{{end}}`))
+
+// renderMarkdownReport writes a report as Markdown
+func renderMarkdownReport(w io.Writer, report *BenchmarkReport) error {
+	return markdownReportTemplate.Execute(w, report)
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+func TestRenderMarkdownReport(t *testing.T) {
+	started := time.Date(2025, 8, 22, 10, 0, 0, 0, time.UTC)
+	run := &db.Run{
+		ID:        "run_000001",
+		Status:    "completed",
+		Model:     "meta-llama/Llama-3-8b-instruct",
+		Runtimes:  []string{"vllm", "transformers"},
+		CreatedAt: started,
+		UpdatedAt: started.Add(5 * time.Minute),
+	}
+	results := []db.ResultSummary{
+		{Runtime: "transformers", Quant: "fp16", Workload: "qa|short", AvgLatencyMs: 350.84, P95LatencyMs: 420.3, ThroughputRPS: 3.1, ErrorRate: 0.01},
+		{Runtime: "vllm", Quant: "fp16", Workload: "chat", AvgLatencyMs: 250.5, Streaming: &db.StreamingSummary{P95TTFTMs: 80}},
+	}
+
+	var b strings.Builder
+	if err := renderMarkdownReport(&b, newBenchmarkReport(run, results)); err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	report := b.String()
+
+	for _, want := range []string{
+		"# Benchmark report run_000001",
+		"| Model | meta-llama/Llama-3-8b-instruct |",
+		"| Started | 2025-08-22T10:00:00Z |",
+		"| Finished | 2025-08-22T10:05:00Z |",
+		"| transformers | fp16 | qa\\|short | 350.8 | 0.0 | 420.3 | 0.0 | 3.10 | 0.00 | 1.00% |",
+		"## Streaming",
+		"| vllm | fp16 | chat | 0.0 | 80.0 |",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, report)
+		}
+	}
+	if strings.Contains(report, "| transformers | fp16 | qa\\|short | 0.0") {
+		t.Errorf("Expected non-streaming results to be left out of the streaming table")
+	}
+}
+
+func TestRenderMarkdownReportWithoutResults(t *testing.T) {
+	run := &db.Run{ID: "run_000002", Status: "running", Model: "m", Runtimes: []string{"vllm"}}
+
+	var b strings.Builder
+	if err := renderMarkdownReport(&b, newBenchmarkReport(run, nil)); err != nil {
+		t.Fatalf("Failed to render report: %v", err)
+	}
+	if !strings.Contains(b.String(), "| Finished | - |") || !strings.Contains(b.String(), "No results have been recorded") {
+		t.Errorf("Expected a running report without results, got:\n%s", b.String())
+	}
+	if strings.Contains(b.String(), "## Streaming") {
+		t.Errorf("Expected no streaming section without streaming results")
+	}
+}
@@ -1,14 +1,84 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/tokenforge/llm-infra-bench/db"
 )
 
-// BenchmarkRunsHandler returns all benchmark runs
+const (
+	defaultRunsLimit = 50
+	maxRunsLimit     = 500
+)
+
+// RunsPage is a page of benchmark runs. NextCursor is passed back as ?after= to fetch the
+// following page and is empty on the last page.
+type RunsPage struct {
+	Runs       []*db.Run `json:"runs"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// runsPageQuery holds the paging parameters of a runs list request
+type runsPageQuery struct {
+	limit  int
+	offset int
+	after  string
+}
+
+// parseRunsPageQuery reads limit, offset and after from a runs list request. ok is false when
+// none are set, in which case the unpaged response is served.
+func parseRunsPageQuery(r *http.Request) (query runsPageQuery, ok bool, err error) {
+	values := r.URL.Query()
+	if !values.Has("limit") && !values.Has("offset") && !values.Has("after") {
+		return query, false, nil
+	}
+
+	query.limit = defaultRunsLimit
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return query, true, errors.New("limit must be a positive integer")
+		}
+		if n > maxRunsLimit {
+			n = maxRunsLimit
+		}
+		query.limit = n
+	}
+	if v := values.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return query, true, errors.New("offset must be a non-negative integer")
+		}
+		query.offset = n
+	}
+	query.after = values.Get("after")
+	if query.after != "" && query.offset > 0 {
+		return query, true, errors.New("after and offset cannot be combined")
+	}
+	return query, true, nil
+}
+
+// newRunsPage builds a page from up to limit+1 runs, using the extra run to tell whether
+// another page follows
+func newRunsPage(runs []*db.Run, limit int) RunsPage {
+	page := RunsPage{Runs: runs}
+	if len(runs) > limit {
+		page.Runs = runs[:limit]
+		page.NextCursor = page.Runs[limit-1].ID
+	}
+	if page.Runs == nil {
+		page.Runs = []*db.Run{}
+	}
+	return page
+}
+
+// BenchmarkRunsHandler returns all benchmark runs. With limit, offset or after set it returns
+// a RunsPage instead; after pages by cursor so pages stay stable while new runs arrive.
 func BenchmarkRunsHandler(dbClient *db.Client) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if dbClient == nil {
@@ -16,6 +86,31 @@ func BenchmarkRunsHandler(dbClient *db.Client) http.HandlerFunc {
 			return
 		}
 		
+		query, paged, err := parseRunsPageQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if paged {
+			var runs []*db.Run
+			if query.after != "" {
+				runs, err = dbClient.ListRunsAfter(r.Context(), query.after, query.limit+1)
+			} else {
+				runs, err = dbClient.ListRuns(r.Context(), query.limit+1, query.offset)
+			}
+			if errors.Is(err, db.ErrUnknownCursor) {
+				http.Error(w, "unknown cursor: "+query.after, http.StatusBadRequest)
+				return
+			}
+			if err != nil {
+				http.Error(w, "failed to list runs: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(newRunsPage(runs, query.limit))
+			return
+		}
+		
 		runs, err := dbClient.GetAllBenchmarkRuns()
 		if err != nil {
 			http.Error(w, "Failed to retrieve benchmark runs", http.StatusInternalServerError)
@@ -41,39 +136,52 @@ func BenchmarkReportHandler(dbClient *db.Client) http.HandlerFunc {
 			return
 		}
 		
-		// For now, we'll return a mock report
-		// In a real implementation, this would fetch the report from the database or S3
-		mockReport := map[string]interface{}{
-			"run_id": runID,
-			"model": "test-model",
-			"runtimes": []string{"vllm", "transformers"},
-			"start_time": "2025-08-22T10:00:00Z",
-			"end_time": "2025-08-22T10:05:00Z",
-			"results": []map[string]interface{}{
-				{
-					"runtime": "vllm",
-					"workload": "qa-short",
-					"avg_latency_ms": 250.5,
-					"p50_latency_ms": 240.2,
-					"p95_latency_ms": 320.7,
-					"p99_latency_ms": 380.1,
-					"throughput_rps": 4.2,
-					"tokens_per_second": 45.6,
-				},
-				{
-					"runtime": "transformers",
-					"workload": "qa-short",
-					"avg_latency_ms": 350.8,
-					"p50_latency_ms": 340.5,
-					"p95_latency_ms": 420.3,
-					"p99_latency_ms": 480.9,
-					"throughput_rps": 3.1,
-					"tokens_per_second": 32.4,
-				},
-			},
+		report, err := loadBenchmarkReport(r.Context(), dbClient, runID)
+		if err != nil {
+			http.Error(w, "failed to load report: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if report == nil {
+			http.Error(w, "run not found", http.StatusNotFound)
+			return
 		}
 		
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(mockReport)
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+// BenchmarkMarkdownReportHandler returns the report for a benchmark run as Markdown, for
+// pasting into pull requests and issues
+func BenchmarkMarkdownReportHandler(dbClient *db.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dbClient == nil {
+			http.Error(w, "Database not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		runID := chi.URLParam(r, "id")
+		if runID == "" {
+			http.Error(w, "Missing run ID parameter", http.StatusBadRequest)
+			return
+		}
+
+		report, err := loadBenchmarkReport(r.Context(), dbClient, runID)
+		if err != nil {
+			http.Error(w, "failed to load report: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if report == nil {
+			http.Error(w, "run not found", http.StatusNotFound)
+			return
+		}
+
+		var b bytes.Buffer
+		if err := renderMarkdownReport(&b, report); err != nil {
+			http.Error(w, "failed to render report: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write(b.Bytes())
 	}
 }
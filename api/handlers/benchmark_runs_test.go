@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+func TestParseRunsPageQuery(t *testing.T) {
+	if _, paged, err := parseRunsPageQuery(httptest.NewRequest("GET", "/benchmarks/runs", nil)); paged || err != nil {
+		t.Errorf("Expected an unpaged request without paging parameters, got paged=%v err=%v", paged, err)
+	}
+
+	query, paged, err := parseRunsPageQuery(httptest.NewRequest("GET", "/benchmarks/runs?after=run_000042", nil))
+	if !paged || err != nil || query.after != "run_000042" || query.limit != defaultRunsLimit {
+		t.Errorf("Expected a cursor page with the default limit, got %+v paged=%v err=%v", query, paged, err)
+	}
+
+	query, _, err = parseRunsPageQuery(httptest.NewRequest("GET", "/benchmarks/runs?limit=10000&offset=20", nil))
+	if err != nil || query.limit != maxRunsLimit || query.offset != 20 {
+		t.Errorf("Expected limit to be capped at %d with offset 20, got %+v err=%v", maxRunsLimit, query, err)
+	}
+
+	for _, target := range []string{
+		"/benchmarks/runs?limit=0",
+		"/benchmarks/runs?offset=-1",
+		"/benchmarks/runs?after=run_000042&offset=10",
+	} {
+		if _, _, err := parseRunsPageQuery(httptest.NewRequest("GET", target, nil)); err == nil {
+			t.Errorf("Expected %s to be rejected", target)
+		}
+	}
+}
+
+func TestNewRunsPageSetsNextCursor(t *testing.T) {
+	runs := []*db.Run{{ID: "run_000003"}, {ID: "run_000002"}, {ID: "run_000001"}}
+
+	page := newRunsPage(runs, 2)
+	if len(page.Runs) != 2 || page.NextCursor != "run_000002" {
+		t.Errorf("Expected two runs and a cursor at run_000002, got %d runs and %q", len(page.Runs), page.NextCursor)
+	}
+
+	page = newRunsPage(runs, 3)
+	if len(page.Runs) != 3 || page.NextCursor != "" {
+		t.Errorf("Expected the last page to have no cursor, got %d runs and %q", len(page.Runs), page.NextCursor)
+	}
+
+	if page := newRunsPage(nil, 3); page.Runs == nil {
+		t.Error("Expected an empty page to encode runs as an empty list")
+	}
+}
@@ -3,36 +3,100 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
-	"os/exec"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/tokenforge/llm-infra-bench/db"
+	"gopkg.in/yaml.v3"
 )
 
 // BenchmarkRunRequest represents the benchmark run request
 // It uses the same structure as configs/benchmark.yaml
 type BenchmarkRunRequest struct {
-	Model     string   `json:"model"`
-	Runtimes  []string `json:"runtimes"`
-	Workloads []struct {
-		Name      string `json:"name"`
-		QPS       int    `json:"qps"`
-		DurationS int    `json:"duration_s"`
-		PromptLen int    `json:"prompt_len"`
-		GenTokens int    `json:"gen_tokens"`
-	} `json:"workloads"`
+	Model    string   `json:"model" yaml:"model"`
+	Runtimes []string `json:"runtimes" yaml:"runtimes"`
+	// Quants optionally lists the quantizations to benchmark for each runtime,
+	// expanding the run matrix across quant variants
+	Quants    map[string][]string `json:"quants,omitempty" yaml:"quants,omitempty"`
+	Workloads []BenchmarkWorkload `json:"workloads" yaml:"workloads"`
+}
+
+// BenchmarkWorkload is a single workload in a benchmark run
+type BenchmarkWorkload struct {
+	Name      string `json:"name" yaml:"name"`
+	QPS       int    `json:"qps" yaml:"qps"`
+	DurationS int    `json:"duration_s" yaml:"duration_s"`
+	PromptLen int    `json:"prompt_len" yaml:"prompt_len"`
+	GenTokens int    `json:"gen_tokens" yaml:"gen_tokens"`
+	// Stream runs the workload with streaming responses, measuring TTFT and inter-token latency
+	Stream bool `json:"stream,omitempty" yaml:"stream,omitempty"`
+	// Ramp replaces the constant QPS with a stepped load profile
+	Ramp *WorkloadRamp `json:"ramp,omitempty" yaml:"ramp,omitempty"`
+	// Profile is the expanded ramp written to the generated config for the harness
+	Profile []LoadStep `json:"-" yaml:"profile,omitempty"`
+}
+
+// WorkloadRamp steps the QPS from StartQPS to EndQPS, holding each step for StepDurationS
+type WorkloadRamp struct {
+	StartQPS      int `json:"start_qps" yaml:"start_qps"`
+	EndQPS        int `json:"end_qps" yaml:"end_qps"`
+	Step          int `json:"step" yaml:"step"`
+	StepDurationS int `json:"step_duration_s" yaml:"step_duration_s"`
+}
+
+// LoadStep is one constant-QPS segment of a load profile
+type LoadStep struct {
+	QPS       int `yaml:"qps"`
+	DurationS int `yaml:"duration_s"`
+}
+
+// expandRampWorkloads validates each workload's ramp and expands it into a load profile.
+// The workload's QPS and duration are set to the final step's QPS and the total duration.
+func expandRampWorkloads(req *BenchmarkRunRequest) error {
+	for i := range req.Workloads {
+		workload := &req.Workloads[i]
+		ramp := workload.Ramp
+		if ramp == nil {
+			continue
+		}
+
+		switch {
+		case ramp.StartQPS <= 0:
+			return fmt.Errorf("workload %s: ramp start_qps must be positive", workload.Name)
+		case ramp.EndQPS < ramp.StartQPS:
+			return fmt.Errorf("workload %s: ramp end_qps must be at least start_qps", workload.Name)
+		case ramp.Step <= 0:
+			return fmt.Errorf("workload %s: ramp step must be positive", workload.Name)
+		case ramp.StepDurationS <= 0:
+			return fmt.Errorf("workload %s: ramp step_duration_s must be positive", workload.Name)
+		}
+
+		workload.Profile = nil
+		for qps := ramp.StartQPS; qps <= ramp.EndQPS; qps += ramp.Step {
+			workload.Profile = append(workload.Profile, LoadStep{QPS: qps, DurationS: ramp.StepDurationS})
+		}
+		workload.QPS = workload.Profile[len(workload.Profile)-1].QPS
+		workload.DurationS = len(workload.Profile) * ramp.StepDurationS
+	}
+	return nil
 }
 
 type BenchmarkRunResponse struct {
 	ID     string `json:"id"`
 	Status string `json:"status"`
+	// TraceID is sent with every harness request so worker logs can be matched to the run
+	TraceID string `json:"trace_id"`
 }
 
 type BenchmarkStatusResponse struct {
 	ID      string `json:"id"`
 	Status  string `json:"status"`
+	TraceID string `json:"trace_id,omitempty"`
 	Summary struct {
 		Model     string   `json:"model"`
 		Runtimes  []string `json:"runtimes"`
@@ -45,10 +109,27 @@ type BenchmarkStatusResponse struct {
 }
 
 // BenchmarkRunHandler handles benchmark run requests
-func BenchmarkRunHandler(dbClient *db.Client) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
+func BenchmarkRunHandler(dbClient *db.Client, configPath string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		w := middleware.NewWrapResponseWriter(rw, r.ProtoMajor)
 		var req BenchmarkRunRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var runID string
+		defer func() {
+			audit(r, dbClient, db.AuditEvent{
+				Action:     auditActionBenchmarkRun,
+				Model:      req.Model,
+				Runtime:    strings.Join(req.Runtimes, ","),
+				RunID:      runID,
+				StatusCode: w.Status(),
+			})
+		}()
+
+		if dbClient == nil {
+			http.Error(w, "Database not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		if err := decodeJSON(r, &req); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -59,31 +140,57 @@ func BenchmarkRunHandler(dbClient *db.Client) http.HandlerFunc {
 			return
 		}
 
-		// Generate a unique run ID
-		runID := fmt.Sprintf("run_%06d", dbClient.GetNextRunID())
+		if err := expandRampWorkloads(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Validate requested quants against the runtime config
+		if len(req.Quants) > 0 {
+			runtimes, err := loadRuntimesConfig(configPath)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if err := validateBenchmarkQuants(&req, runtimes); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
 
-		// Save benchmark config to temporary YAML
-		configPath := filepath.Join("/tmp", runID+".yaml")
-		// TODO: Write config to file
+		configYAML, err := yaml.Marshal(&req)
+		if err != nil {
+			http.Error(w, "failed to encode benchmark config: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
 
-		// Create run record in database
-		err := dbClient.CreateRun(r.Context(), runID, "queued", req.Model, req.Runtimes, configPath)
+		// Create run record in database; the ID it is stored under may differ from the one
+		// generated here when that one is already taken
+		traceID := newTraceID()
+		runID, err = dbClient.CreateRun(r.Context(), dbClient.NewRunID(), "queued", req.Model, req.Runtimes, configYAML, traceID)
 		if err != nil {
 			http.Error(w, "failed to create run record: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		// Save benchmark config to temporary YAML for the harness, named after the final run ID
+		runConfigPath := filepath.Join("/tmp", runID+".yaml")
+		if err := os.WriteFile(runConfigPath, configYAML, 0644); err != nil {
+			if err := dbClient.UpdateRunStatus(r.Context(), runID, "failed", nil, nil, nil); err != nil {
+				log.Printf("Failed to mark run %s failed: %v", runID, err)
+			}
+			http.Error(w, "failed to write benchmark config: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
 		// Start benchmark process in background
-		go func() {
-			cmd := exec.Command("python", "harness/run_bench.py", "--run-id", runID, "--config", configPath)
-			// TODO: Handle command execution and update run status
-			_ = cmd.Run()
-		}()
+		go runBenchmark(dbClient, runID, traceID, runConfigPath)
 
 		// Return response
 		resp := BenchmarkRunResponse{
-			ID:     runID,
-			Status: "queued",
+			ID:      runID,
+			Status:  "queued",
+			TraceID: traceID,
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -92,9 +199,44 @@ func BenchmarkRunHandler(dbClient *db.Client) http.HandlerFunc {
 	}
 }
 
+// validateBenchmarkQuants checks that every requested quant targets a runtime in the run
+// and is supported by that runtime. Runtimes that do not declare quants accept any quant.
+func validateBenchmarkQuants(req *BenchmarkRunRequest, config *RuntimesConfig) error {
+	inRun := make(map[string]bool, len(req.Runtimes))
+	for _, name := range req.Runtimes {
+		inRun[name] = true
+	}
+
+	for runtime, quants := range req.Quants {
+		if !inRun[runtime] {
+			return fmt.Errorf("quants specified for runtime %s which is not part of the run", runtime)
+		}
+
+		supported, found := config.supportedQuants(runtime)
+		if !found {
+			return fmt.Errorf("runtime %s not found in config", runtime)
+		}
+		if len(supported) == 0 {
+			continue
+		}
+		for _, quant := range quants {
+			if !containsString(supported, quant) {
+				return fmt.Errorf("quant %s is not supported by runtime %s (supported: %v)", quant, runtime, supported)
+			}
+		}
+	}
+
+	return nil
+}
+
 // BenchmarkStatusHandler handles benchmark status requests
 func BenchmarkStatusHandler(dbClient *db.Client) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if dbClient == nil {
+			http.Error(w, "Database not available", http.StatusServiceUnavailable)
+			return
+		}
+
 		runID := chi.URLParam(r, "id")
 		if runID == "" {
 			http.Error(w, "run ID is required", http.StatusBadRequest)
@@ -115,8 +257,9 @@ func BenchmarkStatusHandler(dbClient *db.Client) http.HandlerFunc {
 
 		// Prepare response
 		resp := BenchmarkStatusResponse{
-			ID:     runID,
-			Status: run.Status,
+			ID:      runID,
+			Status:  run.Status,
+			TraceID: run.TraceID,
 		}
 		resp.Summary.Model = run.Model
 		resp.Summary.Runtimes = run.Runtimes
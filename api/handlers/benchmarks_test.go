@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandRampWorkloads(t *testing.T) {
+	req := BenchmarkRunRequest{
+		Model:    "test-model",
+		Runtimes: []string{"vllm"},
+		Workloads: []BenchmarkWorkload{
+			{Name: "constant", QPS: 5, DurationS: 60},
+			{Name: "ramp", Ramp: &WorkloadRamp{StartQPS: 2, EndQPS: 9, Step: 3, StepDurationS: 30}},
+		},
+	}
+
+	if err := expandRampWorkloads(&req); err != nil {
+		t.Fatalf("Expected valid ramp, got %v", err)
+	}
+
+	if req.Workloads[0].Profile != nil {
+		t.Errorf("Expected constant workload to have no profile")
+	}
+
+	ramp := req.Workloads[1]
+	want := []int{2, 5, 8}
+	if len(ramp.Profile) != len(want) {
+		t.Fatalf("Expected %d steps, got %+v", len(want), ramp.Profile)
+	}
+	for i, qps := range want {
+		if ramp.Profile[i].QPS != qps || ramp.Profile[i].DurationS != 30 {
+			t.Errorf("Step %d: got %+v, want qps %d for 30s", i, ramp.Profile[i], qps)
+		}
+	}
+	if ramp.DurationS != 90 || ramp.QPS != 8 {
+		t.Errorf("Expected total duration 90s at final qps 8, got %ds at %d", ramp.DurationS, ramp.QPS)
+	}
+}
+
+func TestExpandRampWorkloadsRejectsInconsistentRamp(t *testing.T) {
+	ramps := map[string]WorkloadRamp{
+		"end below start": {StartQPS: 10, EndQPS: 5, Step: 1, StepDurationS: 30},
+		"zero step":       {StartQPS: 1, EndQPS: 5, Step: 0, StepDurationS: 30},
+		"zero start":      {StartQPS: 0, EndQPS: 5, Step: 1, StepDurationS: 30},
+		"zero duration":   {StartQPS: 1, EndQPS: 5, Step: 1, StepDurationS: 0},
+	}
+	for name, ramp := range ramps {
+		ramp := ramp
+		req := BenchmarkRunRequest{Workloads: []BenchmarkWorkload{{Name: "ramp", Ramp: &ramp}}}
+		if err := expandRampWorkloads(&req); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestValidateBenchmarkQuants(t *testing.T) {
+	configPath := t.TempDir()
+	runtimes := `runtimes:
+  - name: vllm
+    quants: [fp16, awq]
+  - name: tgi
+`
+	if err := os.WriteFile(filepath.Join(configPath, "runtimes.yaml"), []byte(runtimes), 0644); err != nil {
+		t.Fatalf("Failed to write runtimes config: %v", err)
+	}
+	config, err := loadRuntimesConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load runtimes config: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		runtimes []string
+		quants   map[string][]string
+		wantErr  bool
+	}{
+		{name: "supported quants", runtimes: []string{"vllm"}, quants: map[string][]string{"vllm": {"fp16", "awq"}}},
+		{name: "runtime without declared quants", runtimes: []string{"tgi"}, quants: map[string][]string{"tgi": {"int8"}}},
+		{name: "unsupported quant", runtimes: []string{"vllm"}, quants: map[string][]string{"vllm": {"fp16", "int8"}}, wantErr: true},
+		{name: "runtime not in run", runtimes: []string{"vllm"}, quants: map[string][]string{"tgi": {"fp16"}}, wantErr: true},
+		{name: "unknown runtime", runtimes: []string{"vllm", "sglang"}, quants: map[string][]string{"sglang": {"fp16"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := BenchmarkRunRequest{Model: "test-model", Runtimes: tt.runtimes, Quants: tt.quants}
+			if err := validateBenchmarkQuants(&req, config); (err != nil) != tt.wantErr {
+				t.Errorf("validateBenchmarkQuants() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+// RequireDatabase disables a group of database-backed routes when the API runs in
+// inference-only mode without a database, so they fail with a clear 503 instead of
+// reaching handlers that need the client
+func RequireDatabase(dbClient *db.Client, feature string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if dbClient != nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, feature+" unavailable: the API is running in inference-only mode without a database", http.StatusServiceUnavailable)
+		})
+	}
+}
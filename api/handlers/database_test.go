@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRequireDatabaseRejectsWithoutClient(t *testing.T) {
+	called := false
+	handler := RequireDatabase(nil, "benchmarks")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/benchmarks/runs", nil))
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusServiceUnavailable)
+	}
+	if !strings.Contains(rr.Body.String(), "inference-only mode") {
+		t.Errorf("Expected message to explain inference-only mode, got %q", rr.Body.String())
+	}
+	if called {
+		t.Errorf("Expected the wrapped handler not to run")
+	}
+}
+
+func TestDatabaseHandlersDoNotPanicWithoutClient(t *testing.T) {
+	r := chi.NewRouter()
+	r.Post("/benchmarks/run", BenchmarkRunHandler(nil, t.TempDir()))
+	r.Get("/benchmarks/run/{id}", BenchmarkStatusHandler(nil))
+	r.Get("/benchmarks/run/{id}/artifacts.zip", BenchmarkArtifactsZipHandler(nil))
+	r.Post("/benchmarks/run/{id}/baseline", BenchmarkBaselineHandler(nil))
+	r.Post("/benchmarks/run/{id}/regression", BenchmarkRegressionHandler(nil))
+	r.Get("/benchmarks/runs", BenchmarkRunsHandler(nil))
+	r.Get("/benchmarks/report/{id}.md", BenchmarkMarkdownReportHandler(nil))
+	r.Get("/benchmarks/report/{id}", BenchmarkReportHandler(nil))
+	r.Get("/leaderboard", LeaderboardHandler(nil))
+	r.Get("/inferences/stats", InferenceStatsHandler(nil))
+	r.Get("/deployments/{model}/{runtime}/inferences", RecentInferencesHandler(nil, ""))
+
+	requests := []*http.Request{
+		httptest.NewRequest("POST", "/benchmarks/run", strings.NewReader(`{"model":"m","runtimes":["vllm"],"workloads":[{"name":"w","qps":1}]}`)),
+		httptest.NewRequest("GET", "/benchmarks/run/run_000001", nil),
+		httptest.NewRequest("GET", "/benchmarks/run/run_000001/artifacts.zip", nil),
+		httptest.NewRequest("POST", "/benchmarks/run/run_000001/baseline", nil),
+		httptest.NewRequest("POST", "/benchmarks/run/run_000001/regression", nil),
+		httptest.NewRequest("GET", "/benchmarks/runs", nil),
+		httptest.NewRequest("GET", "/benchmarks/report/run_000001", nil),
+		httptest.NewRequest("GET", "/benchmarks/report/run_000001.md", nil),
+		httptest.NewRequest("GET", "/leaderboard?model=m", nil),
+		httptest.NewRequest("GET", "/inferences/stats", nil),
+		httptest.NewRequest("GET", "/deployments/m/vllm/inferences", nil),
+	}
+	for _, req := range requests {
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s %s returned %v, want %v", req.Method, req.URL.Path, rr.Code, http.StatusServiceUnavailable)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// strictJSONEnabled reports whether request bodies with unknown fields are rejected.
+// Strict decoding is the default; set STRICT_JSON=false to accept unknown fields.
+func strictJSONEnabled() bool {
+	return os.Getenv("STRICT_JSON") != "false"
+}
+
+// decodeJSON decodes a request body into v. In strict mode an unknown field, such as a
+// typo'd max_token, fails with an error naming the field instead of being ignored.
+func decodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	if strictJSONEnabled() {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(v); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("unknown field %s in request body", field)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+func TestInferHandlerRejectsUnknownField(t *testing.T) {
+	var gotMaxTokens int
+	worker := newTestWorker(t, &gotMaxTokens)
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	body := []byte(`{"model":"test-model","runtime":"minimal","prompt":"hello","max_token":32}`)
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rr.Body.String(), `"max_token"`) {
+		t.Errorf("Expected error to name the unknown field, got %q", rr.Body.String())
+	}
+	if gotMaxTokens != 0 {
+		t.Errorf("Expected request not to reach the worker")
+	}
+}
+
+func TestDeployHandlerRejectsUnknownField(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	handler := DeployHandler(registry, nil, writeTestModelsConfig(t))
+
+	body := []byte(`{"model":"test-model","runtime":"minimal","qaunt":"fp16"}`)
+	req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(rr.Body.String(), `"qaunt"`) {
+		t.Errorf("Expected error to name the unknown field, got %q", rr.Body.String())
+	}
+	if len(registry.GetAll()) != 0 {
+		t.Errorf("Expected no registry entry for a rejected deploy")
+	}
+}
+
+func TestDecodeJSONLenientWhenDisabled(t *testing.T) {
+	t.Setenv("STRICT_JSON", "false")
+
+	var req InferRequest
+	r := httptest.NewRequest("POST", "/api/v1/infer", strings.NewReader(`{"model":"test-model","max_token":32}`))
+	if err := decodeJSON(r, &req); err != nil {
+		t.Fatalf("Expected unknown fields to be ignored, got %v", err)
+	}
+	if req.Model != "test-model" {
+		t.Errorf("Expected model to be decoded, got %q", req.Model)
+	}
+}
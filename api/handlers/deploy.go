@@ -1,24 +1,46 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/tokenforge/llm-infra-bench/auth"
 	"github.com/tokenforge/llm-infra-bench/controlplane"
 	"github.com/tokenforge/llm-infra-bench/controlplane/k8s"
+	"github.com/tokenforge/llm-infra-bench/db"
+	"github.com/tokenforge/llm-infra-bench/events"
+	"golang.org/x/sync/singleflight"
 )
 
 type DeployRequest struct {
 	Model   string `json:"model"`
 	Runtime string `json:"runtime"`
 	Quant   string `json:"quant"`
+	// GPU overrides the runtime's GPU count; 0 deploys a CPU-only variant
+	GPU *int `json:"gpu,omitempty"`
+	// CPU and Mem override the runtime's CPU and memory, e.g. "4" and "64Gi"
+	CPU string `json:"cpu,omitempty"`
+	Mem string `json:"mem,omitempty"`
+	// Profile selects a resource profile defined for the runtime, e.g. "small" or "large"
+	Profile string `json:"profile,omitempty"`
 }
 
 type DeployResponse struct {
+	Model      string    `json:"model"`
+	Quant      string    `json:"quant"`
 	Endpoint   string    `json:"endpoint"`
 	Status     string    `json:"status"`
 	DeployedAt time.Time `json:"deployed_at"`
+	// ConfigHash identifies the effective model and runtime config the worker was built from
+	ConfigHash string `json:"config_hash,omitempty"`
 	K8s        struct {
 		Namespace  string `json:"namespace"`
 		Deployment string `json:"deployment"`
@@ -26,11 +48,37 @@ type DeployResponse struct {
 	} `json:"k8s"`
 }
 
+// resourceOverrides returns the request's resource overrides, or nil when it has none
+func (req *DeployRequest) resourceOverrides() *controlplane.ResourceOverrides {
+	if req.GPU == nil && req.CPU == "" && req.Mem == "" {
+		return nil
+	}
+	return &controlplane.ResourceOverrides{GPU: req.GPU, CPU: req.CPU, Mem: req.Mem}
+}
+
+// needsAuthentication reports whether the request overrides resources in a way only
+// authenticated callers may. A CPU-only "gpu": 0 deploy asks for less than the runtime
+// config and is allowed for everyone.
+func (req *DeployRequest) needsAuthentication() bool {
+	return req.CPU != "" || req.Mem != "" || (req.GPU != nil && *req.GPU > 0)
+}
+
 // DeployHandler handles model deployment requests
-func DeployHandler(registry *controlplane.Registry) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
+func DeployHandler(registry *controlplane.Registry, dbClient *db.Client, configPath string) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		w := middleware.NewWrapResponseWriter(rw, r.ProtoMajor)
 		var req DeployRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		defer func() {
+			audit(r, dbClient, db.AuditEvent{
+				Action:     auditActionDeploy,
+				Model:      req.Model,
+				Runtime:    req.Runtime,
+				StatusCode: w.Status(),
+				Detail:     req.Quant,
+			})
+		}()
+
+		if err := decodeJSON(r, &req); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
@@ -41,47 +89,223 @@ func DeployHandler(registry *controlplane.Registry) http.HandlerFunc {
 			return
 		}
 
-		var serviceURL, namespace, deploymentName, serviceName string
-		var err error
-		
-		// Special case for minimal runtime during testing
-		if req.Runtime == "minimal" {
-			// Use the local minimal worker
-			serviceURL = "http://localhost:8000"
-			namespace = "local"
-			deploymentName = "minimal-worker"
-			serviceName = "minimal-worker"
-		} else {
-			// Create deployment using Kubernetes
-			serviceURL, namespace, deploymentName, serviceName, err = k8s.DeployWorker(r.Context(), req.Model, req.Runtime, req.Quant)
-			if err != nil {
-				http.Error(w, "failed to deploy worker: "+err.Error(), http.StatusInternalServerError)
+		// Resource overrides bypass the reviewed runtime config, so anonymous callers cannot use them
+		overrides := req.resourceOverrides()
+		if req.needsAuthentication() {
+			if identity, ok := auth.FromContext(r.Context()); !ok || identity.Method == "anonymous" {
+				http.Error(w, "resource overrides require an authenticated caller", http.StatusForbidden)
 				return
 			}
 		}
 
-		// Register the service in registry
-		registry.Set(req.Model, req.Runtime, serviceURL)
+		// Resolve aliases so every deployment is keyed by the canonical model name
+		req.Model = canonicalModelName(configPath, req.Model)
 
-		// Prepare response
-		status := "deploying"
-		if req.Runtime == "minimal" {
-			status = "ready" // Minimal worker is always ready
-		}
-		
-		resp := DeployResponse{
-			Endpoint:   serviceURL,
-			Status:     status,
-			DeployedAt: time.Now(),
+		// Fall back to the model's default quant so workers never start with an empty QUANT
+		if req.Quant == "" {
+			req.Quant = defaultQuant(configPath, req.Model)
+			if req.Quant == "" {
+				http.Error(w, "quant is required: no default quant configured for model "+req.Model, http.StatusBadRequest)
+				return
+			}
 		}
-		resp.K8s.Namespace = namespace
-		resp.K8s.Deployment = deploymentName
-		resp.K8s.Service = serviceName
 
-		// TODO: Poll for readiness and update status to "ready" when available
+		// Concurrent deploys of the same pair share one operation and its result. The operation
+		// outlives the request that started it, since other callers may be waiting on it.
+		result, err, _ := deployFlights.Do(makeDeployKey(req), func() (interface{}, error) {
+			return deploy(context.WithoutCancel(r.Context()), registry, dbClient, req, overrides)
+		})
+		if err != nil {
+			http.Error(w, err.Error(), deployErrorStatus(err))
+			return
+		}
+		resp := result.(*DeployResponse)
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(resp)
 	}
 }
+
+// deployFlights deduplicates concurrent deploys of the same model and runtime
+var deployFlights singleflight.Group
+
+// deployWorker creates the worker in Kubernetes; replaced in tests
+var deployWorker = k8s.DeployWorker
+
+// awaitReady moves a deployed worker's entry to ready in the background; replaced in tests
+var awaitReady = func(registry *controlplane.Registry, model, runtime string) {
+	controlplane.NewController(registry).AwaitReady(model, runtime)
+}
+
+// makeDeployKey identifies a deploy operation by everything that shapes the worker, so only
+// identical requests share a result
+func makeDeployKey(req DeployRequest) string {
+	gpu := ""
+	if req.GPU != nil {
+		gpu = strconv.Itoa(*req.GPU)
+	}
+	return strings.Join([]string{req.Model, req.Runtime, req.Quant, req.Profile, gpu, req.CPU, req.Mem}, "::")
+}
+
+// deployMismatch describes how a deploy request differs from the worker already registered for
+// its model and runtime, or returns "" when the request matches it
+func deployMismatch(existing controlplane.Entry, req DeployRequest, overrides *controlplane.ResourceOverrides) string {
+	switch {
+	case existing.Quant != req.Quant:
+		return fmt.Sprintf("is deployed with quant %s, not %s", existing.Quant, req.Quant)
+	case existing.Profile != req.Profile:
+		return fmt.Sprintf("is deployed with profile %q, not %q", existing.Profile, req.Profile)
+	case !existing.Overrides.Equal(overrides):
+		return "is deployed with different resource overrides"
+	}
+	return ""
+}
+
+// deployErrorStatus maps a deploy failure to the HTTP status it is reported with
+func deployErrorStatus(err error) int {
+	var limitErr *controlplane.LimitError
+	switch {
+	case errors.As(err, &limitErr), errors.Is(err, k8s.ErrDeployConflict):
+		return http.StatusConflict
+	case errors.Is(err, k8s.ErrInvalidDeploy):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// deploy reserves the registry slot for a validated request, creates the worker and
+// registers it
+func deploy(ctx context.Context, registry *controlplane.Registry, dbClient *db.Client, req DeployRequest, overrides *controlplane.ResourceOverrides) (*DeployResponse, error) {
+	// Enforce deployment limits, reserving the registry slot for the duration of the deploy
+	reserved, err := registry.Reserve(controlplane.Entry{
+		Model:   req.Model,
+		Runtime: req.Runtime,
+		Quant:   req.Quant,
+		Profile: req.Profile,
+		Status:  "deploying",
+	}, envInt("MAX_DEPLOYMENTS_PER_MODEL", 0), envInt("MAX_DEPLOYMENTS", 0))
+	if err != nil {
+		return nil, err
+	}
+
+	// Release the reserved slot unless the deployed worker gets registered, including when the
+	// deploy panics, so the slot does not count against the limits forever
+	registered := false
+	if reserved {
+		defer func() {
+			if !registered {
+				registry.Delete(req.Model, req.Runtime)
+			}
+		}()
+	}
+
+	// A model and runtime has a single worker, so a redeploy must ask for the same variant.
+	// Redeploying a live worker reports it as it is; only a failed one is deployed again.
+	var paused bool
+	if !reserved {
+		if existing, found := registry.Get(req.Model, req.Runtime); found {
+			if reason := deployMismatch(existing, req, overrides); reason != "" {
+				return nil, fmt.Errorf("%w: %s with runtime %s %s; tear it down before deploying another variant", k8s.ErrDeployConflict, req.Model, req.Runtime, reason)
+			}
+			if existing.Status != "failed" {
+				return deployResponse(existing), nil
+			}
+			paused = existing.Paused
+		}
+	}
+
+	var serviceURL, namespace, deploymentName, serviceName, configHash string
+
+	// Special case for minimal runtime during testing
+	if req.Runtime == "minimal" {
+		// Use the local minimal worker
+		serviceURL = "http://localhost:8000"
+		namespace = "local"
+		deploymentName = "minimal-worker"
+		serviceName = "minimal-worker"
+	} else {
+		// Create deployment using Kubernetes
+		opts := k8s.DeployOptions{GPU: req.GPU, Profile: req.Profile, CPU: req.CPU, Mem: req.Mem}
+		worker, err := deployWorker(ctx, req.Model, req.Runtime, req.Quant, opts)
+		if err != nil {
+			events.Publish(ctx, events.Event{Type: events.DeploymentFailed, Model: req.Model, Runtime: req.Runtime, Data: map[string]interface{}{"error": err.Error()}})
+			return nil, fmt.Errorf("failed to deploy worker: %w", err)
+		}
+		serviceURL, namespace, deploymentName, serviceName = worker.ServiceURL, worker.Namespace, worker.Deployment, worker.Service
+		configHash = worker.ConfigHash
+	}
+
+	// Prepare response
+	status := "deploying"
+	if req.Runtime == "minimal" {
+		status = "ready" // Minimal worker is always ready
+	}
+
+	// Register the service in registry
+	registry.Set(controlplane.Entry{
+		Model:      req.Model,
+		Runtime:    req.Runtime,
+		Quant:      req.Quant,
+		ServiceURL: serviceURL,
+		Status:     status,
+		Namespace:  namespace,
+		Deployment: deploymentName,
+		Service:    serviceName,
+		ConfigHash: configHash,
+		Profile:    req.Profile,
+		Overrides:  overrides,
+		Paused:     paused,
+	})
+	registered = true
+
+	// Keep a durable record of which config produced the worker
+	if dbClient != nil {
+		if err := dbClient.RecordDeployment(ctx, db.Deployment{
+			Time:       time.Now().UTC(),
+			Model:      req.Model,
+			Runtime:    req.Runtime,
+			Quant:      req.Quant,
+			Namespace:  namespace,
+			Deployment: deploymentName,
+			ConfigHash: configHash,
+		}); err != nil {
+			log.Printf("Failed to record deployment: %v", err)
+		}
+	}
+
+	eventType := events.DeploymentCreated
+	if status == "ready" {
+		eventType = events.DeploymentReady
+	}
+	events.Publish(ctx, events.Event{Type: eventType, Model: req.Model, Runtime: req.Runtime, Data: map[string]interface{}{"quant": req.Quant}})
+
+	// The readiness watcher moves the entry to ready, or it is polled when no watch is running
+	if status == "deploying" {
+		awaitReady(registry, req.Model, req.Runtime)
+	}
+
+	// Ready workers report their loaded context window straight away
+	entry, _ := registry.Get(req.Model, req.Runtime)
+	if status == "ready" {
+		entry = cacheWorkerMetadata(ctx, registry, entry)
+	}
+	return deployResponse(entry), nil
+}
+
+// deployResponse describes a registered deployment to the deploy caller
+func deployResponse(entry controlplane.Entry) *DeployResponse {
+	resp := &DeployResponse{
+		Model:      entry.Model,
+		Quant:      entry.Quant,
+		Endpoint:   entry.ServiceURL,
+		Status:     entry.Status,
+		DeployedAt: entry.CreatedAt,
+		ConfigHash: entry.ConfigHash,
+	}
+	resp.K8s.Namespace = entry.Namespace
+	resp.K8s.Deployment = entry.Deployment
+	resp.K8s.Service = entry.Service
+	return resp
+}
@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tokenforge/llm-infra-bench/auth"
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+	"github.com/tokenforge/llm-infra-bench/controlplane/k8s"
+)
+
+func TestDeployHandlerEnforcesPerModelLimit(t *testing.T) {
+	t.Setenv("MAX_DEPLOYMENTS_PER_MODEL", "1")
+
+	registry := controlplane.NewRegistry()
+	handler := DeployHandler(registry, nil, t.TempDir())
+
+	deploy := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(body)))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := deploy(`{"model":"test-model","runtime":"minimal","quant":"fp16"}`); rr.Code != http.StatusOK {
+		t.Fatalf("First deploy returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	// Redeploying the same pair does not count against the limit
+	if rr := deploy(`{"model":"test-model","runtime":"minimal","quant":"fp16"}`); rr.Code != http.StatusOK {
+		t.Fatalf("Redeploy returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	rr := deploy(`{"model":"test-model","runtime":"vllm","quant":"fp16"}`)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Deploy over limit returned wrong status code: got %v want %v", rr.Code, http.StatusConflict)
+	}
+	if !contains(rr.Body.String(), "1 of 1") {
+		t.Errorf("Expected conflict to report the current count, got %q", rr.Body.String())
+	}
+}
+
+func TestDeployHandlerResolvesModelAlias(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	handler := DeployHandler(registry, nil, writeTestModelsConfig(t))
+
+	for _, model := range []string{"tm", "test-model"} {
+		body := `{"model":"` + model + `","runtime":"minimal","quant":"fp16"}`
+		req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(body)))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Deploy of %s returned wrong status code: got %v want %v (%s)", model, rr.Code, http.StatusOK, rr.Body.String())
+		}
+
+		var resp DeployResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if resp.Model != "test-model" {
+			t.Errorf("Expected canonical model name in response, got %s", resp.Model)
+		}
+	}
+
+	entries := registry.GetAll()
+	if len(entries) != 1 || entries[0].Model != "test-model" {
+		t.Errorf("Expected a single entry under the canonical name, got %+v", entries)
+	}
+}
+
+func TestDeployHandlerFallsBackToDefaultQuant(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	handler := DeployHandler(registry, nil, writeTestModelsConfig(t))
+
+	req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(`{"model":"test-model","runtime":"minimal"}`)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Deploy returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp DeployResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Quant != "fp16" {
+		t.Errorf("Expected response to report the default quant fp16, got %q", resp.Quant)
+	}
+	if entry, found := registry.Get("test-model", "minimal"); !found || entry.Quant != "fp16" {
+		t.Errorf("Expected registry entry with quant fp16, got %+v", entry)
+	}
+}
+
+func TestDeployHandlerRejectsUnresolvableQuant(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	handler := DeployHandler(registry, nil, writeTestModelsConfig(t))
+
+	req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(`{"model":"unknown-model","runtime":"minimal"}`)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("Deploy returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+	if len(registry.GetAll()) != 0 {
+		t.Errorf("Expected no registry entry for a rejected deploy")
+	}
+}
+
+func TestDeployHandlerGuardsResourceOverrides(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	handler := DeployHandler(registry, nil, t.TempDir())
+	body := `{"model":"test-model","runtime":"minimal","quant":"fp16","cpu":"4","mem":"64Gi"}`
+
+	req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(body)))
+	req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{Subject: "anonymous@test", Method: "anonymous"}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Fatalf("Anonymous override returned wrong status code: got %v want %v", rr.Code, http.StatusForbidden)
+	}
+	if _, found := registry.Get("test-model", "minimal"); found {
+		t.Errorf("Expected rejected deploy not to be registered")
+	}
+
+	req = httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(body)))
+	req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{Subject: "key:ci", Method: "static"}))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Authenticated override returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	entry, _ := registry.Get("test-model", "minimal")
+	if entry.Overrides == nil || entry.Overrides.CPU != "4" || entry.Overrides.Mem != "64Gi" {
+		t.Errorf("Expected overrides to be recorded on the registry entry, got %+v", entry.Overrides)
+	}
+}
+
+func TestDeployHandlerAllowsAnonymousCPUOnlyDeploy(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	handler := DeployHandler(registry, nil, t.TempDir())
+
+	req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(`{"model":"test-model","runtime":"minimal","quant":"fp16","gpu":0}`)))
+	req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{Subject: "anonymous@test", Method: "anonymous"}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Anonymous CPU-only deploy returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	entry, _ := registry.Get("test-model", "minimal")
+	if entry.Overrides == nil || entry.Overrides.GPU == nil || *entry.Overrides.GPU != 0 {
+		t.Errorf("Expected the gpu override to be recorded, got %+v", entry.Overrides)
+	}
+
+	registry.Delete("test-model", "minimal")
+	req = httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(`{"model":"test-model","runtime":"minimal","quant":"fp16","gpu":4}`)))
+	req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{Subject: "anonymous@test", Method: "anonymous"}))
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Anonymous GPU increase returned wrong status code: got %v want %v", rr.Code, http.StatusForbidden)
+	}
+}
+
+func TestDeployHandlerDeduplicatesConcurrentDeploys(t *testing.T) {
+	const clients = 5
+	var calls atomic.Int32
+	arrived := make(chan struct{}, clients)
+	deployWorker = func(ctx context.Context, model, runtime, quant string, opts k8s.DeployOptions) (*k8s.WorkerDeployment, error) {
+		calls.Add(1)
+		// Hold the deploy until every client has sent its request, so they all join it
+		for i := 0; i < clients; i++ {
+			<-arrived
+		}
+		return &k8s.WorkerDeployment{ServiceURL: "http://worker:8000", Namespace: "default", Deployment: "worker-vllm-test-model", Service: "worker-vllm-test-model"}, nil
+	}
+	defer func() { deployWorker = k8s.DeployWorker }()
+
+	registry := controlplane.NewRegistry()
+	handler := DeployHandler(registry, nil, t.TempDir())
+
+	codes := make([]int, clients)
+	bodies := make([]DeployResponse, clients)
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(`{"model":"test-model","runtime":"vllm","quant":"fp16"}`)))
+			rr := httptest.NewRecorder()
+			arrived <- struct{}{}
+			handler.ServeHTTP(rr, req)
+			codes[i] = rr.Code
+			json.Unmarshal(rr.Body.Bytes(), &bodies[i])
+		}(i)
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected concurrent deploys to create the worker once, got %d calls", got)
+	}
+	for i := 0; i < clients; i++ {
+		if codes[i] != http.StatusOK {
+			t.Errorf("Client %d returned wrong status code: got %v want %v", i, codes[i], http.StatusOK)
+		}
+		if bodies[i].K8s.Deployment != "worker-vllm-test-model" || bodies[i].DeployedAt != bodies[0].DeployedAt {
+			t.Errorf("Expected client %d to get the shared result, got %+v", i, bodies[i])
+		}
+	}
+	if len(registry.GetAll()) != 1 {
+		t.Errorf("Expected one registry entry, got %d", len(registry.GetAll()))
+	}
+}
+
+func TestDeployHandlerRejectsDifferentVariant(t *testing.T) {
+	var calls atomic.Int32
+	deployWorker = func(ctx context.Context, model, runtime, quant string, opts k8s.DeployOptions) (*k8s.WorkerDeployment, error) {
+		calls.Add(1)
+		return &k8s.WorkerDeployment{ServiceURL: "http://worker:8000", Namespace: "default", Deployment: "worker-vllm-test-model", Service: "worker-vllm-test-model"}, nil
+	}
+	defer func() { deployWorker = k8s.DeployWorker }()
+
+	registry := controlplane.NewRegistry()
+	handler := DeployHandler(registry, nil, t.TempDir())
+	deploy := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(body)))
+		req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{Subject: "key:ci", Method: "static"}))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := deploy(`{"model":"test-model","runtime":"vllm","quant":"fp16"}`); rr.Code != http.StatusOK {
+		t.Fatalf("Deploy returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	for _, body := range []string{
+		`{"model":"test-model","runtime":"vllm","quant":"int8"}`,
+		`{"model":"test-model","runtime":"vllm","quant":"fp16","mem":"64Gi"}`,
+		`{"model":"test-model","runtime":"vllm","quant":"fp16","profile":"large"}`,
+	} {
+		if rr := deploy(body); rr.Code != http.StatusConflict {
+			t.Errorf("Deploy of another variant %s returned wrong status code: got %v want %v", body, rr.Code, http.StatusConflict)
+		}
+	}
+	if rr := deploy(`{"model":"test-model","runtime":"vllm","quant":"fp16"}`); rr.Code != http.StatusOK {
+		t.Errorf("Redeploy of the same variant returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if entry, _ := registry.Get("test-model", "vllm"); entry.Quant != "fp16" {
+		t.Errorf("Expected the registered quant to be kept, got %+v", entry)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected only the first deploy to reach the cluster, got %d calls", got)
+	}
+}
+
+func TestDeployHandlerRedeployKeepsLiveWorker(t *testing.T) {
+	var calls atomic.Int32
+	deployWorker = func(ctx context.Context, model, runtime, quant string, opts k8s.DeployOptions) (*k8s.WorkerDeployment, error) {
+		calls.Add(1)
+		return &k8s.WorkerDeployment{ServiceURL: "http://worker:8000", Namespace: "default", Deployment: "worker-vllm-test-model", Service: "worker-vllm-test-model"}, nil
+	}
+	defer func() { deployWorker = k8s.DeployWorker }()
+
+	for _, status := range []string{"ready", "deploying"} {
+		t.Run(status, func(t *testing.T) {
+			calls.Store(0)
+			registry := controlplane.NewRegistry()
+			registry.Set(controlplane.Entry{Model: "test-model", Runtime: "vllm", Quant: "fp16", ServiceURL: "http://worker:8000", Status: status, Deployment: "worker-vllm-test-model", Paused: true, Warm: true, MaxContext: 4096, MetadataFetched: true})
+			before, _ := registry.Get("test-model", "vllm")
+			handler := DeployHandler(registry, nil, t.TempDir())
+
+			req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(`{"model":"test-model","runtime":"vllm","quant":"fp16"}`)))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != http.StatusOK {
+				t.Fatalf("Redeploy returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+			}
+
+			var resp DeployResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Status != status || resp.K8s.Deployment != "worker-vllm-test-model" {
+				t.Errorf("Expected the registered deployment to be reported, got %+v", resp)
+			}
+			if after, _ := registry.Get("test-model", "vllm"); after != before {
+				t.Errorf("Expected the registry entry to be unchanged, got %+v want %+v", after, before)
+			}
+			if got := calls.Load(); got != 0 {
+				t.Errorf("Expected the redeploy not to reach the cluster, got %d calls", got)
+			}
+		})
+	}
+}
+
+func TestDeployHandlerRedeploysFailedWorker(t *testing.T) {
+	var calls atomic.Int32
+	deployWorker = func(ctx context.Context, model, runtime, quant string, opts k8s.DeployOptions) (*k8s.WorkerDeployment, error) {
+		calls.Add(1)
+		return &k8s.WorkerDeployment{ServiceURL: "http://worker:8000", Namespace: "default", Deployment: "worker-vllm-test-model", Service: "worker-vllm-test-model"}, nil
+	}
+	defer func() { deployWorker = k8s.DeployWorker }()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "vllm", Quant: "fp16", Status: "failed", Paused: true})
+	handler := DeployHandler(registry, nil, t.TempDir())
+
+	req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(`{"model":"test-model","runtime":"vllm","quant":"fp16"}`)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Redeploy returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected a failed worker to be deployed again, got %d calls", got)
+	}
+	if entry, _ := registry.Get("test-model", "vllm"); entry.Status != "deploying" || !entry.Paused {
+		t.Errorf("Expected a paused deploying entry, got %+v", entry)
+	}
+}
+
+func TestDeployHandlerAwaitsReadinessOfClusterWorkers(t *testing.T) {
+	deployWorker = func(ctx context.Context, model, runtime, quant string, opts k8s.DeployOptions) (*k8s.WorkerDeployment, error) {
+		return &k8s.WorkerDeployment{ServiceURL: "http://worker:8000", Namespace: "default", Deployment: "worker-vllm-test-model", Service: "worker-vllm-test-model"}, nil
+	}
+	restoreAwaitReady := awaitReady
+	var awaited []string
+	awaitReady = func(registry *controlplane.Registry, model, runtime string) {
+		awaited = append(awaited, runtime)
+	}
+	defer func() {
+		deployWorker = k8s.DeployWorker
+		awaitReady = restoreAwaitReady
+	}()
+
+	handler := DeployHandler(controlplane.NewRegistry(), nil, t.TempDir())
+	for _, runtime := range []string{"vllm", "minimal"} {
+		req := httptest.NewRequest("POST", "/api/v1/deploy", bytes.NewReader([]byte(`{"model":"test-model","runtime":"`+runtime+`","quant":"fp16"}`)))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Deploy on %s returned wrong status code: got %v want %v (%s)", runtime, rr.Code, http.StatusOK, rr.Body.String())
+		}
+	}
+
+	if len(awaited) != 1 || awaited[0] != "vllm" {
+		t.Errorf("Expected readiness to be awaited only for the cluster worker, got %v", awaited)
+	}
+}
+
+func TestDeployReleasesReservationWhenWorkerFails(t *testing.T) {
+	defer func() { deployWorker = k8s.DeployWorker }()
+
+	for name, fail := range map[string]func(){
+		"error": func() {},
+		"panic": func() { panic("cluster unreachable") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			deployWorker = func(ctx context.Context, model, runtime, quant string, opts k8s.DeployOptions) (*k8s.WorkerDeployment, error) {
+				fail()
+				return nil, errors.New("cluster unreachable")
+			}
+			registry := controlplane.NewRegistry()
+
+			func() {
+				defer func() { recover() }()
+				if _, err := deploy(context.Background(), registry, nil, DeployRequest{Model: "test-model", Runtime: "vllm", Quant: "fp16"}, nil); err == nil {
+					t.Error("Expected the deploy to fail")
+				}
+			}()
+
+			if entry, found := registry.Get("test-model", "vllm"); found {
+				t.Errorf("Expected the reserved slot to be released, got %+v", entry)
+			}
+		})
+	}
+}
@@ -2,78 +2,335 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/tokenforge/llm-infra-bench/controlplane"
+	"github.com/tokenforge/llm-infra-bench/controlplane/k8s"
+	"github.com/tokenforge/llm-infra-bench/db"
+	"github.com/tokenforge/llm-infra-bench/events"
 )
 
 // DeploymentStatus represents the status of a model deployment
 type DeploymentStatus struct {
-	Model     string    `json:"model"`
-	Runtime   string    `json:"runtime"`
-	Quant     string    `json:"quant"`
-	Status    string    `json:"status"`
-	Endpoint  string    `json:"endpoint,omitempty"`
-	Error     string    `json:"error,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Model    string `json:"model"`
+	Runtime  string `json:"runtime"`
+	Quant    string `json:"quant"`
+	Status   string `json:"status"`
+	Endpoint string `json:"endpoint,omitempty"`
+	Paused   bool   `json:"paused"`
+	Warm     bool   `json:"warm"`
+	// ConfigHash identifies the effective model and runtime config the worker was deployed from
+	ConfigHash string `json:"config_hash,omitempty"`
+	// Overrides are the resource overrides the deployment was requested with
+	Overrides *controlplane.ResourceOverrides `json:"overrides,omitempty"`
+	// QueueDepth is the worker's last scraped queue depth, or "unknown"
+	QueueDepth interface{} `json:"queue_depth"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// deploymentStatus describes a registry entry in API responses
+func deploymentStatus(entry controlplane.Entry) DeploymentStatus {
+	return DeploymentStatus{
+		Model:      entry.Model,
+		Runtime:    entry.Runtime,
+		Quant:      entry.Quant,
+		Status:     entry.Status,
+		Endpoint:   entry.ServiceURL,
+		Paused:     entry.Paused,
+		Warm:       entry.Warm,
+		ConfigHash: entry.ConfigHash,
+		Overrides:  entry.Overrides,
+		QueueDepth: queueDepthValue(entry),
+		CreatedAt:  entry.CreatedAt,
+		UpdatedAt:  entry.UpdatedAt,
+	}
+}
+
+// DriftResponse lists live deployments that no longer match the current config
+type DriftResponse struct {
+	Checked int                   `json:"checked"`
+	Drifted []k8s.DeploymentDrift `json:"drifted"`
 }
 
 // DeploymentsHandler returns all current deployments
 func DeploymentsHandler(registry *controlplane.Registry) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		deployments := []DeploymentStatus{}
-		
+
 		// Get all deployments from the registry
 		for _, entry := range registry.GetAll() {
-			deployment := DeploymentStatus{
-				Model:     entry.Model,
-				Runtime:   entry.Runtime,
-				Quant:     entry.Quant,
-				Status:    entry.Status,
-				Endpoint:  entry.ServiceURL,
-				CreatedAt: entry.CreatedAt,
-				UpdatedAt: entry.UpdatedAt,
-			}
-			
-			deployments = append(deployments, deployment)
+			deployments = append(deployments, deploymentStatus(entry))
 		}
-		
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(deployments)
 	}
 }
 
 // DeploymentStatusHandler returns the status of a specific deployment
-func DeploymentStatusHandler(registry *controlplane.Registry) http.HandlerFunc {
+func DeploymentStatusHandler(registry *controlplane.Registry, configPath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		model := chi.URLParam(r, "model")
+		model := modelParam(r, configPath)
 		runtime := chi.URLParam(r, "runtime")
-		
+
 		if model == "" || runtime == "" {
 			http.Error(w, "Missing model or runtime parameter", http.StatusBadRequest)
 			return
 		}
-		
+
+		entry, ok := registry.Get(model, runtime)
+		if !ok {
+			http.Error(w, "Deployment not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(deploymentStatus(entry))
+	}
+}
+
+// DeploymentDriftHandler reports live worker deployments whose spec differs from the current config
+func DeploymentDriftHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		drifted, checked, err := k8s.DetectDrift(r.Context(), k8s.DefaultNamespace)
+		if err != nil {
+			http.Error(w, "failed to detect drift: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp := DriftResponse{
+			Checked: checked,
+			Drifted: drifted,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// DeploymentUsageHandler returns the current resource usage of a deployment's pods
+func DeploymentUsageHandler(registry *controlplane.Registry, configPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		model := modelParam(r, configPath)
+		runtime := chi.URLParam(r, "runtime")
+
+		entry, ok := registry.Get(model, runtime)
+		if !ok {
+			http.Error(w, "Deployment not found", http.StatusNotFound)
+			return
+		}
+		if entry.Runtime == "minimal" || entry.Deployment == "" {
+			http.Error(w, "resource usage is not available for local workers", http.StatusServiceUnavailable)
+			return
+		}
+
+		usage, err := k8s.GetDeploymentUsage(r.Context(), entry.Namespace, entry.Deployment)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, k8s.ErrMetricsUnavailable) {
+				status = http.StatusServiceUnavailable
+			}
+			http.Error(w, "failed to get deployment usage: "+err.Error(), status)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(usage)
+	}
+}
+
+// DeploymentK8sStatusHandler returns the live Deployment conditions and pod states from the cluster
+func DeploymentK8sStatusHandler(registry *controlplane.Registry, configPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		model := modelParam(r, configPath)
+		runtime := chi.URLParam(r, "runtime")
+
+		entry, ok := registry.Get(model, runtime)
+		if !ok {
+			http.Error(w, "Deployment not found", http.StatusNotFound)
+			return
+		}
+		if entry.Runtime == "minimal" || entry.Deployment == "" {
+			http.Error(w, "Kubernetes status is not available for local workers", http.StatusNotFound)
+			return
+		}
+
+		status, err := k8s.GetDeploymentStatus(r.Context(), entry.Namespace, entry.Deployment)
+		if err != nil {
+			code := http.StatusInternalServerError
+			if errors.Is(err, k8s.ErrDeploymentNotFound) {
+				code = http.StatusNotFound
+			}
+			http.Error(w, "failed to get deployment status: "+err.Error(), code)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	}
+}
+
+// DeploymentPauseHandler pauses or resumes inference routing to a deployment without touching k8s
+func DeploymentPauseHandler(registry *controlplane.Registry, dbClient *db.Client, configPath string, paused bool) http.HandlerFunc {
+	action := auditActionPause
+	if !paused {
+		action = auditActionResume
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		model := modelParam(r, configPath)
+		runtime := chi.URLParam(r, "runtime")
+
+		if !registry.SetPaused(model, runtime, paused) {
+			http.Error(w, "Deployment not found", http.StatusNotFound)
+			return
+		}
+		eventType := events.DeploymentPaused
+		if !paused {
+			eventType = events.DeploymentResumed
+		}
+		events.Publish(r.Context(), events.Event{Type: eventType, Model: model, Runtime: runtime})
+		audit(r, dbClient, db.AuditEvent{
+			Action:     action,
+			Model:      model,
+			Runtime:    runtime,
+			StatusCode: http.StatusOK,
+		})
+
+		entry, _ := registry.Get(model, runtime)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(deploymentStatus(entry))
+	}
+}
+
+// DeploymentRestartHandler triggers a rollout restart of a deployment's pods
+func DeploymentRestartHandler(registry *controlplane.Registry, dbClient *db.Client, configPath string) http.HandlerFunc {
+	controller := controlplane.NewController(registry)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		model := modelParam(r, configPath)
+		runtime := chi.URLParam(r, "runtime")
+
 		entry, ok := registry.Get(model, runtime)
 		if !ok {
 			http.Error(w, "Deployment not found", http.StatusNotFound)
 			return
 		}
-		
-		deployment := DeploymentStatus{
-			Model:     entry.Model,
-			Runtime:   entry.Runtime,
-			Quant:     entry.Quant,
-			Status:    entry.Status,
-			Endpoint:  entry.ServiceURL,
-			CreatedAt: entry.CreatedAt,
-			UpdatedAt: entry.UpdatedAt,
-		}
-		
+		if entry.Runtime == "minimal" || entry.Deployment == "" {
+			http.Error(w, "restart is not supported for local workers", http.StatusBadRequest)
+			return
+		}
+
+		err := controller.RestartDeployment(r.Context(), model, runtime)
+		status := http.StatusAccepted
+		if errors.Is(err, controlplane.ErrNotDeployed) {
+			status = http.StatusNotFound
+		} else if err != nil {
+			status = http.StatusInternalServerError
+		}
+		audit(r, dbClient, db.AuditEvent{
+			Action:     auditActionRestart,
+			Model:      model,
+			Runtime:    runtime,
+			StatusCode: status,
+		})
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		entry, _ = registry.Get(model, runtime)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(deploymentStatus(entry))
+	}
+}
+
+// modelParam returns the model path parameter resolved to its canonical name, so aliases
+// accepted at deploy time also address the deployment. Model names such as org/name are sent
+// percent-encoded so the slash does not split the path.
+func modelParam(r *http.Request, configPath string) string {
+	model := chi.URLParam(r, "model")
+	if unescaped, err := url.PathUnescape(model); err == nil {
+		model = unescaped
+	}
+	if model == "" {
+		return ""
+	}
+	return canonicalModelName(configPath, model)
+}
+
+// TeardownResult reports the outcome of tearing down one runtime deployment of a model
+type TeardownResult struct {
+	Runtime string `json:"runtime"`
+	// Status is "deleted" or "failed"
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ModelTeardownResponse is the response for tearing down every deployment of a model
+type ModelTeardownResponse struct {
+	Model   string           `json:"model"`
+	Results []TeardownResult `json:"results"`
+	Failed  int              `json:"failed"`
+}
+
+// ModelTeardownHandler tears down every runtime deployment of a model, or only the one named by
+// the runtime query parameter. A model with no deployments is not an error, so repeating the
+// call is safe. Partial failures are reported per runtime with 207 Multi-Status; failed
+// deployments stay registered for a retry.
+func ModelTeardownHandler(registry *controlplane.Registry, dbClient *db.Client, configPath string) http.HandlerFunc {
+	controller := controlplane.NewController(registry)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		model := modelParam(r, configPath)
+		if model == "" {
+			http.Error(w, "Missing model parameter", http.StatusBadRequest)
+			return
+		}
+
+		runtime := r.URL.Query().Get("runtime")
+		resp := ModelTeardownResponse{Model: model, Results: []TeardownResult{}}
+		for _, entry := range registry.GetAll() {
+			if entry.Model != model || (runtime != "" && entry.Runtime != runtime) {
+				continue
+			}
+
+			result := TeardownResult{Runtime: entry.Runtime, Status: "deleted"}
+			status := http.StatusOK
+			err := controller.TeardownDeployment(r.Context(), entry.Model, entry.Runtime)
+			if errors.Is(err, controlplane.ErrNotDeployed) {
+				// Torn down concurrently; the outcome is the same
+				err = nil
+			}
+			if err != nil {
+				result.Status = "failed"
+				result.Error = err.Error()
+				status = http.StatusInternalServerError
+				resp.Failed++
+			}
+			audit(r, dbClient, db.AuditEvent{
+				Action:     auditActionTeardown,
+				Model:      entry.Model,
+				Runtime:    entry.Runtime,
+				StatusCode: status,
+				Detail:     result.Error,
+			})
+			resp.Results = append(resp.Results, result)
+		}
+
+		status := http.StatusOK
+		if resp.Failed > 0 {
+			status = http.StatusMultiStatus
+		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(deployment)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(resp)
 	}
 }
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+func TestPausedDeploymentRejectsInference(t *testing.T) {
+	var gotMaxTokens int
+	worker := newTestWorker(t, &gotMaxTokens)
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+
+	router := chi.NewRouter()
+	router.Post("/deployments/{model}/{runtime}/pause", DeploymentPauseHandler(registry, nil, "", true))
+	router.Post("/deployments/{model}/{runtime}/resume", DeploymentPauseHandler(registry, nil, "", false))
+	router.Get("/deployments/{model}/{runtime}", DeploymentStatusHandler(registry, ""))
+	router.Post("/infer", InferHandler(registry, nil, writeTestModelsConfig(t)))
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+	infer := `{"model":"test-model","runtime":"minimal","prompt":"hello"}`
+
+	if rr := do("POST", "/deployments/test-model/minimal/pause", ""); rr.Code != http.StatusOK {
+		t.Fatalf("Pause returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	var status DeploymentStatus
+	json.Unmarshal(do("GET", "/deployments/test-model/minimal", "").Body.Bytes(), &status)
+	if !status.Paused {
+		t.Errorf("Expected status endpoint to report the deployment as paused")
+	}
+
+	if rr := do("POST", "/infer", infer); rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Infer on paused deployment returned wrong status code: got %v want %v", rr.Code, http.StatusServiceUnavailable)
+	}
+	if gotMaxTokens != 0 {
+		t.Errorf("Expected paused deployment not to receive traffic")
+	}
+
+	do("POST", "/deployments/test-model/minimal/resume", "")
+	if rr := do("POST", "/infer", infer); rr.Code != http.StatusOK {
+		t.Errorf("Infer after resume returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	if rr := do("POST", "/deployments/missing/minimal/pause", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Pause of unknown deployment returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestDeploymentRestartHandlerNotDeployed(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	router := chi.NewRouter()
+	router.Post("/deployments/{model}/{runtime}/restart", DeploymentRestartHandler(registry, nil, ""))
+
+	req := httptest.NewRequest("POST", "/deployments/missing/vllm/restart", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Restart of unknown deployment returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestModelTeardownHandlerIsIdempotent(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: "http://localhost:8000", Status: "ready"})
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "local", ServiceURL: "http://localhost:8001", Status: "ready"})
+	registry.Set(controlplane.Entry{Model: "other-model", Runtime: "minimal", ServiceURL: "http://localhost:8002", Status: "ready"})
+
+	router := chi.NewRouter()
+	router.Delete("/deployments/{model}", ModelTeardownHandler(registry, nil, ""))
+
+	teardown := func() ModelTeardownResponse {
+		req := httptest.NewRequest("DELETE", "/deployments/test-model", nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Teardown returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var resp ModelTeardownResponse
+		json.Unmarshal(rr.Body.Bytes(), &resp)
+		return resp
+	}
+
+	resp := teardown()
+	if len(resp.Results) != 2 || resp.Failed != 0 {
+		t.Fatalf("Expected 2 successful results, got %+v", resp)
+	}
+	for _, result := range resp.Results {
+		if result.Status != "deleted" {
+			t.Errorf("Expected runtime %s to be deleted, got %s", result.Runtime, result.Status)
+		}
+	}
+	if _, found := registry.Get("test-model", "minimal"); found {
+		t.Error("Expected torn down deployment to be removed from the registry")
+	}
+	if _, found := registry.Get("other-model", "minimal"); !found {
+		t.Error("Expected deployments of other models to be left in place")
+	}
+
+	if resp := teardown(); len(resp.Results) != 0 {
+		t.Errorf("Expected repeated teardown to report no results, got %+v", resp.Results)
+	}
+}
+
+func TestModelTeardownHandlerFiltersByRuntime(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: "http://localhost:8000", Status: "ready"})
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "local", ServiceURL: "http://localhost:8001", Status: "ready"})
+
+	router := chi.NewRouter()
+	router.Delete("/deployments/{model}", ModelTeardownHandler(registry, nil, ""))
+
+	req := httptest.NewRequest("DELETE", "/deployments/test-model?runtime=local", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var resp ModelTeardownResponse
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || len(resp.Results) != 1 || resp.Results[0].Runtime != "local" {
+		t.Fatalf("Expected only the local runtime to be torn down, got %v %+v", rr.Code, resp)
+	}
+	if _, found := registry.Get("test-model", "minimal"); !found {
+		t.Error("Expected the other runtime of the model to be left in place")
+	}
+}
+
+func TestModelTeardownHandlerAcceptsSlashedModelName(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "meta-llama/Llama-3-8b-instruct", Runtime: "minimal", ServiceURL: "http://localhost:8000", Status: "ready"})
+
+	router := chi.NewRouter()
+	router.Delete("/deployments/{model}", ModelTeardownHandler(registry, nil, ""))
+
+	req := httptest.NewRequest("DELETE", "/deployments/"+url.PathEscape("meta-llama/Llama-3-8b-instruct")+"?runtime=minimal", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var resp ModelTeardownResponse
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if rr.Code != http.StatusOK || len(resp.Results) != 1 || resp.Model != "meta-llama/Llama-3-8b-instruct" {
+		t.Fatalf("Expected the slashed model to be torn down, got %v %+v", rr.Code, resp)
+	}
+	if _, found := registry.Get("meta-llama/Llama-3-8b-instruct", "minimal"); found {
+		t.Error("Expected torn down deployment to be removed from the registry")
+	}
+}
+
+func TestDeploymentHandlersResolveModelAlias(t *testing.T) {
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: "http://localhost:8000", Status: "ready"})
+	configPath := writeTestModelsConfig(t)
+
+	router := chi.NewRouter()
+	router.Get("/deployments/{model}/{runtime}", DeploymentStatusHandler(registry, configPath))
+	router.Post("/deployments/{model}/{runtime}/pause", DeploymentPauseHandler(registry, nil, configPath, true))
+	router.Delete("/deployments/{model}", ModelTeardownHandler(registry, nil, configPath))
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+		return rr
+	}
+
+	var status DeploymentStatus
+	rr := do("GET", "/deployments/tm/minimal")
+	json.Unmarshal(rr.Body.Bytes(), &status)
+	if rr.Code != http.StatusOK || status.Model != "test-model" {
+		t.Errorf("Expected the alias to resolve to the deployment, got %v %+v", rr.Code, status)
+	}
+	if rr := do("POST", "/deployments/tm/minimal/pause"); rr.Code != http.StatusOK {
+		t.Errorf("Pause by alias returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if entry, _ := registry.Get("test-model", "minimal"); !entry.Paused {
+		t.Errorf("Expected pause by alias to pause the deployment")
+	}
+
+	var teardown ModelTeardownResponse
+	json.Unmarshal(do("DELETE", "/deployments/tm").Body.Bytes(), &teardown)
+	if teardown.Model != "test-model" || len(teardown.Results) != 1 {
+		t.Errorf("Expected teardown by alias to remove the deployment, got %+v", teardown)
+	}
+}
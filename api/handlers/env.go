@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// envInt reads an integer environment variable, returning def when unset or invalid
+func envInt(name string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return v
+	}
+	return def
+}
+
+// envDuration reads a duration environment variable such as "30s", returning def when unset or invalid
+func envDuration(name string, def time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(name)); err == nil && v > 0 {
+		return v
+	}
+	return def
+}
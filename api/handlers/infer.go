@@ -1,16 +1,228 @@
 package handlers
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"mime"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/tokenforge/llm-infra-bench/controlplane"
+	"github.com/tokenforge/llm-infra-bench/controlplane/k8s"
+	"github.com/tokenforge/llm-infra-bench/db"
+	"github.com/tokenforge/llm-infra-bench/events"
 )
 
+// maxLoggedContentLen bounds how much prompt/output text is stored per inference
+const maxLoggedContentLen = 512
+
+// maxErrorSnippetLen bounds how much of a non-JSON worker error body is returned to clients
+const maxErrorSnippetLen = 256
+
+// WorkerErrorResponse is the structured error returned when a worker fails with a non-JSON
+// body, such as an HTML page from a proxy in front of it
+type WorkerErrorResponse struct {
+	Error struct {
+		Message        string `json:"message"`
+		UpstreamStatus int    `json:"upstream_status"`
+		ContentType    string `json:"content_type,omitempty"`
+		Snippet        string `json:"snippet,omitempty"`
+	} `json:"error"`
+}
+
+// isJSONContentType reports whether a Content-Type header names a JSON media type
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isEventStream reports whether a content type is text/event-stream, ignoring parameters
+func isEventStream(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "text/event-stream"
+}
+
+// relayWorkerStream copies a worker's event stream to the client line by line as it arrives,
+// flushing each one. The relay ends early when the request's deadline passes, the client goes
+// away or shutdown closes the open streams.
+func relayWorkerStream(ctx context.Context, w http.ResponseWriter, body io.Reader, timeout time.Duration) {
+	flusher, _ := w.(http.Flusher)
+
+	// The body is read on its own goroutine so the relay can stop while a read is blocked;
+	// closing the body once the handler returns ends the read
+	stop := make(chan struct{})
+	defer close(stop)
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(body)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-stop:
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case line := <-lines:
+			if _, err := w.Write(line); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		case err := <-readErr:
+			if !errors.Is(err, io.EOF) {
+				writeStreamTimeout(ctx, w, timeout)
+			}
+			return
+		case <-streams.closing:
+			writeShutdownEvent(w)
+			return
+		case <-ctx.Done():
+			writeStreamTimeout(ctx, w, timeout)
+			return
+		}
+	}
+}
+
+// writeStreamTimeout ends a stream that ran past its timeout_ms with an error event. The
+// headers are already sent, so the status can no longer say so. A stream that ended for any
+// other reason, such as the client going away, gets nothing more.
+func writeStreamTimeout(ctx context.Context, w http.ResponseWriter, timeout time.Duration) {
+	if timeout <= 0 || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return
+	}
+	fmt.Fprintf(w, "data: {\"error\":\"inference exceeded timeout_ms of %d\"}\n\n", timeout.Milliseconds())
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// writeWorkerError wraps a non-JSON worker error body in a WorkerErrorResponse, keeping the
+// upstream status code
+func writeWorkerError(w http.ResponseWriter, status int, contentType string, body []byte) {
+	var resp WorkerErrorResponse
+	resp.Error.Message = fmt.Sprintf("worker returned %d %s", status, http.StatusText(status))
+	resp.Error.UpstreamStatus = status
+	resp.Error.ContentType = contentType
+	resp.Error.Snippet = strings.ToValidUTF8(truncate(strings.TrimSpace(string(body)), maxErrorSnippetLen), "")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// fallbackDefaultMaxTokens is used when neither the model nor DEFAULT_MAX_TOKENS sets a default
+const fallbackDefaultMaxTokens = 128
+
+// resolveMaxTokens applies the model's max_tokens default and cap, falling back to the
+// DEFAULT_MAX_TOKENS and MAX_TOKENS_CAP environment variables when the model sets neither.
+// A cap of zero means unlimited.
+func resolveMaxTokens(requested int, model *ModelEntry) (int, error) {
+	defaultMaxTokens := envInt("DEFAULT_MAX_TOKENS", fallbackDefaultMaxTokens)
+	maxTokensCap := envInt("MAX_TOKENS_CAP", 0)
+	if model != nil {
+		if model.DefaultMaxTokens > 0 {
+			defaultMaxTokens = model.DefaultMaxTokens
+		}
+		if model.MaxTokensCap > 0 {
+			maxTokensCap = model.MaxTokensCap
+		}
+	}
+
+	if requested < 0 {
+		return 0, fmt.Errorf("max_tokens must not be negative")
+	}
+	if requested == 0 {
+		requested = defaultMaxTokens
+		if maxTokensCap > 0 && requested > maxTokensCap {
+			requested = maxTokensCap
+		}
+	}
+	if maxTokensCap > 0 && requested > maxTokensCap {
+		return 0, fmt.Errorf("max_tokens %d exceeds the cap of %d for this model", requested, maxTokensCap)
+	}
+
+	return requested, nil
+}
+
+// contextWindow returns the worker-reported context window, or the configured one when the
+// worker does not report it. Zero means no limit is known.
+func contextWindow(entry controlplane.Entry, model *ModelEntry) int {
+	if entry.MaxContext > 0 {
+		return entry.MaxContext
+	}
+	if model != nil {
+		return model.MaxContext
+	}
+	return 0
+}
+
+// estimatePromptTokens approximates the prompt's token count at about four characters per token
+func estimatePromptTokens(prompt string) int {
+	return (len(prompt) + 3) / 4
+}
+
+// splitModelRuntime splits a "model@runtime" string, returning an empty runtime when none is encoded
+func splitModelRuntime(model string) (string, string) {
+	if i := strings.LastIndex(model, "@"); i > 0 && i < len(model)-1 {
+		return model[:i], model[i+1:]
+	}
+	return model, ""
+}
+
+// resolveRuntime picks the runtime for a request from the body, a model@runtime suffix or the
+// X-Runtime header, falling back to the model's default_runtime and then DEFAULT_RUNTIME.
+// Explicit choices that disagree are rejected rather than silently preferring one.
+func resolveRuntime(r *http.Request, req *InferRequest, configPath string) error {
+	model, suffix := splitModelRuntime(req.Model)
+	req.Model = model
+
+	for _, candidate := range []string{suffix, r.Header.Get("X-Runtime")} {
+		if candidate == "" {
+			continue
+		}
+		if req.Runtime != "" && req.Runtime != candidate {
+			return fmt.Errorf("conflicting runtimes requested: %s and %s", req.Runtime, candidate)
+		}
+		req.Runtime = candidate
+	}
+
+	if req.Runtime == "" && req.Model != "" {
+		if models, err := loadModelsConfig(configPath); err == nil {
+			if entry, found := models.findModel(req.Model); found {
+				req.Runtime = entry.DefaultRuntime
+			}
+		}
+	}
+	if req.Runtime == "" {
+		req.Runtime = os.Getenv("DEFAULT_RUNTIME")
+	}
+	return nil
+}
+
 type InferRequest struct {
 	Model       string  `json:"model"`
 	Runtime     string  `json:"runtime"`
@@ -19,6 +231,8 @@ type InferRequest struct {
 	Temperature float64 `json:"temperature"`
 	TopP        float64 `json:"top_p"`
 	Stream      bool    `json:"stream"`
+	// TimeoutMs bounds the worker call, or the whole stream when streaming; 0 means no deadline
+	TimeoutMs int `json:"timeout_ms,omitempty"`
 }
 
 type InferResponse struct {
@@ -34,36 +248,176 @@ type InferResponse struct {
 	} `json:"runtime_meta"`
 }
 
+// promptLoggingEnabled reports whether prompt and output content may be stored with inference records
+func promptLoggingEnabled() bool {
+	return os.Getenv("INFERENCE_LOG_PROMPTS") == "true"
+}
+
+// defaultMaxInferTimeout caps timeout_ms when INFER_MAX_TIMEOUT is unset
+const defaultMaxInferTimeout = 5 * time.Minute
+
+// inferTimeout converts a request's timeout_ms into a deadline for the worker call, capped at
+// INFER_MAX_TIMEOUT. A zero result means the request set no deadline.
+func inferTimeout(timeoutMs int) (time.Duration, error) {
+	if timeoutMs < 0 {
+		return 0, errors.New("timeout_ms must not be negative")
+	}
+	timeout := time.Duration(timeoutMs) * time.Millisecond
+	if limit := envDuration("INFER_MAX_TIMEOUT", defaultMaxInferTimeout); limit > 0 && timeout > limit {
+		timeout = limit
+	}
+	return timeout, nil
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+// recordInference stores an inference record in the background so it never delays the response
+func recordInference(dbClient *db.Client, inf db.Inference) {
+	if dbClient == nil {
+		return
+	}
+	if promptLoggingEnabled() {
+		inf.Prompt = truncate(inf.Prompt, maxLoggedContentLen)
+		inf.Output = truncate(inf.Output, maxLoggedContentLen)
+	} else {
+		inf.Prompt = ""
+		inf.Output = ""
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := dbClient.RecordInference(ctx, inf); err != nil {
+			log.Printf("Failed to record inference: %v", err)
+		}
+	}()
+}
+
 // InferHandler handles inference requests
-func InferHandler(registry *controlplane.Registry) http.HandlerFunc {
+func InferHandler(registry *controlplane.Registry, dbClient *db.Client, configPath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req InferRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if err := decodeJSON(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		timeout, err := inferTimeout(req.TimeoutMs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// New streams are refused while shutdown drains the open ones
+		if req.Stream {
+			done, ok := streams.begin()
+			if !ok {
+				http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+				return
+			}
+			defer done()
+		}
+
+		// Clients that can only send a model string pin the runtime with model@runtime or X-Runtime
+		if err := resolveRuntime(r, &req, configPath); err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
+		// Weighted routes pick the backend model and runtime for each request
+		routes, err := loadRoutesConfig(configPath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if route, found := routes.findRoute(req.Model); found {
+			backend := route.pickRandom()
+			req.Model = backend.Model
+			req.Runtime = backend.Runtime
+			w.Header().Set("X-Route", route.Name)
+		}
+
 		// Validate request
 		if req.Model == "" || req.Runtime == "" || req.Prompt == "" {
 			http.Error(w, "model, runtime, and prompt are required", http.StatusBadRequest)
 			return
 		}
 
+		// Resolve aliases to the canonical model name used as the registry key
+		req.Model = canonicalModelName(configPath, req.Model)
+		w.Header().Set("X-Model", req.Model)
+		w.Header().Set("X-Runtime", req.Runtime)
+
 		// Get worker endpoint from registry
-		workerURL, found := registry.Get(req.Model, req.Runtime)
+		entry, found := registry.Get(req.Model, req.Runtime)
 		if !found {
 			http.Error(w, "model not deployed with specified runtime", http.StatusNotFound)
 			return
 		}
+		if entry.Paused {
+			http.Error(w, "deployment is paused for maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		defer trackInFlight(req.Model, req.Runtime)()
+
+		workerURL := entry.ServiceURL
+
+		// Debug requests can target a single replica instead of the service
+		if targetPod := r.Header.Get("X-Target-Pod"); targetPod != "" {
+			if entry.Deployment == "" || entry.Runtime == "minimal" {
+				http.Error(w, "X-Target-Pod is only supported for cluster deployments", http.StatusBadRequest)
+				return
+			}
+			podURL, err := k8s.PodURL(r.Context(), entry.Namespace, entry.Deployment, targetPod)
+			if err != nil {
+				status := http.StatusBadGateway
+				if errors.Is(err, k8s.ErrPodNotInDeployment) {
+					status = http.StatusBadRequest
+				}
+				http.Error(w, "failed to route to target pod: "+err.Error(), status)
+				return
+			}
+			workerURL = podURL
+		}
+
+		// Apply the model's max_tokens default and cap
+		var modelEntry *ModelEntry
+		if models, err := loadModelsConfig(configPath); err == nil {
+			modelEntry, _ = models.findModel(req.Model)
+		}
+		maxTokens, err := resolveMaxTokens(req.MaxTokens, modelEntry)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		req.MaxTokens = maxTokens
 
-		// Prepare worker request
-		workerReq := map[string]interface{}{
+		// Enforce the context window the worker actually loaded, falling back to config
+		entry = cacheWorkerMetadata(r.Context(), registry, entry)
+		if maxContext := contextWindow(entry, modelEntry); maxContext > 0 {
+			if needed := estimatePromptTokens(req.Prompt) + req.MaxTokens; needed > maxContext {
+				http.Error(w, fmt.Sprintf("prompt and max_tokens need about %d tokens, exceeding the context window of %d", needed, maxContext), http.StatusBadRequest)
+				return
+			}
+		}
+
+		// Prepare worker request, renamed to the field names the runtime expects
+		fieldMapping, err := runtimeFieldMapping(configPath, req.Runtime)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		workerReq := mapWorkerRequest(map[string]interface{}{
 			"prompt":      req.Prompt,
 			"max_tokens":  req.MaxTokens,
 			"temperature": req.Temperature,
 			"top_p":       req.TopP,
 			"stream":      req.Stream,
-		}
+		}, fieldMapping)
 
 		reqBody, err := json.Marshal(workerReq)
 		if err != nil {
@@ -71,21 +425,106 @@ func InferHandler(registry *controlplane.Registry) http.HandlerFunc {
 			return
 		}
 
+		record := db.Inference{
+			Model:   req.Model,
+			Runtime: req.Runtime,
+			Route:   w.Header().Get("X-Route"),
+			Stream:  req.Stream,
+			Prompt:  req.Prompt,
+		}
+		start := time.Now()
+
 		// Forward request to worker
-		workerResp, err := http.Post(workerURL+"/infer", "application/json", bytes.NewBuffer(reqBody))
+		client, err := getWorkerClient()
 		if err != nil {
+			http.Error(w, "worker client misconfigured: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// timeout_ms cancels the upstream call when it expires, independently of the worker
+		// client's own timeout
+		ctx := r.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		timedOut := func(err error) bool {
+			if !errors.Is(err, context.DeadlineExceeded) || ctx.Err() == nil {
+				return false
+			}
+			record.StatusCode = http.StatusGatewayTimeout
+			record.LatencyMs = int(time.Since(start).Milliseconds())
+			observeInference(record.Model, record.Runtime, record.StatusCode, time.Since(start))
+			recordInference(dbClient, record)
+			http.Error(w, fmt.Sprintf("inference exceeded timeout_ms of %d", timeout.Milliseconds()), http.StatusGatewayTimeout)
+			return true
+		}
+
+		workerResp, err := postWorker(ctx, client, workerURL+"/infer", reqBody, forwardedTraceHeaders(r), inferRetries(r))
+		if err != nil {
+			if timedOut(err) {
+				return
+			}
+			record.StatusCode = http.StatusServiceUnavailable
+			record.LatencyMs = int(time.Since(start).Milliseconds())
+			observeInference(record.Model, record.Runtime, record.StatusCode, time.Since(start))
+			recordInference(dbClient, record)
 			http.Error(w, "failed to connect to worker: "+err.Error(), http.StatusServiceUnavailable)
 			return
 		}
 		defer workerResp.Body.Close()
 
+		// completed records the inference once the worker's response has been read
+		completed := func() {
+			record.StatusCode = workerResp.StatusCode
+			record.LatencyMs = int(time.Since(start).Milliseconds())
+			observeInference(record.Model, record.Runtime, record.StatusCode, time.Since(start))
+			events.Publish(r.Context(), events.Event{
+				Type:    events.InferenceCompleted,
+				Model:   record.Model,
+				Runtime: record.Runtime,
+				Data: map[string]interface{}{
+					"status_code": record.StatusCode,
+					"latency_ms":  record.LatencyMs,
+					"route":       record.Route,
+				},
+			})
+			recordInference(dbClient, record)
+		}
+
+		// A worker that streams is relayed event by event as it generates, rather than
+		// buffered until it finishes
+		if req.Stream && workerResp.StatusCode < 400 && isEventStream(workerResp.Header.Get("Content-Type")) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.WriteHeader(workerResp.StatusCode)
+			relayWorkerStream(ctx, w, workerResp.Body, timeout)
+			completed()
+			return
+		}
+
 		// Read worker response
 		respBody, err := io.ReadAll(workerResp.Body)
 		if err != nil {
+			if timedOut(err) {
+				return
+			}
 			http.Error(w, "failed to read worker response: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		if workerResp.Header.Get("Content-Type") != "text/event-stream" {
+			var parsed InferResponse
+			if json.Unmarshal(respBody, &parsed) == nil {
+				record.TokensIn = parsed.TokensIn
+				record.TokensOut = parsed.TokensOut
+				record.Output = parsed.Output
+			}
+		}
+		completed()
+
 		// If streaming is enabled, handle differently
 		if req.Stream {
 			// For streaming, we need to proxy the worker's streaming response
@@ -133,13 +572,27 @@ func InferHandler(registry *controlplane.Registry) http.HandlerFunc {
 					fmt.Fprintf(w, "data: %s\n\n", eventData)
 					w.(http.Flusher).Flush()
 					
-					// Simulate generation time
-					time.Sleep(100 * time.Millisecond)
+					// Simulate generation time, ending the stream early if shutdown cuts it off
+					select {
+					case <-streams.closing:
+						writeShutdownEvent(w)
+						return
+					case <-ctx.Done():
+						writeStreamTimeout(ctx, w, timeout)
+						return
+					case <-time.After(100 * time.Millisecond):
+					}
 				}
 			}
 			return
 		}
 
+		// Error pages that are not JSON are wrapped so clients always get a parseable error
+		if contentType := workerResp.Header.Get("Content-Type"); workerResp.StatusCode >= 400 && !isJSONContentType(contentType) {
+			writeWorkerError(w, workerResp.StatusCode, contentType, respBody)
+			return
+		}
+
 		// Set headers and return response for non-streaming
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(workerResp.StatusCode)
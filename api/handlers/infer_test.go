@@ -0,0 +1,462 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+// newTestWorker starts a fake worker that records the max_tokens it receives
+func newTestWorker(t *testing.T, gotMaxTokens *int) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if v, ok := body["max_tokens"].(float64); ok {
+			*gotMaxTokens = int(v)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"output":"ok","latency_ms":1,"tokens_in":1,"tokens_out":1}`))
+	}))
+}
+
+// writeTestModelsConfig writes a models.yaml with max_tokens limits to a temp dir
+func writeTestModelsConfig(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	testConfig := `models:
+  - name: test-model
+    quant: fp16
+    hash: sha256:test-hash
+    default_max_tokens: 64
+    max_tokens_cap: 256
+    aliases: [tm]
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "models.yaml"), []byte(testConfig), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	return tempDir
+}
+
+func TestInferHandlerAppliesDefaultMaxTokens(t *testing.T) {
+	var gotMaxTokens int
+	worker := newTestWorker(t, &gotMaxTokens)
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	body := []byte(`{"model":"test-model","runtime":"minimal","prompt":"hello"}`)
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if gotMaxTokens != 64 {
+		t.Errorf("Expected worker to receive default max_tokens 64, got %d", gotMaxTokens)
+	}
+}
+
+func TestInferHandlerRejectsMaxTokensAboveCap(t *testing.T) {
+	var gotMaxTokens int
+	worker := newTestWorker(t, &gotMaxTokens)
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	body := []byte(`{"model":"test-model","runtime":"minimal","prompt":"hello","max_tokens":100000}`)
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+	if gotMaxTokens != 0 {
+		t.Errorf("Expected request not to reach the worker, got max_tokens %d", gotMaxTokens)
+	}
+}
+
+func TestInferHandlerUsesGlobalMaxTokensDefault(t *testing.T) {
+	var gotMaxTokens int
+	worker := newTestWorker(t, &gotMaxTokens)
+	defer worker.Close()
+
+	t.Setenv("DEFAULT_MAX_TOKENS", "32")
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "unlisted-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	body := []byte(`{"model":"unlisted-model","runtime":"minimal","prompt":"hello"}`)
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if gotMaxTokens != 32 {
+		t.Errorf("Expected worker to receive global default max_tokens 32, got %d", gotMaxTokens)
+	}
+}
+
+func TestInferHandlerRejectsTargetPodForLocalWorker(t *testing.T) {
+	var gotMaxTokens int
+	worker := newTestWorker(t, &gotMaxTokens)
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	body := []byte(`{"model":"test-model","runtime":"minimal","prompt":"hello"}`)
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader(body))
+	req.Header.Set("X-Target-Pod", "worker-pod-0")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+	if gotMaxTokens != 0 {
+		t.Errorf("Expected request not to reach the worker")
+	}
+}
+
+func TestInferHandlerEnforcesWorkerContextWindow(t *testing.T) {
+	var inferCalls, metadataCalls int
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/metadata" {
+			metadataCalls++
+			w.Write([]byte(`{"model":"test-model","quant":"fp16","max_context":100}`))
+			return
+		}
+		inferCalls++
+		w.Write([]byte(`{"output":"ok","latency_ms":1,"tokens_in":1,"tokens_out":1}`))
+	}))
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	infer := func(prompt string, maxTokens int) int {
+		body, _ := json.Marshal(map[string]interface{}{"model": "test-model", "runtime": "minimal", "prompt": prompt, "max_tokens": maxTokens})
+		req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// 200 characters is about 50 tokens, which fits with 40 max_tokens but not with 60
+	prompt := strings.Repeat("a", 200)
+	if code := infer(prompt, 40); code != http.StatusOK {
+		t.Errorf("Request within context window returned %v, want %v", code, http.StatusOK)
+	}
+	if code := infer(prompt, 60); code != http.StatusBadRequest {
+		t.Errorf("Request over context window returned %v, want %v", code, http.StatusBadRequest)
+	}
+
+	if metadataCalls != 1 {
+		t.Errorf("Expected worker metadata to be fetched once, got %d", metadataCalls)
+	}
+	if entry, _ := registry.Get("test-model", "minimal"); entry.MaxContext != 100 {
+		t.Errorf("Expected max_context cached on the registry entry, got %d", entry.MaxContext)
+	}
+	if inferCalls != 1 {
+		t.Errorf("Expected only the request within the window to reach the worker, got %d", inferCalls)
+	}
+}
+
+func TestContextWindowFallsBackToConfig(t *testing.T) {
+	model := &ModelEntry{Name: "test-model", MaxContext: 2048}
+
+	if got := contextWindow(controlplane.Entry{MaxContext: 4096}, model); got != 4096 {
+		t.Errorf("Expected worker-reported window, got %d", got)
+	}
+	if got := contextWindow(controlplane.Entry{}, model); got != 2048 {
+		t.Errorf("Expected configured window, got %d", got)
+	}
+	if got := contextWindow(controlplane.Entry{}, nil); got != 0 {
+		t.Errorf("Expected no limit without worker or config value, got %d", got)
+	}
+}
+
+func TestInferHandlerResolvesRuntimeFromModelOrHeader(t *testing.T) {
+	var gotMaxTokens int
+	worker := newTestWorker(t, &gotMaxTokens)
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	tests := []struct {
+		name   string
+		body   string
+		header string
+		want   int
+	}{
+		{"model suffix", `{"model":"tm@minimal","prompt":"hello"}`, "", http.StatusOK},
+		{"header", `{"model":"test-model","prompt":"hello"}`, "minimal", http.StatusOK},
+		{"matching body and suffix", `{"model":"test-model@minimal","runtime":"minimal","prompt":"hello"}`, "", http.StatusOK},
+		{"conflicting suffix and header", `{"model":"test-model@minimal","prompt":"hello"}`, "vllm", http.StatusBadRequest},
+		{"no runtime", `{"model":"test-model","prompt":"hello"}`, "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader([]byte(tt.body)))
+			if tt.header != "" {
+				req.Header.Set("X-Runtime", tt.header)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("Handler returned wrong status code: got %v want %v (%s)", rr.Code, tt.want, rr.Body.String())
+			}
+			if tt.want == http.StatusOK {
+				if got := rr.Header().Get("X-Model"); got != "test-model" {
+					t.Errorf("Expected X-Model test-model, got %q", got)
+				}
+				if got := rr.Header().Get("X-Runtime"); got != "minimal" {
+					t.Errorf("Expected X-Runtime minimal, got %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestInferHandlerUsesDefaultRuntime(t *testing.T) {
+	var gotMaxTokens int
+	worker := newTestWorker(t, &gotMaxTokens)
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+	t.Setenv("DEFAULT_RUNTIME", "minimal")
+
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader([]byte(`{"model":"test-model","prompt":"hello"}`)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
+
+func TestInferHandlerWrapsNonJSONWorkerError(t *testing.T) {
+	page := "<html><body><h1>502 Bad Gateway</h1>" + strings.Repeat("<p>upstream unavailable</p>", 50) + "</body></html>"
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(page))
+	}))
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready", MetadataFetched: true})
+
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader([]byte(`{"model":"test-model","runtime":"minimal","prompt":"hello"}`)))
+	req.Header.Set("X-No-Retry", "true")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusBadGateway {
+		t.Fatalf("Expected upstream status %v to be preserved, got %v", http.StatusBadGateway, rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected a JSON error, got Content-Type %q", ct)
+	}
+
+	var resp WorkerErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Expected a structured error envelope, got %q: %v", rr.Body.String(), err)
+	}
+	if resp.Error.UpstreamStatus != http.StatusBadGateway || !strings.HasPrefix(resp.Error.ContentType, "text/html") {
+		t.Errorf("Expected upstream status and content type in the envelope, got %+v", resp.Error)
+	}
+	if !strings.Contains(resp.Error.Snippet, "502 Bad Gateway") || len(resp.Error.Snippet) > maxErrorSnippetLen {
+		t.Errorf("Expected a truncated snippet of the page, got %d bytes: %q", len(resp.Error.Snippet), resp.Error.Snippet)
+	}
+}
+
+func TestInferHandlerTimesOutSlowWorker(t *testing.T) {
+	cancelled := make(chan struct{})
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body has been consumed
+		io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+			close(cancelled)
+		case <-time.After(5 * time.Second):
+			w.Write([]byte(`{"output":"too late"}`))
+		}
+	}))
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready", MetadataFetched: true})
+
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader([]byte(`{"model":"test-model","runtime":"minimal","prompt":"hello","timeout_ms":50}`)))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusGatewayTimeout {
+		t.Fatalf("Expected %v for a request past its timeout_ms, got %v (%s)", http.StatusGatewayTimeout, rr.Code, rr.Body.String())
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the upstream request to be cancelled")
+	}
+}
+
+func TestInferHandlerStreamEndsSilentlyWhenClientLeaves(t *testing.T) {
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"output":"one two three four five six"}`))
+	}))
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready", MetadataFetched: true})
+
+	handler := InferHandler(registry, nil, writeTestModelsConfig(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader([]byte(`{"model":"test-model","runtime":"minimal","prompt":"hello","stream":true}`))).WithContext(ctx)
+	rr := httptest.NewRecorder()
+	time.AfterFunc(150*time.Millisecond, cancel)
+	handler.ServeHTTP(rr, req)
+
+	if strings.Contains(rr.Body.String(), "timeout_ms") {
+		t.Errorf("Expected no timeout event for a client that went away, got %q", rr.Body.String())
+	}
+	if strings.Contains(rr.Body.String(), `"is_last":true`) {
+		t.Errorf("Expected the stream to end once the client went away, got %q", rr.Body.String())
+	}
+}
+
+func TestInferTimeoutIsCappedAndValidated(t *testing.T) {
+	t.Setenv("INFER_MAX_TIMEOUT", "2s")
+
+	if timeout, err := inferTimeout(500); err != nil || timeout != 500*time.Millisecond {
+		t.Errorf("Expected 500ms, got %v, %v", timeout, err)
+	}
+	if timeout, err := inferTimeout(60000); err != nil || timeout != 2*time.Second {
+		t.Errorf("Expected timeout_ms to be capped at 2s, got %v, %v", timeout, err)
+	}
+	if timeout, err := inferTimeout(0); err != nil || timeout != 0 {
+		t.Errorf("Expected no deadline when timeout_ms is unset, got %v, %v", timeout, err)
+	}
+	if _, err := inferTimeout(-1); err == nil {
+		t.Error("Expected a negative timeout_ms to be rejected")
+	}
+}
+
+// flushSignalRecorder records a response and signals every flush, so a test can inspect
+// what has been relayed while the stream is still open
+type flushSignalRecorder struct {
+	*httptest.ResponseRecorder
+	flushed chan struct{}
+}
+
+func (r *flushSignalRecorder) Flush() {
+	r.ResponseRecorder.Flush()
+	r.flushed <- struct{}{}
+}
+
+func TestRelayWorkerStreamForwardsEventsAsTheyArrive(t *testing.T) {
+	body, worker := io.Pipe()
+	rec := &flushSignalRecorder{ResponseRecorder: httptest.NewRecorder(), flushed: make(chan struct{})}
+
+	relayed := make(chan struct{})
+	go func() {
+		relayWorkerStream(context.Background(), rec, body, 0)
+		close(relayed)
+	}()
+
+	worker.Write([]byte("data: {\"token\":\"one\"}\n"))
+	select {
+	case <-rec.flushed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the first event to be relayed before the worker finished")
+	}
+	if got := rec.Body.String(); got != "data: {\"token\":\"one\"}\n" {
+		t.Errorf("Expected the first event to be relayed as sent, got %q", got)
+	}
+
+	go func() {
+		for range rec.flushed {
+		}
+	}()
+	worker.Write([]byte("\ndata: {\"token\":\"two\"}\n\n"))
+	worker.Close()
+	<-relayed
+	close(rec.flushed)
+	if !strings.Contains(rec.Body.String(), "two") || strings.Contains(rec.Body.String(), "error") {
+		t.Errorf("Expected the whole stream without an error event, got %q", rec.Body.String())
+	}
+}
+
+func TestRelayWorkerStreamEndsAtDeadlineAndShutdown(t *testing.T) {
+	t.Run("deadline", func(t *testing.T) {
+		body, worker := io.Pipe()
+		defer worker.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		rr := httptest.NewRecorder()
+		relayWorkerStream(ctx, rr, body, 50*time.Millisecond)
+		if !strings.Contains(rr.Body.String(), "inference exceeded timeout_ms of 50") {
+			t.Errorf("Expected a timeout event, got %q", rr.Body.String())
+		}
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		restore := streams
+		streams = newStreamTracker()
+		defer func() { streams = restore }()
+
+		body, worker := io.Pipe()
+		defer worker.Close()
+		close(streams.closing)
+
+		rr := httptest.NewRecorder()
+		relayWorkerStream(context.Background(), rr, body, 0)
+		if !strings.HasPrefix(rr.Body.String(), "event: shutdown\n") {
+			t.Errorf("Expected a shutdown event, got %q", rr.Body.String())
+		}
+	})
+}
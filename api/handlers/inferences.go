@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+const (
+	defaultInferencesLimit = 20
+	maxInferencesLimit     = 200
+)
+
+// RecentInferencesHandler returns the most recent inferences served by a deployment, newest first.
+// Prompt and output content is only included when prompt logging is enabled.
+func RecentInferencesHandler(dbClient *db.Client, configPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dbClient == nil {
+			http.Error(w, "Database not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		model := modelParam(r, configPath)
+		runtime := chi.URLParam(r, "runtime")
+		if model == "" || runtime == "" {
+			http.Error(w, "Missing model or runtime parameter", http.StatusBadRequest)
+			return
+		}
+
+		limit := defaultInferencesLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+				return
+			}
+			if n > maxInferencesLimit {
+				n = maxInferencesLimit
+			}
+			limit = n
+		}
+
+		inferences, err := dbClient.ListRecentInferences(r.Context(), model, runtime, limit)
+		if err != nil {
+			http.Error(w, "failed to list inferences: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if !promptLoggingEnabled() {
+			for i := range inferences {
+				inferences[i].Prompt = ""
+				inferences[i].Output = ""
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(inferences)
+	}
+}
+
+const (
+	defaultStatsWindow = time.Hour
+	maxStatsWindow     = 30 * 24 * time.Hour
+)
+
+// InferenceStatsResponse is the response for the inference stats endpoint
+type InferenceStatsResponse struct {
+	Model   string `json:"model,omitempty"`
+	Runtime string `json:"runtime,omitempty"`
+	Window  string `json:"window"`
+	db.InferenceStats
+}
+
+// InferenceStatsHandler returns latency percentiles and throughput from recorded inferences
+func InferenceStatsHandler(dbClient *db.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dbClient == nil {
+			http.Error(w, "Database not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		model := r.URL.Query().Get("model")
+		runtime := r.URL.Query().Get("runtime")
+
+		window := defaultStatsWindow
+		if v := r.URL.Query().Get("window"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				http.Error(w, "window must be a positive duration such as 15m or 1h", http.StatusBadRequest)
+				return
+			}
+			if d > maxStatsWindow {
+				d = maxStatsWindow
+			}
+			window = d
+		}
+
+		stats, err := dbClient.GetInferenceStats(r.Context(), model, runtime, window)
+		if err != nil {
+			http.Error(w, "failed to get inference stats: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(InferenceStatsResponse{
+			Model:          model,
+			Runtime:        runtime,
+			Window:         window.String(),
+			InferenceStats: stats,
+		})
+	}
+}
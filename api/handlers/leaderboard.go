@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+const (
+	// leaderboardCacheTTL is how long a computed leaderboard is served from cache
+	leaderboardCacheTTL = 30 * time.Second
+	// leaderboardCacheSize caps the cached leaderboards, which are keyed by query parameters
+	leaderboardCacheSize = 256
+)
+
+// LeaderboardEntry is a ranked runtime/quant variant for a model
+type LeaderboardEntry struct {
+	Rank      int      `json:"rank"`
+	Runtime   string   `json:"runtime"`
+	Quant     string   `json:"quant,omitempty"`
+	Value     float64  `json:"value"`
+	BestRunID string   `json:"best_run_id"`
+	RunIDs    []string `json:"run_ids"`
+}
+
+// LeaderboardResponse is the response for the leaderboard endpoint
+type LeaderboardResponse struct {
+	Model       string             `json:"model"`
+	Workload    string             `json:"workload,omitempty"`
+	Metric      string             `json:"metric"`
+	Entries     []LeaderboardEntry `json:"entries"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
+
+type leaderboardCacheEntry struct {
+	resp      LeaderboardResponse
+	expiresAt time.Time
+}
+
+// leaderboardCache holds computed leaderboards until they expire, bounded to maxEntries
+type leaderboardCache struct {
+	mu         sync.Mutex
+	entries    map[string]leaderboardCacheEntry
+	maxEntries int
+}
+
+func newLeaderboardCache(maxEntries int) *leaderboardCache {
+	return &leaderboardCache{entries: make(map[string]leaderboardCacheEntry), maxEntries: maxEntries}
+}
+
+// get returns the unexpired leaderboard cached under key
+func (c *leaderboardCache) get(key string, now time.Time) (LeaderboardResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, found := c.entries[key]
+	if !found || !now.Before(cached.expiresAt) {
+		return LeaderboardResponse{}, false
+	}
+	return cached.resp, true
+}
+
+// put caches a leaderboard under key. Expired entries are swept first, and if the cache is
+// still full the entry closest to expiring is evicted.
+func (c *leaderboardCache) put(key string, resp LeaderboardResponse, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.entries[key]; !found && len(c.entries) >= c.maxEntries {
+		oldest := ""
+		for k, cached := range c.entries {
+			if !now.Before(cached.expiresAt) {
+				delete(c.entries, k)
+			} else if oldest == "" || cached.expiresAt.Before(c.entries[oldest].expiresAt) {
+				oldest = k
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			delete(c.entries, oldest)
+		}
+	}
+	c.entries[key] = leaderboardCacheEntry{resp: resp, expiresAt: now.Add(leaderboardCacheTTL)}
+}
+
+// LeaderboardHandler ranks runtimes for a model by a metric across completed runs
+func LeaderboardHandler(dbClient *db.Client) http.HandlerFunc {
+	cache := newLeaderboardCache(leaderboardCacheSize)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if dbClient == nil {
+			http.Error(w, "Database not available", http.StatusServiceUnavailable)
+			return
+		}
+
+		model := r.URL.Query().Get("model")
+		workload := r.URL.Query().Get("workload")
+		metric := r.URL.Query().Get("metric")
+		if metric == "" {
+			metric = "tokens_per_second"
+		}
+
+		if model == "" {
+			http.Error(w, "model is required", http.StatusBadRequest)
+			return
+		}
+		higherIsBetter, ok := db.ResultMetrics[metric]
+		if !ok {
+			http.Error(w, "unsupported metric: "+metric, http.StatusBadRequest)
+			return
+		}
+
+		key := model + "\x00" + workload + "\x00" + metric
+		if cached, found := cache.get(key, time.Now()); found {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(cached)
+			return
+		}
+
+		results, err := dbClient.ListCompletedResults(r.Context(), model, workload, metric)
+		if err != nil {
+			http.Error(w, "failed to query run results: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp := LeaderboardResponse{
+			Model:       model,
+			Workload:    workload,
+			Metric:      metric,
+			Entries:     rankResults(results, higherIsBetter),
+			GeneratedAt: time.Now(),
+		}
+
+		cache.put(key, resp, time.Now())
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// rankResults keeps the best value per runtime/quant variant and ranks the variants.
+// Variants with equal values share a rank; NaN values are treated as missing data.
+func rankResults(results []db.RunResult, higherIsBetter bool) []LeaderboardEntry {
+	better := func(a, b float64) bool {
+		if higherIsBetter {
+			return a > b
+		}
+		return a < b
+	}
+
+	byVariant := make(map[string]*LeaderboardEntry)
+	var order []string
+	for _, result := range results {
+		if math.IsNaN(result.Value) {
+			continue
+		}
+		key := result.Runtime + "\x00" + result.Quant
+		entry, found := byVariant[key]
+		if !found {
+			entry = &LeaderboardEntry{
+				Runtime:   result.Runtime,
+				Quant:     result.Quant,
+				Value:     result.Value,
+				BestRunID: result.RunID,
+			}
+			byVariant[key] = entry
+			order = append(order, key)
+		} else if better(result.Value, entry.Value) {
+			entry.Value = result.Value
+			entry.BestRunID = result.RunID
+		}
+		if !containsString(entry.RunIDs, result.RunID) {
+			entry.RunIDs = append(entry.RunIDs, result.RunID)
+		}
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(order))
+	for _, key := range order {
+		entry := byVariant[key]
+		sort.Strings(entry.RunIDs)
+		entries = append(entries, *entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return better(entries[i].Value, entries[j].Value)
+		}
+		if entries[i].Runtime != entries[j].Runtime {
+			return entries[i].Runtime < entries[j].Runtime
+		}
+		return entries[i].Quant < entries[j].Quant
+	})
+
+	for i := range entries {
+		if i > 0 && entries[i].Value == entries[i-1].Value {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+func TestRankResultsTiesAndMissingData(t *testing.T) {
+	results := []db.RunResult{
+		{RunID: "run_000001", Runtime: "vllm", Value: 40},
+		{RunID: "run_000002", Runtime: "vllm", Value: 45},
+		{RunID: "run_000001", Runtime: "transformers", Value: 30},
+		{RunID: "run_000003", Runtime: "tgi", Value: 45},
+		{RunID: "run_000003", Runtime: "llamacpp", Value: math.NaN()},
+	}
+
+	entries := rankResults(results, true)
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 ranked entries, got %d: %+v", len(entries), entries)
+	}
+
+	// tgi and vllm tie at 45 and share rank 1, transformers follows at rank 3
+	if entries[0].Runtime != "tgi" || entries[0].Rank != 1 {
+		t.Errorf("Expected tgi at rank 1, got %+v", entries[0])
+	}
+	if entries[1].Runtime != "vllm" || entries[1].Rank != 1 || entries[1].BestRunID != "run_000002" {
+		t.Errorf("Expected vllm at rank 1 from run_000002, got %+v", entries[1])
+	}
+	if len(entries[1].RunIDs) != 2 {
+		t.Errorf("Expected vllm to list both source runs, got %v", entries[1].RunIDs)
+	}
+	if entries[2].Runtime != "transformers" || entries[2].Rank != 3 {
+		t.Errorf("Expected transformers at rank 3, got %+v", entries[2])
+	}
+}
+
+func TestRankResultsLowerIsBetter(t *testing.T) {
+	results := []db.RunResult{
+		{RunID: "run_000001", Runtime: "vllm", Value: 250},
+		{RunID: "run_000001", Runtime: "transformers", Value: 350},
+	}
+
+	entries := rankResults(results, false)
+	if entries[0].Runtime != "vllm" {
+		t.Errorf("Expected lowest latency first, got %+v", entries)
+	}
+}
+
+func TestLeaderboardCacheIsBounded(t *testing.T) {
+	cache := newLeaderboardCache(3)
+	now := time.Now()
+
+	cache.put("expired", LeaderboardResponse{}, now.Add(-time.Minute))
+	for i := 0; i < 5; i++ {
+		cache.put(fmt.Sprintf("query-%d", i), LeaderboardResponse{Model: fmt.Sprint(i)}, now.Add(time.Duration(i)*time.Second))
+	}
+
+	if len(cache.entries) != 3 {
+		t.Errorf("Expected the cache to hold at most 3 entries, got %d", len(cache.entries))
+	}
+	if _, found := cache.entries["expired"]; found {
+		t.Errorf("Expected the expired entry to be swept")
+	}
+	if _, found := cache.get("query-0", now); found {
+		t.Errorf("Expected the entry closest to expiring to be evicted")
+	}
+	if resp, found := cache.get("query-4", now); !found || resp.Model != "4" {
+		t.Errorf("Expected the newest entry to be cached, got %+v", resp)
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// defaultMaxModelLabels caps the distinct model label values exported to Prometheus
+const defaultMaxModelLabels = 50
+
+// maxModelLabelLen bounds the length of a single model label value
+const maxModelLabelLen = 64
+
+// overflowModelLabel is the model label used once the cap on distinct values is reached
+const overflowModelLabel = "other"
+
+var inferenceLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "tokenforge_inference_latency_seconds",
+	Help:    "Latency of inference requests proxied to workers.",
+	Buckets: prometheus.ExponentialBuckets(0.05, 2, 10),
+}, []string{"model", "runtime", "status"})
+
+var inferenceInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "tokenforge_inference_in_flight",
+	Help: "Inference requests currently being proxied to workers.",
+}, []string{"model", "runtime"})
+
+// modelLabeler maps model names to a bounded set of label-safe values
+type modelLabeler struct {
+	mu    sync.Mutex
+	limit int
+	seen  map[string]bool
+}
+
+var defaultModelLabeler = &modelLabeler{
+	limit: envInt("METRICS_MAX_MODEL_LABELS", defaultMaxModelLabels),
+	seen:  make(map[string]bool),
+}
+
+// label returns the metric label for a model. Names are lowercased, every character outside
+// [a-z0-9_.-] becomes "_" (so "meta-llama/Llama-3-8b" becomes "meta-llama_llama-3-8b"), and
+// the result is truncated to 64 characters. Once the cap on distinct values is reached, new
+// models are reported as "other".
+func (l *modelLabeler) label(model string) string {
+	value := sanitizeLabelValue(model)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.seen[value] {
+		return value
+	}
+	if l.limit > 0 && len(l.seen) >= l.limit {
+		return overflowModelLabel
+	}
+	l.seen[value] = true
+	return value
+}
+
+// sanitizeLabelValue applies the model label character mapping and length bound
+func sanitizeLabelValue(name string) string {
+	mapped := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, strings.ToLower(name))
+	if len(mapped) > maxModelLabelLen {
+		mapped = mapped[:maxModelLabelLen]
+	}
+	if mapped == "" {
+		return "unknown"
+	}
+	return mapped
+}
+
+// modelLabel returns the bounded metric label for a model name
+func modelLabel(model string) string {
+	return defaultModelLabeler.label(model)
+}
+
+// observeInference records the latency of a proxied inference request
+func observeInference(model, runtime string, statusCode int, latency time.Duration) {
+	inferenceLatency.WithLabelValues(modelLabel(model), runtime, strconv.Itoa(statusCode)).Observe(latency.Seconds())
+	instanceMetrics.record(time.Now(), latency, statusCode)
+}
+
+// trackInFlight increments the in-flight gauge and returns the matching decrement. Callers
+// must defer the returned function so early returns and panics do not leak the gauge.
+func trackInFlight(model, runtime string) func() {
+	gauge := inferenceInFlight.WithLabelValues(modelLabel(model), runtime)
+	gauge.Inc()
+	instanceMetrics.inFlight.Add(1)
+	return func() {
+		gauge.Dec()
+		instanceMetrics.inFlight.Add(-1)
+	}
+}
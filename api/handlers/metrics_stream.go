@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+const (
+	// defaultMetricsStreamInterval is how often a snapshot is pushed when METRICS_STREAM_INTERVAL is unset
+	defaultMetricsStreamInterval = 2 * time.Second
+	// minMetricsStreamInterval bounds the interval a client may request
+	minMetricsStreamInterval = 500 * time.Millisecond
+	// defaultMetricsWindow is the rolling window for QPS and latency when METRICS_STREAM_WINDOW is unset
+	defaultMetricsWindow = time.Minute
+	// maxMetricsSamples caps the latencies kept for the rolling window
+	maxMetricsSamples = 50000
+)
+
+// Sources a metrics snapshot's request counts and latency can come from
+const (
+	// metricsSourceInferences covers every inference recorded by any API replica
+	metricsSourceInferences = "inferences"
+	// metricsSourceInstance covers only the inferences proxied by this API instance since it started
+	metricsSourceInstance = "instance"
+)
+
+// MetricsSnapshot is one update of the live metrics stream. Errors count responses of 400 and
+// above, and P95LatencyMs covers successful requests only. InFlight is always this instance's.
+type MetricsSnapshot struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Source       string    `json:"source"`
+	WindowS      float64   `json:"window_s"`
+	Requests     int       `json:"requests"`
+	Errors       int       `json:"errors"`
+	QPS          float64   `json:"qps"`
+	InFlight     int64     `json:"in_flight"`
+	P95LatencyMs float64   `json:"p95_latency_ms"`
+}
+
+// metricsSample is one completed inference in the rolling window
+type metricsSample struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+// instanceMetricsAggregator keeps a rolling window of the inferences completed by this API
+// instance across all deployments. It feeds the live metrics stream when no database is
+// connected, and always supplies the in-flight count.
+type instanceMetricsAggregator struct {
+	window   time.Duration
+	inFlight atomic.Int64
+
+	mu      sync.Mutex
+	samples []metricsSample
+}
+
+var instanceMetrics = &instanceMetricsAggregator{
+	window: envDuration("METRICS_STREAM_WINDOW", defaultMetricsWindow),
+}
+
+// record adds a completed inference to the window
+func (a *instanceMetricsAggregator) record(at time.Time, latency time.Duration, statusCode int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.prune(at)
+	if len(a.samples) >= maxMetricsSamples {
+		a.samples = a.samples[1:]
+	}
+	a.samples = append(a.samples, metricsSample{at: at, latency: latency, failed: statusCode >= 400})
+}
+
+// prune drops samples older than the window; the caller must hold the lock
+func (a *instanceMetricsAggregator) prune(now time.Time) {
+	cutoff := now.Add(-a.window)
+	i := 0
+	for i < len(a.samples) && a.samples[i].at.Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		a.samples = append(a.samples[:0], a.samples[i:]...)
+	}
+}
+
+// snapshot summarizes the window ending at now
+func (a *instanceMetricsAggregator) snapshot(now time.Time) MetricsSnapshot {
+	a.mu.Lock()
+	a.prune(now)
+	requests := len(a.samples)
+	latencies := make([]float64, 0, requests)
+	errors := 0
+	for _, sample := range a.samples {
+		if sample.failed {
+			errors++
+			continue
+		}
+		latencies = append(latencies, float64(sample.latency)/float64(time.Millisecond))
+	}
+	a.mu.Unlock()
+
+	snapshot := MetricsSnapshot{
+		Timestamp: now.UTC(),
+		Source:    metricsSourceInstance,
+		WindowS:   a.window.Seconds(),
+		Requests:  requests,
+		Errors:    errors,
+		QPS:       float64(requests) / a.window.Seconds(),
+		InFlight:  a.inFlight.Load(),
+	}
+	if len(latencies) > 0 {
+		sort.Float64s(latencies)
+		snapshot.P95LatencyMs = latencies[(len(latencies)*95+99)/100-1]
+	}
+	return snapshot
+}
+
+// metricsSnapshot summarizes the inferences recorded in the database over the window, so every
+// API replica streams the same numbers. Without a database, or when the query fails, it falls
+// back to this instance's own window.
+func metricsSnapshot(ctx context.Context, dbClient *db.Client, now time.Time) MetricsSnapshot {
+	if dbClient == nil {
+		return instanceMetrics.snapshot(now)
+	}
+	stats, err := dbClient.GetInferenceStats(ctx, "", "", instanceMetrics.window)
+	if err != nil {
+		log.Printf("Failed to compute streamed metrics, using this instance's: %v", err)
+		return instanceMetrics.snapshot(now)
+	}
+	return MetricsSnapshot{
+		Timestamp:    now.UTC(),
+		Source:       metricsSourceInferences,
+		WindowS:      instanceMetrics.window.Seconds(),
+		Requests:     stats.Requests,
+		Errors:       stats.Errors,
+		QPS:          float64(stats.Requests) / instanceMetrics.window.Seconds(),
+		InFlight:     instanceMetrics.inFlight.Load(),
+		P95LatencyMs: stats.P95LatencyMs,
+	}
+}
+
+// metricsStreamInterval returns the push interval from the interval query parameter,
+// falling back to METRICS_STREAM_INTERVAL
+func metricsStreamInterval(r *http.Request) (time.Duration, error) {
+	interval := envDuration("METRICS_STREAM_INTERVAL", defaultMetricsStreamInterval)
+	if v := r.URL.Query().Get("interval"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q", v)
+		}
+		interval = parsed
+	}
+	if interval < minMetricsStreamInterval {
+		return 0, fmt.Errorf("interval must be at least %s", minMetricsStreamInterval)
+	}
+	return interval, nil
+}
+
+// MetricsStreamHandler streams aggregate inference metrics as server-sent events: a
+// snapshot is sent on connect and then every interval until the client disconnects
+func MetricsStreamHandler(dbClient *db.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		interval, err := metricsStreamInterval(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			data, err := json.Marshal(metricsSnapshot(r.Context(), dbClient, time.Now()))
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "event: metrics\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-streams.stopping:
+				writeShutdownEvent(w)
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
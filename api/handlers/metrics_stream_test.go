@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLiveMetricsSnapshot(t *testing.T) {
+	a := &instanceMetricsAggregator{window: 10 * time.Second}
+	now := time.Now()
+
+	a.record(now.Add(-time.Minute), 5*time.Second, 200)
+	for i := 1; i <= 20; i++ {
+		status := 200
+		if i == 20 {
+			status = 503
+		}
+		a.record(now.Add(-time.Second), time.Duration(i)*100*time.Millisecond, status)
+	}
+	a.inFlight.Add(3)
+
+	snapshot := a.snapshot(now)
+	if snapshot.Requests != 20 || snapshot.Errors != 1 {
+		t.Errorf("Expected 20 requests and 1 error in the window, got %d and %d", snapshot.Requests, snapshot.Errors)
+	}
+	if snapshot.QPS != 2 {
+		t.Errorf("Expected 2 QPS over a 10s window, got %v", snapshot.QPS)
+	}
+	if snapshot.P95LatencyMs != 1900 {
+		t.Errorf("Expected p95 of 1900ms, got %v", snapshot.P95LatencyMs)
+	}
+	if snapshot.Source != metricsSourceInstance {
+		t.Errorf("Expected the snapshot to be marked as this instance's, got %q", snapshot.Source)
+	}
+	if snapshot.InFlight != 3 {
+		t.Errorf("Expected 3 in-flight requests, got %d", snapshot.InFlight)
+	}
+
+	empty := (&instanceMetricsAggregator{window: time.Second}).snapshot(now)
+	if empty.Requests != 0 || empty.P95LatencyMs != 0 {
+		t.Errorf("Expected an empty snapshot, got %+v", empty)
+	}
+}
+
+func TestMetricsStreamPushesSnapshots(t *testing.T) {
+	server := httptest.NewServer(MetricsStreamHandler(nil))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?interval=500ms")
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", ct)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	snapshots := 0
+	for snapshots < 2 && scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var snapshot MetricsSnapshot
+		if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+			t.Fatalf("Failed to decode snapshot: %v", err)
+		}
+		snapshots++
+	}
+	if snapshots != 2 {
+		t.Errorf("Expected 2 snapshots, got %d", snapshots)
+	}
+}
+
+func TestMetricsStreamRejectsShortInterval(t *testing.T) {
+	rr := httptest.NewRecorder()
+	MetricsStreamHandler(nil).ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/metrics/stream?interval=1ms", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a too-short interval, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+func TestSanitizeLabelValue(t *testing.T) {
+	tests := map[string]string{
+		"meta-llama/Llama-3-8b-instruct": "meta-llama_llama-3-8b-instruct",
+		"Qwen/Qwen2.5-7B":                "qwen_qwen2.5-7b",
+		"model with spaces!":             "model_with_spaces_",
+		"":                               "unknown",
+	}
+	for name, want := range tests {
+		if got := sanitizeLabelValue(name); got != want {
+			t.Errorf("sanitizeLabelValue(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	if got := sanitizeLabelValue(strings.Repeat("a", 100)); len(got) != maxModelLabelLen {
+		t.Errorf("Expected label truncated to %d characters, got %d", maxModelLabelLen, len(got))
+	}
+}
+
+func TestModelLabelerBucketsOverflow(t *testing.T) {
+	labeler := &modelLabeler{limit: 2, seen: make(map[string]bool)}
+
+	if got := labeler.label("org/a"); got != "org_a" {
+		t.Errorf("Expected org_a, got %s", got)
+	}
+	labeler.label("org/b")
+	if got := labeler.label("org/c"); got != overflowModelLabel {
+		t.Errorf("Expected model over the cap to map to %s, got %s", overflowModelLabel, got)
+	}
+	if got := labeler.label("org/a"); got != "org_a" {
+		t.Errorf("Expected already-seen model to keep its label, got %s", got)
+	}
+}
+
+// nonFlushingWriter is a ResponseWriter without http.Flusher, so streaming to it panics
+type nonFlushingWriter struct {
+	header http.Header
+	code   int
+}
+
+func (w *nonFlushingWriter) Header() http.Header         { return w.header }
+func (w *nonFlushingWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *nonFlushingWriter) WriteHeader(code int)        { w.code = code }
+
+func TestInFlightGaugeReturnsToZeroAfterPanic(t *testing.T) {
+	var gotMaxTokens int
+	worker := newTestWorker(t, &gotMaxTokens)
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "panic-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+
+	handler := middleware.Recoverer(InferHandler(registry, nil, writeTestModelsConfig(t)))
+
+	// Simulated streaming flushes the writer, which panics mid-request without http.Flusher
+	body := []byte(`{"model":"panic-model","runtime":"minimal","prompt":"hello","stream":true}`)
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader(body))
+	w := &nonFlushingWriter{header: make(http.Header)}
+	handler.ServeHTTP(w, req)
+
+	if gotMaxTokens == 0 {
+		t.Fatalf("Expected the request to reach the worker before panicking")
+	}
+	gauge := inferenceInFlight.WithLabelValues(modelLabel("panic-model"), "minimal")
+	if got := testutil.ToFloat64(gauge); got != 0 {
+		t.Errorf("Expected in-flight gauge to return to 0 after panic, got %v", got)
+	}
+}
@@ -2,35 +2,102 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 
+	"github.com/tokenforge/llm-infra-bench/controlplane"
 	"gopkg.in/yaml.v3"
 )
 
 type ModelsConfig struct {
-	Models []struct {
-		Name  string `json:"name" yaml:"name"`
-		Quant string `json:"quant" yaml:"quant"`
-		Hash  string `json:"hash" yaml:"hash"`
-	} `json:"models" yaml:"models"`
+	Models []ModelEntry `json:"models" yaml:"models"`
+}
+
+// ModelEntry is a single model from models.yaml
+type ModelEntry struct {
+	Name  string `json:"name" yaml:"name"`
+	Quant string `json:"quant" yaml:"quant"`
+	Hash  string `json:"hash" yaml:"hash"`
+	// DefaultMaxTokens is applied when a request omits max_tokens
+	DefaultMaxTokens int `json:"default_max_tokens,omitempty" yaml:"default_max_tokens"`
+	// MaxTokensCap rejects requests asking for more tokens than this
+	MaxTokensCap int `json:"max_tokens_cap,omitempty" yaml:"max_tokens_cap"`
+	// MaxContext is the context window used when the worker does not report one
+	MaxContext int `json:"max_context,omitempty" yaml:"max_context"`
+	// Aliases are alternative names accepted in requests for this model
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases"`
+	// DefaultQuant is deployed when a request omits quant; falls back to Quant
+	DefaultQuant string `json:"default_quant,omitempty" yaml:"default_quant"`
+	// DefaultRuntime serves inference requests that do not name a runtime
+	DefaultRuntime string `json:"default_runtime,omitempty" yaml:"default_runtime"`
+}
+
+// loadModelsConfig reads and parses models.yaml from the config directory
+func loadModelsConfig(configPath string) (*ModelsConfig, error) {
+	data, err := os.ReadFile(filepath.Join(configPath, "models.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read models config: %w", err)
+	}
+
+	var config ModelsConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse models config: %w", err)
+	}
+	if len(config.Models) == 0 {
+		return nil, fmt.Errorf("no models defined in config")
+	}
+
+	return &config, nil
+}
+
+// findModel returns the configured model with the given name or alias
+func (c *ModelsConfig) findModel(name string) (*ModelEntry, bool) {
+	for i := range c.Models {
+		if c.Models[i].Name == name || containsString(c.Models[i].Aliases, name) {
+			return &c.Models[i], true
+		}
+	}
+	return nil, false
+}
+
+// canonicalModelName resolves a model name or alias to the canonical name in models.yaml.
+// Names that are not configured, or a missing config, are returned unchanged.
+func canonicalModelName(configPath, name string) string {
+	config, err := loadModelsConfig(configPath)
+	if err != nil {
+		return name
+	}
+	if model, found := config.findModel(name); found {
+		return model.Name
+	}
+	return name
+}
+
+// defaultQuant returns the quant to deploy for a model when a request omits one,
+// or an empty string if the model is unknown or has no quant configured
+func defaultQuant(configPath, name string) string {
+	config, err := loadModelsConfig(configPath)
+	if err != nil {
+		return ""
+	}
+	model, found := config.findModel(name)
+	if !found {
+		return ""
+	}
+	if model.DefaultQuant != "" {
+		return model.DefaultQuant
+	}
+	return model.Quant
 }
 
 // ModelsHandler returns the configured models from YAML
 func ModelsHandler(configPath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Read models config file
-		data, err := os.ReadFile(filepath.Join(configPath, "models.yaml"))
+		config, err := loadModelsConfig(configPath)
 		if err != nil {
-			http.Error(w, "failed to read models config: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Parse YAML
-		var config ModelsConfig
-		if err := yaml.Unmarshal(data, &config); err != nil {
-			http.Error(w, "failed to parse models config: "+err.Error(), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
@@ -40,3 +107,82 @@ func ModelsHandler(configPath string) http.HandlerFunc {
 		json.NewEncoder(w).Encode(config)
 	}
 }
+
+// ModelRuntimeStatus is one runtime a model is currently deployed with
+type ModelRuntimeStatus struct {
+	Runtime string `json:"runtime"`
+	Quant   string `json:"quant"`
+	Status  string `json:"status"`
+	Paused  bool   `json:"paused"`
+}
+
+// ModelStatus joins a configured model with its current deployments
+type ModelStatus struct {
+	Name     string               `json:"name"`
+	Aliases  []string             `json:"aliases,omitempty"`
+	Deployed bool                 `json:"deployed"`
+	Status   string               `json:"status"`
+	Runtimes []ModelRuntimeStatus `json:"runtimes"`
+}
+
+// modelStatusPriority orders deployment statuses when summarizing a model's runtimes
+var modelStatusPriority = []string{"ready", "restarting", "deploying", "no_endpoints", "failed"}
+
+// summarizeModelStatus reports the most useful status across a model's runtimes: ready if
+// any runtime can serve traffic, otherwise the most hopeful of the remaining statuses
+func summarizeModelStatus(runtimes []ModelRuntimeStatus) string {
+	if len(runtimes) == 0 {
+		return "not_deployed"
+	}
+	for _, status := range modelStatusPriority {
+		for _, rt := range runtimes {
+			if rt.Status == status && !(status == "ready" && rt.Paused) {
+				return status
+			}
+		}
+	}
+	if runtimes[0].Paused {
+		return "paused"
+	}
+	return runtimes[0].Status
+}
+
+// ModelsStatusHandler lists every configured model with whether and how it is deployed
+func ModelsStatusHandler(registry *controlplane.Registry, configPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		config, err := loadModelsConfig(configPath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// Group the registry snapshot by model once rather than scanning it per model
+		deployed := make(map[string][]ModelRuntimeStatus)
+		for _, entry := range registry.GetAll() {
+			deployed[entry.Model] = append(deployed[entry.Model], ModelRuntimeStatus{
+				Runtime: entry.Runtime,
+				Quant:   entry.Quant,
+				Status:  entry.Status,
+				Paused:  entry.Paused,
+			})
+		}
+
+		models := make([]ModelStatus, 0, len(config.Models))
+		for _, model := range config.Models {
+			runtimes := deployed[model.Name]
+			if runtimes == nil {
+				runtimes = []ModelRuntimeStatus{}
+			}
+			models = append(models, ModelStatus{
+				Name:     model.Name,
+				Aliases:  model.Aliases,
+				Deployed: len(runtimes) > 0,
+				Status:   summarizeModelStatus(runtimes),
+				Runtimes: runtimes,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"models": models})
+	}
+}
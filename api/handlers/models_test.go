@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane"
 )
 
 func TestModelsHandler(t *testing.T) {
@@ -60,3 +63,63 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestModelsHandlerRejectsEmptyConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "models.yaml"), []byte(""), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/models", nil)
+	rr := httptest.NewRecorder()
+	ModelsHandler(tempDir).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusInternalServerError)
+	}
+	if !contains(rr.Body.String(), "no models defined in config") {
+		t.Errorf("Expected clear error message, got %q", rr.Body.String())
+	}
+}
+
+func TestModelsStatusHandler(t *testing.T) {
+	tempDir := t.TempDir()
+	testConfig := `models:
+  - name: deployed-model
+    quant: fp16
+  - name: idle-model
+    quant: fp16
+`
+	if err := os.WriteFile(filepath.Join(tempDir, "models.yaml"), []byte(testConfig), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "deployed-model", Runtime: "vllm", Quant: "fp16", Status: "deploying"})
+	registry.Set(controlplane.Entry{Model: "deployed-model", Runtime: "transformers", Quant: "fp16", Status: "ready"})
+	registry.Set(controlplane.Entry{Model: "unconfigured-model", Runtime: "minimal", Status: "ready"})
+
+	rr := httptest.NewRecorder()
+	ModelsStatusHandler(registry, tempDir).ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/models/status", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	var resp struct {
+		Models []ModelStatus `json:"models"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Models) != 2 {
+		t.Fatalf("Expected only the 2 configured models, got %+v", resp.Models)
+	}
+
+	deployed, idle := resp.Models[0], resp.Models[1]
+	if !deployed.Deployed || deployed.Status != "ready" || len(deployed.Runtimes) != 2 {
+		t.Errorf("Expected deployed-model ready on 2 runtimes, got %+v", deployed)
+	}
+	if idle.Deployed || idle.Status != "not_deployed" || idle.Runtimes == nil || len(idle.Runtimes) != 0 {
+		t.Errorf("Expected idle-model to be not_deployed with no runtimes, got %+v", idle)
+	}
+}
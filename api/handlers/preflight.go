@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+	"github.com/tokenforge/llm-infra-bench/controlplane/k8s"
+)
+
+// PreflightResponse reports whether a deploy request would succeed and why not
+type PreflightResponse struct {
+	Model   string               `json:"model"`
+	Runtime string               `json:"runtime"`
+	Quant   string               `json:"quant"`
+	Passed  bool                 `json:"passed"`
+	Checks  []k8s.PreflightCheck `json:"checks"`
+}
+
+// DeployPreflightHandler runs the deploy validations for a request without deploying anything
+func DeployPreflightHandler(registry *controlplane.Registry, configPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req DeployRequest
+		if err := decodeJSON(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Model == "" || req.Runtime == "" {
+			http.Error(w, "model and runtime are required", http.StatusBadRequest)
+			return
+		}
+		req.Model = canonicalModelName(configPath, req.Model)
+		if req.Quant == "" {
+			req.Quant = defaultQuant(configPath, req.Model)
+		}
+
+		resp := PreflightResponse{
+			Model:   req.Model,
+			Runtime: req.Runtime,
+			Quant:   req.Quant,
+		}
+
+		limitCheck := k8s.PreflightCheck{Name: "deployment_limits", Passed: true, Message: "within deployment limits"}
+		if err := registry.CheckLimits(req.Model, req.Runtime, envInt("MAX_DEPLOYMENTS_PER_MODEL", 0), envInt("MAX_DEPLOYMENTS", 0)); err != nil {
+			limitCheck.Passed = false
+			limitCheck.Message = err.Error()
+		}
+		resp.Checks = append(resp.Checks, limitCheck)
+
+		// The minimal runtime runs locally and has no cluster requirements
+		if req.Runtime != "minimal" {
+			resp.Checks = append(resp.Checks, k8s.Preflight(r.Context(), req.Model, req.Runtime, req.Quant, k8s.DeployOptions{GPU: req.GPU, Profile: req.Profile, CPU: req.CPU, Mem: req.Mem})...)
+		}
+
+		resp.Passed = true
+		for _, check := range resp.Checks {
+			if !check.Passed {
+				resp.Passed = false
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
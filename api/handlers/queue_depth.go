@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+// defaultQueueDepthScrapeInterval is how often worker queue depths are scraped when
+// QUEUE_DEPTH_SCRAPE_INTERVAL is unset
+const defaultQueueDepthScrapeInterval = 15 * time.Second
+
+// queueDepthScrapeTimeout bounds how long a single worker scrape may take
+const queueDepthScrapeTimeout = 5 * time.Second
+
+// unknownQueueDepth is reported for workers that do not export a queue depth metric
+const unknownQueueDepth = "unknown"
+
+// queueDepthMetrics are the worker metric names read as queue depth, in order of preference.
+// TokenForge workers export tokenforge_worker_queue_depth; vLLM's native server exports
+// vllm:num_requests_waiting.
+var queueDepthMetrics = []string{"tokenforge_worker_queue_depth", "vllm:num_requests_waiting"}
+
+var workerQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "tokenforge_worker_queue_depth",
+	Help: "Requests waiting on a worker, as last scraped from its /metrics endpoint.",
+}, []string{"deployment", "model", "runtime"})
+
+// parseQueueDepth reads the queue depth from a Prometheus text exposition, summing every
+// series of the first matching metric. It reports false when no queue depth metric is present.
+func parseQueueDepth(r io.Reader) (int, bool) {
+	values := make(map[string]float64)
+	found := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := parseSampleLine(line)
+		if !ok {
+			continue
+		}
+		for _, metric := range queueDepthMetrics {
+			if name == metric {
+				values[metric] += value
+				found[metric] = true
+			}
+		}
+	}
+
+	for _, metric := range queueDepthMetrics {
+		if found[metric] {
+			return int(values[metric]), true
+		}
+	}
+	return 0, false
+}
+
+// parseSampleLine splits a sample line into its metric name and value, ignoring labels and
+// timestamps
+func parseSampleLine(line string) (string, float64, bool) {
+	var name, rest string
+	if i := strings.IndexByte(line, '{'); i >= 0 {
+		end := strings.LastIndexByte(line, '}')
+		if end < i {
+			return "", 0, false
+		}
+		name, rest = line[:i], line[end+1:]
+	} else {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return "", 0, false
+		}
+		name, rest = fields[0], strings.Join(fields[1:], " ")
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", 0, false
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return name, value, true
+}
+
+// fetchQueueDepth scrapes a worker's /metrics endpoint. A worker that answers without a
+// queue depth metric, or with a non-Prometheus body, reports an unknown depth rather than
+// an error.
+func fetchQueueDepth(ctx context.Context, baseURL string) (int, bool, error) {
+	client, err := getWorkerClient()
+	if err != nil {
+		return 0, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queueDepthScrapeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/metrics", nil)
+	if err != nil {
+		return 0, false, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to scrape worker metrics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, false, nil
+	}
+	depth, known := parseQueueDepth(resp.Body)
+	return depth, known, nil
+}
+
+// queueDepthLabel returns the deployment label for an entry, falling back to the model and
+// runtime pair for workers without a Kubernetes deployment
+func queueDepthLabel(entry controlplane.Entry) string {
+	if entry.Deployment != "" {
+		return entry.Deployment
+	}
+	return sanitizeLabelValue(entry.Model) + "-" + entry.Runtime
+}
+
+// scrapeQueueDepths updates the queue depth of every ready deployment in the registry. It
+// returns the gauge series it set so the caller can drop series for removed deployments.
+func scrapeQueueDepths(ctx context.Context, registry *controlplane.Registry) map[string]prometheus.Labels {
+	exported := make(map[string]prometheus.Labels)
+	for _, entry := range registry.GetAll() {
+		labels := prometheus.Labels{
+			"deployment": queueDepthLabel(entry),
+			"model":      modelLabel(entry.Model),
+			"runtime":    entry.Runtime,
+		}
+		if entry.Status != "ready" || entry.ServiceURL == "" {
+			workerQueueDepth.Delete(labels)
+			continue
+		}
+
+		depth, known, err := fetchQueueDepth(ctx, entry.ServiceURL)
+		if err != nil {
+			log.Printf("Failed to scrape queue depth for %s/%s: %v", entry.Model, entry.Runtime, err)
+		}
+		registry.SetQueueDepth(entry.Model, entry.Runtime, depth, known)
+		if known {
+			workerQueueDepth.With(labels).Set(float64(depth))
+			exported[labels["deployment"]+"\x00"+labels["model"]+"\x00"+labels["runtime"]] = labels
+		} else {
+			workerQueueDepth.Delete(labels)
+		}
+	}
+	return exported
+}
+
+// StartQueueDepthScraper periodically scrapes each worker's queue depth into the registry
+// and the tokenforge_worker_queue_depth gauge, every QUEUE_DEPTH_SCRAPE_INTERVAL
+func StartQueueDepthScraper(ctx context.Context, registry *controlplane.Registry) {
+	interval := envDuration("QUEUE_DEPTH_SCRAPE_INTERVAL", defaultQueueDepthScrapeInterval)
+
+	backgroundLoops.register("queue_depth_scraper", interval)
+	var previous map[string]prometheus.Labels
+	scrape := func() {
+		defer backgroundLoops.ran("queue_depth_scraper")
+		current := scrapeQueueDepths(ctx, registry)
+		for key, labels := range previous {
+			if _, ok := current[key]; !ok {
+				workerQueueDepth.Delete(labels)
+			}
+		}
+		previous = current
+	}
+
+	go func() {
+		scrape()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				scrape()
+			}
+		}
+	}()
+}
+
+// queueDepthValue returns an entry's queue depth for API responses, or "unknown"
+func queueDepthValue(entry controlplane.Entry) interface{} {
+	if !entry.QueueDepthKnown {
+		return unknownQueueDepth
+	}
+	return entry.QueueDepth
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+func TestParseQueueDepth(t *testing.T) {
+	exposition := `# HELP tokenforge_worker_queue_depth Inference requests queued or in progress
+# TYPE tokenforge_worker_queue_depth gauge
+tokenforge_worker_queue_depth{engine="vllm"} 3.0
+tokenforge_worker_queue_depth{engine="other"} 2
+vllm:num_requests_waiting{model_name="x"} 9
+`
+	depth, known := parseQueueDepth(strings.NewReader(exposition))
+	if !known || depth != 5 {
+		t.Errorf("Expected queue depth 5, got %d (known %v)", depth, known)
+	}
+
+	depth, known = parseQueueDepth(strings.NewReader("vllm:num_requests_waiting{model_name=\"x\"} 4 1700000000\n"))
+	if !known || depth != 4 {
+		t.Errorf("Expected vLLM queue depth 4, got %d (known %v)", depth, known)
+	}
+
+	if _, known := parseQueueDepth(strings.NewReader(`{"message": "Metrics available at :8001/metrics"}`)); known {
+		t.Error("Expected a non-Prometheus body to report an unknown queue depth")
+	}
+}
+
+func TestScrapeQueueDepthsReportsUnknown(t *testing.T) {
+	withDepth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tokenforge_worker_queue_depth{engine=\"vllm\"} 7\n"))
+	}))
+	defer withDepth.Close()
+	withoutDepth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("inference_requests_total{engine=\"vllm\"} 12\n"))
+	}))
+	defer withoutDepth.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "a", Runtime: "vllm", ServiceURL: withDepth.URL, Status: "ready"})
+	registry.Set(controlplane.Entry{Model: "b", Runtime: "vllm", ServiceURL: withoutDepth.URL, Status: "ready"})
+
+	scrapeQueueDepths(context.Background(), registry)
+
+	rec := httptest.NewRecorder()
+	DeploymentsHandler(registry)(rec, httptest.NewRequest(http.MethodGet, "/deployments", nil))
+
+	var deployments []map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&deployments); err != nil {
+		t.Fatalf("Failed to decode deployments: %v", err)
+	}
+	if len(deployments) != 2 {
+		t.Fatalf("Expected 2 deployments, got %d", len(deployments))
+	}
+	if got := deployments[0]["queue_depth"]; got != float64(7) {
+		t.Errorf("Expected queue depth 7 for a, got %v", got)
+	}
+	if got := deployments[1]["queue_depth"]; got != unknownQueueDepth {
+		t.Errorf("Expected unknown queue depth for b, got %v", got)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// workerRequestFields are the fields of the worker request body that a runtime may rename
+var workerRequestFields = []string{"prompt", "max_tokens", "temperature", "top_p", "stream"}
+
+// requestFormats are the built-in field mappings a runtime selects with request_format
+var requestFormats = map[string]map[string]string{
+	// vLLM's API server takes the default field names
+	"vllm": {},
+	// TGI's /generate takes the prompt as inputs and nests sampling parameters
+	"tgi": {
+		"prompt":      "inputs",
+		"max_tokens":  "parameters.max_new_tokens",
+		"temperature": "parameters.temperature",
+		"top_p":       "parameters.top_p",
+	},
+}
+
+// requestFieldMapping merges a runtime's request_fields over its request_format and checks
+// that the result only renames known fields and maps no two fields to the same place. Targets
+// may be dotted paths to nest a field, such as parameters.max_new_tokens.
+func requestFieldMapping(format string, fields map[string]string) (map[string]string, error) {
+	mapping := map[string]string{}
+	if format != "" {
+		preset, ok := requestFormats[format]
+		if !ok {
+			return nil, fmt.Errorf("unknown request_format %q", format)
+		}
+		for field, target := range preset {
+			mapping[field] = target
+		}
+	}
+	for field, target := range fields {
+		if !containsString(workerRequestFields, field) {
+			return nil, fmt.Errorf("request_fields maps unknown field %q, must be one of %s", field, strings.Join(workerRequestFields, ", "))
+		}
+		mapping[field] = target
+	}
+
+	// Fields that are not renamed keep their name, so they can collide with renamed ones too
+	targets := map[string]string{}
+	for _, field := range workerRequestFields {
+		target, ok := mapping[field]
+		if !ok {
+			target = field
+		}
+		for _, segment := range strings.Split(target, ".") {
+			if segment == "" {
+				return nil, fmt.Errorf("request_fields maps %q to invalid target %q", field, target)
+			}
+		}
+		targets[field] = target
+	}
+	fieldsByTarget := map[string]string{}
+	for _, field := range workerRequestFields {
+		fieldsByTarget[targets[field]] = field
+	}
+	for _, field := range workerRequestFields {
+		target := targets[field]
+		if other := fieldsByTarget[target]; other != field {
+			return nil, fmt.Errorf("request_fields maps both %q and %q to %q", other, field, target)
+		}
+		segments := strings.Split(target, ".")
+		for k := 1; k < len(segments); k++ {
+			parent := strings.Join(segments[:k], ".")
+			if other, ok := fieldsByTarget[parent]; ok {
+				return nil, fmt.Errorf("request_fields nests %q under %q, which %q is mapped to", field, parent, other)
+			}
+		}
+	}
+
+	if len(mapping) == 0 {
+		return nil, nil
+	}
+	return mapping, nil
+}
+
+// runtimeFieldMapping returns the request field mapping of a runtime, or nil when it keeps the
+// default field names. A missing runtimes.yaml means no runtime renames fields.
+func runtimeFieldMapping(configPath, runtime string) (map[string]string, error) {
+	config, err := loadRuntimesConfig(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range config.Runtimes {
+		if r.Name == runtime {
+			return requestFieldMapping(r.RequestFormat, r.RequestFields)
+		}
+	}
+	return nil, nil
+}
+
+// mapWorkerRequest renames the fields of a worker request body according to mapping, nesting
+// fields whose target is a dotted path. Fields without a mapping are kept as they are.
+func mapWorkerRequest(body map[string]interface{}, mapping map[string]string) map[string]interface{} {
+	if len(mapping) == 0 {
+		return body
+	}
+
+	mapped := map[string]interface{}{}
+	for field, value := range body {
+		target, ok := mapping[field]
+		if !ok {
+			target = field
+		}
+		path := strings.Split(target, ".")
+		parent := mapped
+		for _, segment := range path[:len(path)-1] {
+			child, ok := parent[segment].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				parent[segment] = child
+			}
+			parent = child
+		}
+		parent[path[len(path)-1]] = value
+	}
+	return mapped
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+func TestMapWorkerRequestTGI(t *testing.T) {
+	mapping, err := requestFieldMapping("tgi", nil)
+	if err != nil {
+		t.Fatalf("Expected the tgi format to be valid: %v", err)
+	}
+
+	mapped := mapWorkerRequest(map[string]interface{}{
+		"prompt":      "hello",
+		"max_tokens":  64,
+		"temperature": 0.2,
+		"top_p":       0.9,
+		"stream":      false,
+	}, mapping)
+
+	params, ok := mapped["parameters"].(map[string]interface{})
+	if mapped["inputs"] != "hello" || !ok || params["max_new_tokens"] != 64 || params["top_p"] != 0.9 || mapped["stream"] != false {
+		t.Errorf("Unexpected TGI request %+v", mapped)
+	}
+	if _, ok := mapped["prompt"]; ok {
+		t.Error("Expected prompt to be renamed")
+	}
+}
+
+func TestRequestFieldMappingDefaults(t *testing.T) {
+	for _, format := range []string{"", "vllm"} {
+		if mapping, err := requestFieldMapping(format, nil); err != nil || mapping != nil {
+			t.Errorf("Expected format %q to keep the default names, got %v, %v", format, mapping, err)
+		}
+	}
+
+	mapping, err := requestFieldMapping("tgi", map[string]string{"prompt": "text"})
+	if err != nil || mapping["prompt"] != "text" || mapping["max_tokens"] != "parameters.max_new_tokens" {
+		t.Errorf("Expected request_fields to override the format, got %v, %v", mapping, err)
+	}
+}
+
+func TestRequestFieldMappingRejectsInvalidMappings(t *testing.T) {
+	for name, tc := range map[string]struct {
+		format string
+		fields map[string]string
+	}{
+		"unknown format":    {format: "openai"},
+		"unknown field":     {fields: map[string]string{"max_token": "max_new_tokens"}},
+		"duplicate target":  {fields: map[string]string{"prompt": "input", "top_p": "input"}},
+		"unrenamed clash":   {fields: map[string]string{"prompt": "stream"}},
+		"nested under leaf": {fields: map[string]string{"prompt": "inputs", "top_p": "inputs.top_p"}},
+		"empty segment":     {fields: map[string]string{"top_p": "parameters..top_p"}},
+	} {
+		if _, err := requestFieldMapping(tc.format, tc.fields); err == nil {
+			t.Errorf("%s: expected the mapping to be rejected", name)
+		}
+	}
+}
+
+func TestLoadRuntimesConfigRejectsInvalidRequestFields(t *testing.T) {
+	tempDir := t.TempDir()
+	config := "runtimes:\n  - name: tgi\n    image: tgi:latest\n    request_fields:\n      inputs: prompt\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "runtimes.yaml"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	if _, err := loadRuntimesConfig(tempDir); err == nil {
+		t.Error("Expected a mapping of an unknown field to fail config loading")
+	}
+}
+
+func TestInferHandlerMapsRequestFields(t *testing.T) {
+	var got map[string]interface{}
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"output":"ok","latency_ms":1,"tokens_in":1,"tokens_out":1}`))
+	}))
+	defer worker.Close()
+
+	configPath := writeTestModelsConfig(t)
+	runtimes := "runtimes:\n  - name: tgi\n    image: tgi:latest\n    request_format: tgi\n"
+	if err := os.WriteFile(filepath.Join(configPath, "runtimes.yaml"), []byte(runtimes), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "tgi", ServiceURL: worker.URL, Status: "ready", MetadataFetched: true})
+
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader([]byte(`{"model":"test-model","runtime":"tgi","prompt":"hello","max_tokens":32}`)))
+	rr := httptest.NewRecorder()
+	InferHandler(registry, nil, configPath).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	params, _ := got["parameters"].(map[string]interface{})
+	if got["inputs"] != "hello" || params["max_new_tokens"] != float64(32) {
+		t.Errorf("Expected the worker to receive TGI field names, got %+v", got)
+	}
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RoutesConfig maps logical model names to weighted backends, loaded from routes.yaml
+type RoutesConfig struct {
+	Routes []Route `json:"routes" yaml:"routes"`
+}
+
+// Route splits traffic for a logical model name across backends by weight
+type Route struct {
+	Name     string         `json:"name" yaml:"name"`
+	Backends []RouteBackend `json:"backends" yaml:"backends"`
+}
+
+// RouteBackend is a deployed model and runtime pair receiving a percentage of a route's traffic
+type RouteBackend struct {
+	Model   string `json:"model" yaml:"model"`
+	Runtime string `json:"runtime" yaml:"runtime"`
+	Weight  int    `json:"weight" yaml:"weight"`
+}
+
+// loadRoutesConfig reads routes.yaml from the config directory. A missing file means no routes.
+func loadRoutesConfig(configPath string) (*RoutesConfig, error) {
+	data, err := os.ReadFile(filepath.Join(configPath, "routes.yaml"))
+	if errors.Is(err, os.ErrNotExist) {
+		return &RoutesConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routes config: %w", err)
+	}
+
+	var config RoutesConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse routes config: %w", err)
+	}
+	for i := range config.Routes {
+		if err := config.Routes[i].validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &config, nil
+}
+
+// findRoute returns the route for a logical model name
+func (c *RoutesConfig) findRoute(name string) (*Route, bool) {
+	for i := range c.Routes {
+		if c.Routes[i].Name == name {
+			return &c.Routes[i], true
+		}
+	}
+	return nil, false
+}
+
+// validate checks that every backend is complete and that the weights are percentages
+// summing to 100. A weight of 0 keeps a backend configured without sending it traffic.
+func (r *Route) validate() error {
+	if len(r.Backends) == 0 {
+		return fmt.Errorf("route %s has no backends", r.Name)
+	}
+	total := 0
+	for _, backend := range r.Backends {
+		if backend.Model == "" || backend.Runtime == "" {
+			return fmt.Errorf("route %s has a backend without model or runtime", r.Name)
+		}
+		if backend.Weight < 0 {
+			return fmt.Errorf("route %s has a negative weight for %s/%s", r.Name, backend.Model, backend.Runtime)
+		}
+		total += backend.Weight
+	}
+	if total != 100 {
+		return fmt.Errorf("route %s weights sum to %d, want 100", r.Name, total)
+	}
+	return nil
+}
+
+// pick chooses a backend with probability proportional to its weight. n must be in [0, 100).
+func (r *Route) pick(n int) RouteBackend {
+	for _, backend := range r.Backends {
+		if n < backend.Weight {
+			return backend
+		}
+		n -= backend.Weight
+	}
+	return r.Backends[len(r.Backends)-1]
+}
+
+// pickRandom chooses a backend for a single request
+func (r *Route) pickRandom() RouteBackend {
+	return r.pick(rand.Intn(100))
+}
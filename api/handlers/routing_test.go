@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+func TestRoutePickFollowsWeights(t *testing.T) {
+	route := Route{Name: "canary", Backends: []RouteBackend{
+		{Model: "m", Runtime: "vllm", Weight: 90},
+		{Model: "m", Runtime: "transformers", Weight: 10},
+	}}
+	if err := route.validate(); err != nil {
+		t.Fatalf("Expected valid route, got %v", err)
+	}
+
+	counts := map[string]int{}
+	for n := 0; n < 100; n++ {
+		counts[route.pick(n).Runtime]++
+	}
+	if counts["vllm"] != 90 || counts["transformers"] != 10 {
+		t.Errorf("Expected a 90/10 split across the weight range, got %v", counts)
+	}
+}
+
+func TestRouteFullCutover(t *testing.T) {
+	route := Route{Name: "cutover", Backends: []RouteBackend{
+		{Model: "m", Runtime: "vllm", Weight: 0},
+		{Model: "m", Runtime: "transformers", Weight: 100},
+	}}
+	if err := route.validate(); err != nil {
+		t.Fatalf("Expected 0/100 route to be valid, got %v", err)
+	}
+	for n := 0; n < 100; n++ {
+		if got := route.pick(n).Runtime; got != "transformers" {
+			t.Fatalf("Expected all traffic on transformers, got %s for %d", got, n)
+		}
+	}
+}
+
+func TestRouteValidateRejectsBadWeights(t *testing.T) {
+	routes := map[string][]RouteBackend{
+		"under 100":       {{Model: "m", Runtime: "vllm", Weight: 50}},
+		"negative":        {{Model: "m", Runtime: "vllm", Weight: 110}, {Model: "m", Runtime: "transformers", Weight: -10}},
+		"no backends":     nil,
+		"missing runtime": {{Model: "m", Weight: 100}},
+	}
+	for name, backends := range routes {
+		route := Route{Name: name, Backends: backends}
+		if err := route.validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestInferHandlerRoutesToBackend(t *testing.T) {
+	var gotMaxTokens int
+	worker := newTestWorker(t, &gotMaxTokens)
+	defer worker.Close()
+
+	configPath := writeTestModelsConfig(t)
+	routes := `routes:
+  - name: test-route
+    backends:
+      - model: test-model
+        runtime: minimal
+        weight: 100
+`
+	if err := os.WriteFile(filepath.Join(configPath, "routes.yaml"), []byte(routes), 0644); err != nil {
+		t.Fatalf("Failed to write routes config: %v", err)
+	}
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+	handler := InferHandler(registry, nil, configPath)
+
+	body := []byte(`{"model":"test-route","prompt":"hello"}`)
+	req := httptest.NewRequest("POST", "/api/v1/infer", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	if rr.Header().Get("X-Route") != "test-route" || rr.Header().Get("X-Model") != "test-model" || rr.Header().Get("X-Runtime") != "minimal" {
+		t.Errorf("Expected backend headers, got route=%q model=%q runtime=%q", rr.Header().Get("X-Route"), rr.Header().Get("X-Model"), rr.Header().Get("X-Runtime"))
+	}
+}
@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 
+	"github.com/go-chi/chi/v5"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,23 +20,97 @@ type RuntimesConfig struct {
 		CPU   string            `json:"cpu" yaml:"cpu"`
 		Mem   string            `json:"mem" yaml:"mem"`
 		Env   map[string]string `json:"env" yaml:"env"`
+		// Quants lists the quantizations the runtime supports; empty means unrestricted
+		Quants       []string `json:"quants,omitempty" yaml:"quants"`
+		SupportsCPU  bool     `json:"supports_cpu,omitempty" yaml:"supports_cpu"`
+		StartupProbe *struct {
+			PeriodSeconds    int32 `json:"period_seconds,omitempty" yaml:"period_seconds"`
+			FailureThreshold int32 `json:"failure_threshold,omitempty" yaml:"failure_threshold"`
+		} `json:"startup_probe,omitempty" yaml:"startup_probe"`
+		Images        map[string]string `json:"images,omitempty" yaml:"images"`
+		ReadinessPath string            `json:"readiness_path,omitempty" yaml:"readiness_path"`
+		Command       []string          `json:"command,omitempty" yaml:"command"`
+		Args          []string          `json:"args,omitempty" yaml:"args"`
+		Replicas      int               `json:"replicas,omitempty" yaml:"replicas"`
+		// GPUHourCost is the price of one GPU-hour, used to estimate benchmark cost
+		GPUHourCost float64 `json:"gpu_hour_cost,omitempty" yaml:"gpu_hour_cost"`
+		// Profiles are named resource tiers that override the base resources at deploy time
+		Profiles map[string]struct {
+			GPU      *int   `json:"gpu,omitempty" yaml:"gpu"`
+			CPU      string `json:"cpu,omitempty" yaml:"cpu"`
+			Mem      string `json:"mem,omitempty" yaml:"mem"`
+			Replicas int    `json:"replicas,omitempty" yaml:"replicas"`
+		} `json:"profiles,omitempty" yaml:"profiles"`
+		// RequestFormat selects a built-in worker request field mapping, such as tgi
+		RequestFormat string `json:"request_format,omitempty" yaml:"request_format"`
+		// RequestFields renames worker request fields, overriding RequestFormat
+		RequestFields map[string]string `json:"request_fields,omitempty" yaml:"request_fields"`
 	} `json:"runtimes" yaml:"runtimes"`
 }
 
+// QuantImage is the image resolved for a single quant of a runtime
+type QuantImage struct {
+	Quant   string `json:"quant"`
+	Image   string `json:"image"`
+	Default bool   `json:"default"`
+}
+
+// RuntimeImagesResponse describes the quant to image mapping of a runtime
+type RuntimeImagesResponse struct {
+	Runtime      string       `json:"runtime"`
+	DefaultImage string       `json:"default_image"`
+	Images       []QuantImage `json:"images"`
+}
+
+// supportedQuants returns the quants declared for a runtime and whether the runtime exists
+func (c *RuntimesConfig) supportedQuants(runtime string) ([]string, bool) {
+	for _, r := range c.Runtimes {
+		if r.Name == runtime {
+			return r.Quants, true
+		}
+	}
+	return nil, false
+}
+
+// loadRuntimesConfig reads and parses runtimes.yaml from the config directory
+func loadRuntimesConfig(configPath string) (*RuntimesConfig, error) {
+	data, err := os.ReadFile(filepath.Join(configPath, "runtimes.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read runtimes config: %w", err)
+	}
+
+	var config RuntimesConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse runtimes config: %w", err)
+	}
+	if len(config.Runtimes) == 0 {
+		return nil, fmt.Errorf("no runtimes defined in config")
+	}
+	for _, r := range config.Runtimes {
+		if _, err := requestFieldMapping(r.RequestFormat, r.RequestFields); err != nil {
+			return nil, fmt.Errorf("invalid runtimes config: runtime %s: %w", r.Name, err)
+		}
+	}
+
+	return &config, nil
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // RuntimesHandler returns the configured runtimes from YAML
 func RuntimesHandler(configPath string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Read runtimes config file
-		data, err := os.ReadFile(filepath.Join(configPath, "runtimes.yaml"))
+		config, err := loadRuntimesConfig(configPath)
 		if err != nil {
-			http.Error(w, "failed to read runtimes config: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Parse YAML
-		var config RuntimesConfig
-		if err := yaml.Unmarshal(data, &config); err != nil {
-			http.Error(w, "failed to parse runtimes config: "+err.Error(), http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
@@ -43,3 +120,54 @@ func RuntimesHandler(configPath string) http.HandlerFunc {
 		json.NewEncoder(w).Encode(config)
 	}
 }
+
+// RuntimeImagesHandler returns the image resolved for each quant of a runtime,
+// marking quants that fall back to the runtime's default image
+func RuntimeImagesHandler(configPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "name")
+
+		config, err := loadRuntimesConfig(configPath)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		for _, runtime := range config.Runtimes {
+			if runtime.Name != name {
+				continue
+			}
+
+			quants := append([]string{}, runtime.Quants...)
+			for quant := range runtime.Images {
+				if !containsString(quants, quant) {
+					quants = append(quants, quant)
+				}
+			}
+			sort.Strings(quants)
+
+			resp := RuntimeImagesResponse{
+				Runtime:      runtime.Name,
+				DefaultImage: runtime.Image,
+				Images:       []QuantImage{},
+			}
+			for _, quant := range quants {
+				image, mapped := runtime.Images[quant]
+				if !mapped || image == "" {
+					image = runtime.Image
+				}
+				resp.Images = append(resp.Images, QuantImage{
+					Quant:   quant,
+					Image:   image,
+					Default: !mapped || image == runtime.Image,
+				})
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+
+		http.Error(w, "runtime not found", http.StatusNotFound)
+	}
+}
@@ -57,3 +57,29 @@ func TestRuntimesHandler(t *testing.T) {
 		t.Errorf("Handler response doesn't contain expected runtime: %v", response)
 	}
 }
+
+func TestRuntimesHandlerRejectsEmptyConfig(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty file":      "",
+		"missing section": "other: true\n",
+		"empty section":   "runtimes: []\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(tempDir, "runtimes.yaml"), []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write test config: %v", err)
+			}
+
+			req := httptest.NewRequest("GET", "/api/v1/runtimes", nil)
+			rr := httptest.NewRecorder()
+			RuntimesHandler(tempDir).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusInternalServerError {
+				t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusInternalServerError)
+			}
+			if !contains(rr.Body.String(), "no runtimes defined in config") {
+				t.Errorf("Expected clear error message, got %q", rr.Body.String())
+			}
+		})
+	}
+}
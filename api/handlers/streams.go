@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// streamCloseGrace is how long closed streams get to write their shutdown event and return
+const streamCloseGrace = 2 * time.Second
+
+// streamTracker counts active SSE inference streams so shutdown can drain them
+type streamTracker struct {
+	mu       sync.Mutex
+	active   sync.WaitGroup
+	draining bool
+	// stopping is closed when draining starts, ending streams that never finish on their own
+	stopping chan struct{}
+	// closing is closed when the drain timeout expires and open streams must end
+	closing chan struct{}
+}
+
+func newStreamTracker() *streamTracker {
+	return &streamTracker{stopping: make(chan struct{}), closing: make(chan struct{})}
+}
+
+// streams tracks the streaming connections served by InferHandler
+var streams = newStreamTracker()
+
+// begin registers a new stream, returning false once the server has started draining
+func (t *streamTracker) begin() (func(), bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return nil, false
+	}
+	t.active.Add(1)
+	return t.active.Done, true
+}
+
+// drain stops new streams and waits for active ones to finish. When ctx expires first,
+// the remaining streams are told to send a shutdown event and close.
+func (t *streamTracker) drain(ctx context.Context) error {
+	t.mu.Lock()
+	if t.draining {
+		t.mu.Unlock()
+		return nil
+	}
+	t.draining = true
+	close(t.stopping)
+	t.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		t.active.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+
+	close(t.closing)
+	select {
+	case <-finished:
+	case <-time.After(streamCloseGrace):
+	}
+	return fmt.Errorf("streams still active after drain timeout: %w", ctx.Err())
+}
+
+// DrainStreams stops accepting new inference streams and waits for active ones to finish
+// until ctx expires, then closes the rest after sending them a shutdown event. Call it
+// before http.Server.Shutdown, which would otherwise wait on or cut the open streams.
+func DrainStreams(ctx context.Context) error {
+	return streams.drain(ctx)
+}
+
+// writeShutdownEvent tells an SSE client the server is going away so it can reconnect elsewhere
+func writeShutdownEvent(w http.ResponseWriter) {
+	fmt.Fprint(w, "event: shutdown\ndata: {\"reason\":\"server shutting down\",\"reconnect\":true}\n\n")
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamTrackerDrainWaitsForActiveStreams(t *testing.T) {
+	tracker := newStreamTracker()
+	done, ok := tracker.begin()
+	if !ok {
+		t.Fatalf("Expected a stream to start before draining")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tracker.drain(ctx); err != nil {
+		t.Fatalf("Expected drain to finish once the stream ends, got %v", err)
+	}
+	if _, ok := tracker.begin(); ok {
+		t.Errorf("Expected new streams to be refused while draining")
+	}
+}
+
+func TestStreamTrackerDrainClosesStreamsAfterTimeout(t *testing.T) {
+	tracker := newStreamTracker()
+	done, _ := tracker.begin()
+
+	// A stream that only ends when told to close
+	go func() {
+		<-tracker.closing
+		done()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := tracker.drain(ctx); err == nil {
+		t.Errorf("Expected drain to report streams cut off by the timeout")
+	}
+	select {
+	case <-tracker.closing:
+	default:
+		t.Errorf("Expected open streams to be signaled to close")
+	}
+}
+
+func TestWriteShutdownEvent(t *testing.T) {
+	rr := httptest.NewRecorder()
+	writeShutdownEvent(rr)
+	if !strings.HasPrefix(rr.Body.String(), "event: shutdown\n") || !rr.Flushed {
+		t.Errorf("Expected a flushed shutdown event, got %q", rr.Body.String())
+	}
+}
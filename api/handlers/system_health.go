@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane/k8s"
+	"github.com/tokenforge/llm-infra-bench/db"
+)
+
+const (
+	// healthCheckTimeout bounds each subsystem check of the system health endpoint
+	healthCheckTimeout = 2 * time.Second
+	// loopStaleIntervals is how many intervals a background loop may miss before it is reported stale
+	loopStaleIntervals = 3
+)
+
+// Component and overall statuses reported by the system health endpoint
+const (
+	componentOK       = "ok"
+	componentDown     = "down"
+	componentStale    = "stale"
+	componentDisabled = "disabled"
+
+	systemHealthy   = "healthy"
+	systemDegraded  = "degraded"
+	systemUnhealthy = "unhealthy"
+)
+
+// pingKubernetes checks the Kubernetes API; replaced in tests
+var pingKubernetes = k8s.Ping
+
+// ComponentHealth is the status of one control-plane subsystem
+type ComponentHealth struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// LoopHealth is the liveness of a background loop
+type LoopHealth struct {
+	Status   string     `json:"status"`
+	Interval string     `json:"interval"`
+	LastRun  *time.Time `json:"last_run,omitempty"`
+}
+
+// SystemHealthResponse is the response for the system health endpoint
+type SystemHealthResponse struct {
+	Status     string                     `json:"status"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+	Components map[string]ComponentHealth `json:"components"`
+	Loops      map[string]LoopHealth      `json:"loops"`
+}
+
+// loopTracker records when each background loop last ran
+type loopTracker struct {
+	mu    sync.Mutex
+	loops map[string]*loopState
+}
+
+// loopState is the registered interval and last run time of a background loop
+type loopState struct {
+	interval time.Duration
+	started  time.Time
+	lastRun  time.Time
+}
+
+// backgroundLoops tracks the API's background loops for the system health endpoint
+var backgroundLoops = newLoopTracker()
+
+// newLoopTracker creates an empty loop tracker
+func newLoopTracker() *loopTracker {
+	return &loopTracker{loops: make(map[string]*loopState)}
+}
+
+// register adds a loop that is expected to run every interval
+func (t *loopTracker) register(name string, interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.loops[name] = &loopState{interval: interval, started: time.Now()}
+}
+
+// ran records that a loop completed an iteration
+func (t *loopTracker) ran(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if loop, ok := t.loops[name]; ok {
+		loop.lastRun = time.Now()
+	}
+}
+
+// health reports each loop as stale when it has not run for loopStaleIntervals intervals
+func (t *loopTracker) health(now time.Time) map[string]LoopHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make(map[string]LoopHealth, len(t.loops))
+	for name, loop := range t.loops {
+		health := LoopHealth{Status: componentOK, Interval: loop.interval.String()}
+		since := loop.started
+		if !loop.lastRun.IsZero() {
+			lastRun := loop.lastRun
+			health.LastRun = &lastRun
+			since = lastRun
+		}
+		if now.Sub(since) > loopStaleIntervals*loop.interval {
+			health.Status = componentStale
+		}
+		result[name] = health
+	}
+	return result
+}
+
+// checkComponent runs a subsystem check under healthCheckTimeout
+func checkComponent(ctx context.Context, check func(ctx context.Context) error) ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	health := ComponentHealth{Status: componentOK, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		health.Status = componentDown
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// checkConfig reports whether models.yaml and runtimes.yaml load
+func checkConfig(configPath string) ComponentHealth {
+	if _, err := loadModelsConfig(configPath); err != nil {
+		return ComponentHealth{Status: componentDown, Error: err.Error(), Detail: configPath}
+	}
+	if _, err := loadRuntimesConfig(configPath); err != nil {
+		return ComponentHealth{Status: componentDown, Error: err.Error(), Detail: configPath}
+	}
+	return ComponentHealth{Status: componentOK, Detail: configPath}
+}
+
+// overallStatus is unhealthy when the database or config is down and degraded when the
+// Kubernetes API is unreachable or a background loop has stalled, since inference to
+// running workers still works then
+func overallStatus(resp SystemHealthResponse) string {
+	status := systemHealthy
+	for name, component := range resp.Components {
+		if component.Status != componentDown {
+			continue
+		}
+		if name == "database" || name == "config" {
+			return systemUnhealthy
+		}
+		status = systemDegraded
+	}
+	for _, loop := range resp.Loops {
+		if loop.Status == componentStale {
+			status = systemDegraded
+		}
+	}
+	return status
+}
+
+// SystemHealthHandler reports the status of the database, the Kubernetes API, the config and
+// the background loops. It responds 503 when the system is unhealthy so it can back probes.
+func SystemHealthHandler(dbClient *db.Client, configPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp := SystemHealthResponse{
+			CheckedAt:  time.Now().UTC(),
+			Components: map[string]ComponentHealth{},
+		}
+
+		if dbClient == nil {
+			resp.Components["database"] = ComponentHealth{Status: componentDisabled, Detail: "inference-only mode"}
+		} else {
+			resp.Components["database"] = checkComponent(r.Context(), dbClient.Ping)
+		}
+		resp.Components["kubernetes"] = checkComponent(r.Context(), pingKubernetes)
+		resp.Components["config"] = checkConfig(configPath)
+		resp.Loops = backgroundLoops.health(time.Now())
+		resp.Status = overallStatus(resp)
+
+		w.Header().Set("Content-Type", "application/json")
+		if resp.Status == systemUnhealthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// stubSystemHealth replaces the Kubernetes check and loop tracker for a test
+func stubSystemHealth(t *testing.T, pingErr error) *loopTracker {
+	t.Helper()
+	oldPing, oldLoops := pingKubernetes, backgroundLoops
+	pingKubernetes = func(ctx context.Context) error { return pingErr }
+	backgroundLoops = newLoopTracker()
+	t.Cleanup(func() { pingKubernetes, backgroundLoops = oldPing, oldLoops })
+	return backgroundLoops
+}
+
+// getSystemHealth calls the system health endpoint and decodes the response
+func getSystemHealth(t *testing.T, configPath string) (int, SystemHealthResponse) {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	SystemHealthHandler(nil, configPath).ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/system/health", nil))
+
+	var resp SystemHealthResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", rr.Body.String(), err)
+	}
+	return rr.Code, resp
+}
+
+// writeTestSystemConfig writes a models.yaml and runtimes.yaml that load cleanly
+func writeTestSystemConfig(t *testing.T) string {
+	t.Helper()
+	configPath := writeTestModelsConfig(t)
+	runtimes := "runtimes:\n  - name: vllm\n    image: worker-vllm:latest\n"
+	if err := os.WriteFile(filepath.Join(configPath, "runtimes.yaml"), []byte(runtimes), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	return configPath
+}
+
+func TestSystemHealthHandlerHealthy(t *testing.T) {
+	loops := stubSystemHealth(t, nil)
+	loops.register("queue_depth_scraper", time.Minute)
+	loops.ran("queue_depth_scraper")
+
+	code, resp := getSystemHealth(t, writeTestSystemConfig(t))
+
+	if code != http.StatusOK || resp.Status != systemHealthy {
+		t.Fatalf("Expected a healthy 200, got %v %+v", code, resp)
+	}
+	if resp.Components["database"].Status != componentDisabled {
+		t.Errorf("Expected the database to be reported disabled without a client, got %+v", resp.Components["database"])
+	}
+	if resp.Components["kubernetes"].Status != componentOK || resp.Components["config"].Status != componentOK {
+		t.Errorf("Expected kubernetes and config to be ok, got %+v", resp.Components)
+	}
+	if loop := resp.Loops["queue_depth_scraper"]; loop.Status != componentOK || loop.LastRun == nil {
+		t.Errorf("Expected the loop to be live with a last run time, got %+v", loop)
+	}
+}
+
+func TestSystemHealthHandlerDegradedWithoutKubernetes(t *testing.T) {
+	stubSystemHealth(t, errors.New("connection refused"))
+
+	code, resp := getSystemHealth(t, writeTestSystemConfig(t))
+
+	if code != http.StatusOK || resp.Status != systemDegraded {
+		t.Fatalf("Expected a degraded 200, got %v %+v", code, resp)
+	}
+	if k := resp.Components["kubernetes"]; k.Status != componentDown || k.Error != "connection refused" {
+		t.Errorf("Expected the kubernetes error to be reported, got %+v", k)
+	}
+}
+
+func TestSystemHealthHandlerUnhealthyWithoutConfig(t *testing.T) {
+	stubSystemHealth(t, nil)
+
+	code, resp := getSystemHealth(t, t.TempDir())
+
+	if code != http.StatusServiceUnavailable || resp.Status != systemUnhealthy {
+		t.Fatalf("Expected an unhealthy 503, got %v %+v", code, resp)
+	}
+	if resp.Components["config"].Status != componentDown || resp.Components["config"].Error == "" {
+		t.Errorf("Expected the config error to be reported, got %+v", resp.Components["config"])
+	}
+}
+
+func TestLoopTrackerReportsStaleLoops(t *testing.T) {
+	loops := newLoopTracker()
+	loops.register("stale_run_sweeper", time.Second)
+	loops.ran("stale_run_sweeper")
+
+	if status := loops.health(time.Now())["stale_run_sweeper"].Status; status != componentOK {
+		t.Errorf("Expected a loop that just ran to be ok, got %s", status)
+	}
+	if status := loops.health(time.Now().Add(loopStaleIntervals*time.Second + time.Second))["stale_run_sweeper"].Status; status != componentStale {
+		t.Errorf("Expected a loop that missed %d intervals to be stale, got %s", loopStaleIntervals, status)
+	}
+
+	loops.register("never_ran", time.Second)
+	if status := loops.health(time.Now().Add(time.Hour))["never_ran"].Status; status != componentStale {
+		t.Errorf("Expected a loop that never ran to turn stale, got %s", status)
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+// defaultWarmupMaxAttempts caps how many warmup requests are sent when WARMUP_MAX_ATTEMPTS is unset
+const defaultWarmupMaxAttempts = 5
+
+// warmupStableTolerance is how close two consecutive latencies must be for the worker to
+// count as warm, as a fraction of the earlier latency
+const warmupStableTolerance = 0.2
+
+// warmupPrompt and warmupMaxTokens shape the throwaway requests: long enough to exercise
+// prefill and decode, short enough to keep warmup quick
+const (
+	warmupPrompt    = "Warm up the model by briefly describing what a GPU does."
+	warmupMaxTokens = 16
+)
+
+// WarmupRequest optionally overrides the warmup attempt limit
+type WarmupRequest struct {
+	MaxAttempts int `json:"max_attempts"`
+}
+
+// WarmupResponse reports the warmup requests sent to a worker
+type WarmupResponse struct {
+	Model     string  `json:"model"`
+	Runtime   string  `json:"runtime"`
+	Attempts  int     `json:"attempts"`
+	Latencies []int64 `json:"latencies_ms"`
+	// Stable is false when the attempt limit was reached before latency settled
+	Stable bool `json:"stable"`
+	Warm   bool `json:"warm"`
+}
+
+// latencyStable reports whether the last two latencies are within warmupStableTolerance
+func latencyStable(latencies []time.Duration) bool {
+	if len(latencies) < 2 {
+		return false
+	}
+	prev, last := latencies[len(latencies)-2], latencies[len(latencies)-1]
+	if prev <= 0 {
+		return false
+	}
+	return math.Abs(float64(last-prev))/float64(prev) <= warmupStableTolerance
+}
+
+// DeploymentWarmupHandler sends throwaway inference requests to a ready worker until its
+// latency stabilizes or the attempt limit is reached, then marks the deployment warm
+func DeploymentWarmupHandler(registry *controlplane.Registry, configPath string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		model := modelParam(r, configPath)
+		runtime := chi.URLParam(r, "runtime")
+
+		entry, ok := registry.Get(model, runtime)
+		if !ok {
+			http.Error(w, "Deployment not found", http.StatusNotFound)
+			return
+		}
+		if entry.Status != "ready" {
+			http.Error(w, fmt.Sprintf("deployment is %s, not ready", entry.Status), http.StatusConflict)
+			return
+		}
+
+		var req WarmupRequest
+		if err := decodeJSON(r, &req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		maxAttempts := req.MaxAttempts
+		if maxAttempts <= 0 {
+			maxAttempts = envInt("WARMUP_MAX_ATTEMPTS", defaultWarmupMaxAttempts)
+		}
+
+		client, err := getWorkerClient()
+		if err != nil {
+			http.Error(w, "worker client misconfigured: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fieldMapping, err := runtimeFieldMapping(configPath, runtime)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body, err := json.Marshal(mapWorkerRequest(map[string]interface{}{
+			"prompt":     warmupPrompt,
+			"max_tokens": warmupMaxTokens,
+			"stream":     false,
+		}, fieldMapping))
+		if err != nil {
+			http.Error(w, "failed to encode request: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp := WarmupResponse{Model: model, Runtime: runtime, Latencies: []int64{}}
+		var latencies []time.Duration
+		for resp.Attempts < maxAttempts {
+			resp.Attempts++
+			start := time.Now()
+			workerResp, err := postWorker(r.Context(), client, entry.ServiceURL+"/infer", body, nil, 0)
+			if err != nil {
+				http.Error(w, "warmup request failed: "+err.Error(), http.StatusBadGateway)
+				return
+			}
+			io.Copy(io.Discard, workerResp.Body)
+			workerResp.Body.Close()
+			if workerResp.StatusCode != http.StatusOK {
+				http.Error(w, fmt.Sprintf("warmup request failed: worker returned %d", workerResp.StatusCode), http.StatusBadGateway)
+				return
+			}
+
+			latency := time.Since(start)
+			latencies = append(latencies, latency)
+			resp.Latencies = append(resp.Latencies, latency.Milliseconds())
+			if latencyStable(latencies) {
+				resp.Stable = true
+				break
+			}
+		}
+
+		resp.Warm = registry.SetWarm(model, runtime, true)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
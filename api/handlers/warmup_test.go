@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+func TestLatencyStable(t *testing.T) {
+	ms := time.Millisecond
+	if latencyStable([]time.Duration{900 * ms}) {
+		t.Error("Expected a single latency not to count as stable")
+	}
+	if latencyStable([]time.Duration{900 * ms, 300 * ms}) {
+		t.Error("Expected a large drop in latency not to count as stable")
+	}
+	if !latencyStable([]time.Duration{900 * ms, 300 * ms, 280 * ms}) {
+		t.Error("Expected latencies within tolerance to count as stable")
+	}
+}
+
+func TestDeploymentWarmupHandler(t *testing.T) {
+	var calls int
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"output":"ok"}`))
+	}))
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready"})
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "vllm", ServiceURL: worker.URL, Status: "deploying"})
+
+	router := chi.NewRouter()
+	router.Post("/deployments/{model}/{runtime}/warmup", DeploymentWarmupHandler(registry, t.TempDir()))
+
+	req := httptest.NewRequest("POST", "/deployments/test-model/minimal/warmup", strings.NewReader(`{"max_attempts": 3}`))
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Warmup returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	var resp WarmupResponse
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if resp.Attempts < 1 || resp.Attempts > 3 || resp.Attempts != calls {
+		t.Errorf("Expected between 1 and 3 warmup requests, got %d attempts and %d calls", resp.Attempts, calls)
+	}
+	if entry, _ := registry.Get("test-model", "minimal"); !entry.Warm || !resp.Warm {
+		t.Error("Expected the deployment to be marked warm")
+	}
+
+	req = httptest.NewRequest("POST", "/deployments/test-model/vllm/warmup", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Warmup of a deploying worker returned wrong status code: got %v want %v", rr.Code, http.StatusConflict)
+	}
+}
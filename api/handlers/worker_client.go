@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+var (
+	workerClientOnce sync.Once
+	workerClient     *http.Client
+	workerClientErr  error
+)
+
+// getWorkerClient returns the shared client used to call workers, built once from the env
+func getWorkerClient() (*http.Client, error) {
+	workerClientOnce.Do(func() {
+		workerClient, workerClientErr = newWorkerClient()
+	})
+	return workerClient, workerClientErr
+}
+
+// newWorkerClient builds the worker HTTP client. Plain HTTP needs no configuration; for https
+// worker URLs WORKER_TLS_CA_FILE adds a CA to trust, and WORKER_TLS_CERT_FILE and
+// WORKER_TLS_KEY_FILE supply a client certificate for mTLS.
+func newWorkerClient() (*http.Client, error) {
+	caFile := os.Getenv("WORKER_TLS_CA_FILE")
+	certFile := os.Getenv("WORKER_TLS_CERT_FILE")
+	keyFile := os.Getenv("WORKER_TLS_KEY_FILE")
+	if caFile == "" && certFile == "" && keyFile == "" {
+		return &http.Client{}, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: os.Getenv("WORKER_TLS_SERVER_NAME"),
+	}
+
+	if caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read worker CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in worker CA file %s", caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("WORKER_TLS_CERT_FILE and WORKER_TLS_KEY_FILE must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load worker client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
+
+// workerMetadataTimeout bounds how long a worker metadata fetch may take
+const workerMetadataTimeout = 5 * time.Second
+
+// workerMetadataRetryInterval is how long inference requests skip the metadata fetch after
+// one failed, so an unreachable worker does not add the fetch timeout to every request
+const workerMetadataRetryInterval = 30 * time.Second
+
+// metadataFailures records when the last metadata fetch from each worker URL failed
+var metadataFailures sync.Map
+
+// WorkerMetadata is what a worker reports about the model it loaded
+type WorkerMetadata struct {
+	Model      string `json:"model"`
+	Quant      string `json:"quant"`
+	MaxContext int    `json:"max_context"`
+}
+
+// fetchWorkerMetadata queries a worker's /metadata endpoint. Workers without the endpoint
+// report empty metadata; any other non-200 response, such as a 503 while the model is still
+// loading, is an error.
+func fetchWorkerMetadata(ctx context.Context, baseURL string) (*WorkerMetadata, error) {
+	client, err := getWorkerClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, workerMetadataTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/metadata", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch worker metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var metadata WorkerMetadata
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode worker metadata: %w", err)
+		}
+	case http.StatusNotFound:
+	default:
+		return nil, fmt.Errorf("worker metadata returned status %d", resp.StatusCode)
+	}
+	return &metadata, nil
+}
+
+// cacheWorkerMetadata fetches the worker's metadata once and caches it on the registry entry.
+// Failed fetches are retried on a later call once workerMetadataRetryInterval has passed.
+func cacheWorkerMetadata(ctx context.Context, registry *controlplane.Registry, entry controlplane.Entry) controlplane.Entry {
+	if entry.MetadataFetched {
+		return entry
+	}
+	if failedAt, ok := metadataFailures.Load(entry.ServiceURL); ok && time.Since(failedAt.(time.Time)) < workerMetadataRetryInterval {
+		return entry
+	}
+	metadata, err := fetchWorkerMetadata(ctx, entry.ServiceURL)
+	if err != nil {
+		log.Printf("Failed to fetch metadata for %s with runtime %s: %v", entry.Model, entry.Runtime, err)
+		metadataFailures.Store(entry.ServiceURL, time.Now())
+		return entry
+	}
+	metadataFailures.Delete(entry.ServiceURL)
+	registry.SetMaxContext(entry.Model, entry.Runtime, metadata.MaxContext)
+	entry.MaxContext = metadata.MaxContext
+	entry.MetadataFetched = true
+	return entry
+}
+
+// CacheMetadataOnReady fetches a worker's metadata in the background as soon as its entry
+// becomes ready, so inference requests find its context window already cached
+func CacheMetadataOnReady(ctx context.Context, registry *controlplane.Registry) {
+	registry.OnReady(func(entry controlplane.Entry) {
+		metadataFailures.Delete(entry.ServiceURL)
+		go cacheWorkerMetadata(ctx, registry, entry)
+	})
+}
+
+// defaultInferRetries is how many times a failed worker request is retried when INFER_RETRIES is unset
+const defaultInferRetries = 2
+
+// inferRetries returns how many times a request's worker call may be retried. Requests with
+// X-No-Retry are never retried: benchmark traffic sets it because a retried request reports
+// the latency of several attempts and hides the failure, distorting measured latency and
+// error rates.
+func inferRetries(r *http.Request) int {
+	switch r.Header.Get("X-No-Retry") {
+	case "true", "1":
+		return 0
+	}
+	if retries := envInt("INFER_RETRIES", defaultInferRetries); retries > 0 {
+		return retries
+	}
+	return 0
+}
+
+// retryableStatus reports whether a worker response is a transient failure worth retrying
+func retryableStatus(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// traceHeaders are the correlation headers passed through from callers to workers, so a
+// benchmark run can be followed from the run to each harness request to the worker log
+var traceHeaders = []string{"X-Run-ID", "X-Trace-ID", "X-Request-ID", "Traceparent"}
+
+// forwardedTraceHeaders returns the correlation headers set on an incoming request
+func forwardedTraceHeaders(r *http.Request) http.Header {
+	header := http.Header{}
+	for _, name := range traceHeaders {
+		if value := r.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	return header
+}
+
+// postWorker sends a request to a worker with the given extra headers, retrying connection
+// failures and transient 5xx responses up to retries times with a linear backoff set by
+// INFER_RETRY_BACKOFF
+func postWorker(ctx context.Context, client *http.Client, url string, body []byte, header http.Header, retries int) (*http.Response, error) {
+	backoff := envDuration("INFER_RETRY_BACKOFF", 100*time.Millisecond)
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if attempt >= retries || (err == nil && !retryableStatus(resp.StatusCode)) {
+			return resp, err
+		}
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * backoff):
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane"
+)
+
+func TestNewWorkerClientDefaultsToPlainHTTP(t *testing.T) {
+	client, err := newWorkerClient()
+	if err != nil {
+		t.Fatalf("Expected default client, got %v", err)
+	}
+	if client.Transport != nil {
+		t.Errorf("Expected default transport when no TLS options are set")
+	}
+}
+
+func TestNewWorkerClientTrustsConfiguredCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0644); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+	t.Setenv("WORKER_TLS_CA_FILE", caFile)
+
+	client, err := newWorkerClient()
+	if err != nil {
+		t.Fatalf("Failed to build worker client: %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected TLS request to succeed with configured CA, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewWorkerClientRequiresCertAndKeyTogether(t *testing.T) {
+	t.Setenv("WORKER_TLS_CERT_FILE", "/nonexistent/cert.pem")
+	if _, err := newWorkerClient(); err == nil {
+		t.Errorf("Expected error when the client key is missing")
+	}
+}
+
+func TestInferHandlerRetriesUnlessNoRetry(t *testing.T) {
+	t.Setenv("INFER_RETRY_BACKOFF", "1ms")
+
+	tests := []struct {
+		name      string
+		noRetry   bool
+		wantCode  int
+		wantCalls int
+	}{
+		{"interactive traffic retries", false, http.StatusOK, 2},
+		{"benchmark traffic does not retry", true, http.StatusServiceUnavailable, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					http.Error(w, "loading", http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"output":"ok","latency_ms":1,"tokens_in":1,"tokens_out":1}`))
+			}))
+			defer worker.Close()
+
+			registry := controlplane.NewRegistry()
+			registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready", MetadataFetched: true})
+
+			req := httptest.NewRequest("POST", "/api/v1/infer", strings.NewReader(`{"model":"test-model","runtime":"minimal","prompt":"hello"}`))
+			if tt.noRetry {
+				req.Header.Set("X-No-Retry", "true")
+			}
+			rr := httptest.NewRecorder()
+			InferHandler(registry, nil, writeTestModelsConfig(t)).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("Handler returned wrong status code: got %v want %v", rr.Code, tt.wantCode)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected %d worker calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestInferHandlerForwardsTraceHeaders(t *testing.T) {
+	var got http.Header
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"output":"ok","latency_ms":1,"tokens_in":1,"tokens_out":1}`))
+	}))
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "minimal", ServiceURL: worker.URL, Status: "ready", MetadataFetched: true})
+
+	req := httptest.NewRequest("POST", "/api/v1/infer", strings.NewReader(`{"model":"test-model","runtime":"minimal","prompt":"hello"}`))
+	req.Header.Set("X-Run-ID", "run-1")
+	req.Header.Set("X-Trace-ID", "abc123")
+	req.Header.Set("X-API-Key", "secret")
+	rr := httptest.NewRecorder()
+	InferHandler(registry, nil, writeTestModelsConfig(t)).ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if got.Get("X-Run-ID") != "run-1" || got.Get("X-Trace-ID") != "abc123" {
+		t.Errorf("Expected trace headers to reach the worker, got %v", got)
+	}
+	if got.Get("X-API-Key") != "" {
+		t.Errorf("Expected credentials not to be forwarded to the worker")
+	}
+}
+
+func TestCacheWorkerMetadataRetriesLoadingWorker(t *testing.T) {
+	var calls atomic.Int32
+	var loading atomic.Bool
+	loading.Store(true)
+	worker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if loading.Load() {
+			http.Error(w, "loading", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"model":"test-model","quant":"fp16","max_context":4096}`))
+	}))
+	defer worker.Close()
+
+	registry := controlplane.NewRegistry()
+	registry.Set(controlplane.Entry{Model: "test-model", Runtime: "vllm", ServiceURL: worker.URL, Status: "deploying"})
+	CacheMetadataOnReady(context.Background(), registry)
+
+	entry, _ := registry.Get("test-model", "vllm")
+	if entry = cacheWorkerMetadata(context.Background(), registry, entry); entry.MetadataFetched {
+		t.Fatalf("Expected a loading worker's metadata not to be cached, got %+v", entry)
+	}
+	if cacheWorkerMetadata(context.Background(), registry, entry); calls.Load() != 1 {
+		t.Errorf("Expected the fetch not to be retried straight away, got %d calls", calls.Load())
+	}
+
+	// Becoming ready fetches the metadata again in the background
+	loading.Store(false)
+	registry.SetStatus("test-model", "vllm", "ready")
+	deadline := time.Now().Add(time.Second)
+	for {
+		entry, _ = registry.Get("test-model", "vllm")
+		if entry.MetadataFetched || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !entry.MetadataFetched || entry.MaxContext != 4096 {
+		t.Errorf("Expected the metadata to be cached once the worker is ready, got %+v", entry)
+	}
+}
@@ -9,8 +9,23 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/tokenforge/llm-infra-bench/api/handlers"
+	"github.com/tokenforge/llm-infra-bench/events"
 )
 
+// defaultStreamDrainTimeout is how long shutdown waits for streams when STREAM_DRAIN_TIMEOUT is unset
+const defaultStreamDrainTimeout = 30 * time.Second
+
+// streamDrainTimeout reads how long active SSE streams may run during shutdown
+func streamDrainTimeout() time.Duration {
+	timeout, err := time.ParseDuration(os.Getenv("STREAM_DRAIN_TIMEOUT"))
+	if err != nil || timeout < 0 {
+		return defaultStreamDrainTimeout
+	}
+	return timeout
+}
+
 func main() {
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -38,6 +53,13 @@ func main() {
 	<-quit
 	log.Println("Shutting down server...")
 
+	// Let active inference streams finish before the server stops serving them
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), streamDrainTimeout())
+	if err := handlers.DrainStreams(drainCtx); err != nil {
+		log.Printf("Closing inference streams: %v", err)
+	}
+	cancelDrain()
+
 	// Create context with timeout for shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -47,5 +69,9 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	if err := events.Close(); err != nil {
+		log.Printf("Failed to flush events: %v", err)
+	}
+
 	log.Println("Server exited")
 }
@@ -5,6 +5,8 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -12,30 +14,115 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/tokenforge/llm-infra-bench/api/handlers"
+	"github.com/tokenforge/llm-infra-bench/auth"
 	"github.com/tokenforge/llm-infra-bench/controlplane"
+	"github.com/tokenforge/llm-infra-bench/controlplane/k8s"
 	"github.com/tokenforge/llm-infra-bench/db"
+	"github.com/tokenforge/llm-infra-bench/events"
 )
 
+// defaultCompressionLevel is the gzip level used when COMPRESSION_LEVEL is unset
+const defaultCompressionLevel = 5
+
+// compressibleContentTypes lists the response types that are gzip-compressed.
+// text/event-stream is deliberately absent so SSE responses are flushed unbuffered.
+var compressibleContentTypes = []string{
+	"application/json",
+	"text/html",
+	"text/csv",
+	"text/plain",
+}
+
+// compressionLevel reads the gzip compression level from COMPRESSION_LEVEL
+func compressionLevel() int {
+	level, err := strconv.Atoi(os.Getenv("COMPRESSION_LEVEL"))
+	if err != nil || level < 1 || level > 9 {
+		return defaultCompressionLevel
+	}
+	return level
+}
+
+// syncConfigMap mirrors the CONFIG_CONFIGMAP ConfigMap into a local directory and returns it,
+// falling back to the file-based fallbackPath when the ConfigMap cannot be read
+func syncConfigMap(ctx context.Context, fallbackPath string) string {
+	name := os.Getenv("CONFIG_CONFIGMAP")
+	if name == "" {
+		name = "tokenforge-config"
+	}
+	namespace := os.Getenv("CONFIG_NAMESPACE")
+	if namespace == "" {
+		namespace = k8s.DefaultNamespace
+	}
+	dir := filepath.Join(os.TempDir(), "tokenforge-config")
+
+	if err := k8s.SyncConfigMap(ctx, namespace, name, dir); err != nil {
+		log.Printf("Warning: Failed to load config from config map, using %s: %v", fallbackPath, err)
+		return fallbackPath
+	}
+	log.Printf("Loading config from config map %s/%s", namespace, name)
+	return dir
+}
+
 func setupRouter() http.Handler {
 	r := chi.NewRouter()
 
 	// Create registry
 	registry := controlplane.NewRegistry()
 
-	// Create DB client
+	// Create DB client. Without one the API runs in inference-only mode: deploy, infer and
+	// config endpoints work, while benchmark and inference history endpoints are disabled.
 	ctx := context.Background()
-	dbClient, err := db.NewClient(ctx)
-	if err != nil {
+	var dbClient *db.Client
+	if os.Getenv("INFERENCE_ONLY") == "true" {
+		log.Printf("INFERENCE_ONLY is set, not connecting to a database")
+	} else if client, err := db.NewClient(ctx); err != nil {
 		log.Printf("Warning: Failed to connect to database: %v", err)
-		dbClient = nil
+	} else {
+		dbClient = client
+	}
+	if dbClient == nil {
+		log.Printf("Running in inference-only mode: benchmark, leaderboard and inference history endpoints are disabled")
+	}
+
+	// Publish lifecycle events when a broker is configured
+	publisher, err := events.NewPublisherFromEnv()
+	if err != nil {
+		log.Printf("Warning: Failed to set up event publisher: %v", err)
+	} else {
+		events.SetDefault(publisher)
 	}
 
+	// Fail runs orphaned by a previous crash
+	handlers.StartStaleRunSweeper(ctx, dbClient)
+
+	// Track worker queue depths for routing and autoscaling
+	handlers.StartQueueDepthScraper(ctx, registry)
+
+	// Cache each worker's reported context window once it becomes ready
+	handlers.CacheMetadataOnReady(ctx, registry)
+
+	// Drive worker readiness from one shared Deployment watch. Deploys made before it is up,
+	// or when no watch can be established, poll their deployment instead.
+	go func() {
+		if err := controlplane.StartReadinessWatcher(ctx, registry, k8s.DefaultNamespace); err != nil {
+			log.Printf("Warning: Readiness watcher unavailable, polling deployments instead: %v", err)
+			return
+		}
+		log.Printf("Watching worker deployments in %s for readiness", k8s.DefaultNamespace)
+	}()
+
 	// Config path
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
 		configPath = "configs"
 	}
 
+	// In-cluster deployments can source config from a ConfigMap instead of the image
+	if os.Getenv("CONFIG_SOURCE") == "configmap" {
+		configPath = syncConfigMap(ctx, configPath)
+	}
+	k8s.SetConfigDir(configPath)
+
 	// Middleware
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
@@ -46,8 +133,8 @@ func setupRouter() http.Handler {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5173", "http://localhost:3000"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key", "X-Target-Pod", "X-Runtime", "X-No-Retry"},
+		ExposedHeaders:   []string{"Link", "X-Model", "X-Runtime", "X-Route"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
@@ -61,22 +148,60 @@ func setupRouter() http.Handler {
 		w.Write([]byte(`{"status":"ok"}`))
 	})
 
+	// Authenticate API callers; metrics and health checks stay open for probes and scrapers
+	authenticator, err := auth.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid auth configuration: %v", err)
+	}
+	if keys, ok := authenticator.(*auth.StaticKeys); ok && !keys.Enabled() {
+		log.Printf("Warning: API_KEYS is not set, API requests are not authenticated")
+	}
+
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Post("/deploy", handlers.DeployHandler(registry))
+		r.Use(auth.Middleware(authenticator))
+		r.Use(middleware.Compress(compressionLevel(), compressibleContentTypes...))
+
+		r.Post("/deploy", handlers.DeployHandler(registry, dbClient, configPath))
+		r.Post("/deploy/preflight", handlers.DeployPreflightHandler(registry, configPath))
 		r.Get("/deployments", handlers.DeploymentsHandler(registry))
-		r.Get("/deployments/{model}/{runtime}", handlers.DeploymentStatusHandler(registry))
-		r.Post("/infer", handlers.InferHandler(registry))
+		r.Get("/deployments/drift", handlers.DeploymentDriftHandler())
+		r.Delete("/deployments/{model}", handlers.ModelTeardownHandler(registry, dbClient, configPath))
+		r.Get("/deployments/{model}/{runtime}", handlers.DeploymentStatusHandler(registry, configPath))
+		r.With(handlers.RequireDatabase(dbClient, "inference history")).Get("/deployments/{model}/{runtime}/inferences", handlers.RecentInferencesHandler(dbClient, configPath))
+		r.Get("/deployments/{model}/{runtime}/usage", handlers.DeploymentUsageHandler(registry, configPath))
+		r.Get("/deployments/{model}/{runtime}/k8s-status", handlers.DeploymentK8sStatusHandler(registry, configPath))
+		r.Post("/deployments/{model}/{runtime}/pause", handlers.DeploymentPauseHandler(registry, dbClient, configPath, true))
+		r.Post("/deployments/{model}/{runtime}/resume", handlers.DeploymentPauseHandler(registry, dbClient, configPath, false))
+		r.Post("/deployments/{model}/{runtime}/restart", handlers.DeploymentRestartHandler(registry, dbClient, configPath))
+		r.Post("/deployments/{model}/{runtime}/warmup", handlers.DeploymentWarmupHandler(registry, configPath))
+		r.Post("/infer", handlers.InferHandler(registry, dbClient, configPath))
+		r.With(handlers.RequireDatabase(dbClient, "inference stats")).Get("/inferences/stats", handlers.InferenceStatsHandler(dbClient))
+		r.Get("/metrics/stream", handlers.MetricsStreamHandler(dbClient))
+		r.Get("/system/health", handlers.SystemHealthHandler(dbClient, configPath))
 
 		r.Route("/benchmarks", func(r chi.Router) {
-			r.Post("/run", handlers.BenchmarkRunHandler(dbClient))
-			r.Get("/run/{id}", handlers.BenchmarkStatusHandler(dbClient))
-			r.Get("/runs", handlers.BenchmarkRunsHandler(dbClient))
-			r.Get("/report/{id}", handlers.BenchmarkReportHandler(dbClient))
+			r.Post("/estimate", handlers.BenchmarkEstimateHandler(configPath))
+
+			r.Group(func(r chi.Router) {
+				r.Use(handlers.RequireDatabase(dbClient, "benchmarks"))
+				r.Post("/run", handlers.BenchmarkRunHandler(dbClient, configPath))
+				r.Get("/run/{id}", handlers.BenchmarkStatusHandler(dbClient))
+				r.Get("/run/{id}/artifacts.zip", handlers.BenchmarkArtifactsZipHandler(dbClient))
+				r.Post("/run/{id}/baseline", handlers.BenchmarkBaselineHandler(dbClient))
+				r.Post("/run/{id}/regression", handlers.BenchmarkRegressionHandler(dbClient))
+				r.Get("/runs", handlers.BenchmarkRunsHandler(dbClient))
+				r.Get("/report/{id}.md", handlers.BenchmarkMarkdownReportHandler(dbClient))
+				r.Get("/report/{id}", handlers.BenchmarkReportHandler(dbClient))
+			})
 		})
 
+		r.With(handlers.RequireDatabase(dbClient, "leaderboard")).Get("/leaderboard", handlers.LeaderboardHandler(dbClient))
+
 		r.Get("/models", handlers.ModelsHandler(configPath))
+		r.Get("/models/status", handlers.ModelsStatusHandler(registry, configPath))
 		r.Get("/runtimes", handlers.RuntimesHandler(configPath))
+		r.Get("/runtimes/{name}/images", handlers.RuntimeImagesHandler(configPath))
 	})
 
 	return r
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ErrUnauthenticated is wrapped by every error returned for a request without valid credentials
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	// Subject identifies the caller in audit logs, e.g. "key:ci" or "jwt:alice"
+	Subject string `json:"subject"`
+	// Method is the scheme that authenticated the caller: static, jwt or anonymous
+	Method string `json:"method"`
+}
+
+// Authenticator verifies the credentials on a request
+type Authenticator interface {
+	Authenticate(r *http.Request) (Identity, error)
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the identity
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity attached by Middleware, if any
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// Middleware rejects requests the authenticator does not accept with 401 and attaches the
+// identity of accepted requests to their context
+func Middleware(a Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, err := a.Authenticate(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+		})
+	}
+}
+
+// Anonymous accepts every request, identifying callers by their address
+type Anonymous struct{}
+
+// Authenticate always succeeds
+func (Anonymous) Authenticate(r *http.Request) (Identity, error) {
+	return Identity{Subject: "anonymous@" + r.RemoteAddr, Method: "anonymous"}, nil
+}
+
+// APIKey returns the key sent in X-API-Key or as an Authorization bearer token
+func APIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return bearerToken(r)
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// Fingerprint identifies a secret by a short hash so the secret itself never reaches the logs
+func Fingerprint(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// NewFromEnv builds the authenticator selected by AUTH_MODE: "static" (the default) checks
+// the keys in API_KEYS, "jwt" verifies bearer tokens as configured by the AUTH_JWT_*
+// variables, and "none" accepts every request
+func NewFromEnv() (Authenticator, error) {
+	switch mode := os.Getenv("AUTH_MODE"); mode {
+	case "", "static":
+		return NewStaticKeys(ParseStaticKeys(os.Getenv("API_KEYS"))), nil
+	case "jwt":
+		return NewJWT(JWTConfig{
+			Secret:   os.Getenv("AUTH_JWT_SECRET"),
+			JWKSURL:  os.Getenv("AUTH_JWT_JWKS_URL"),
+			Issuer:   os.Getenv("AUTH_JWT_ISSUER"),
+			Audience: os.Getenv("AUTH_JWT_AUDIENCE"),
+		})
+	case "none":
+		return Anonymous{}, nil
+	default:
+		return nil, fmt.Errorf("unknown AUTH_MODE %q: must be static, jwt or none", mode)
+	}
+}
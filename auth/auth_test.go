@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to encode token segment: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	input := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	input := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func requestWithHeader(name, value string) *http.Request {
+	r := httptest.NewRequest("GET", "/api/v1/deployments", nil)
+	if name != "" {
+		r.Header.Set(name, value)
+	}
+	return r
+}
+
+func TestStaticKeys(t *testing.T) {
+	a := NewStaticKeys(ParseStaticKeys("ci:secret-one, secret-two"))
+
+	identity, err := a.Authenticate(requestWithHeader("X-API-Key", "secret-one"))
+	if err != nil || identity.Subject != "key:ci" {
+		t.Errorf("Expected named key to authenticate as key:ci, got %+v, %v", identity, err)
+	}
+	identity, err = a.Authenticate(requestWithHeader("Authorization", "Bearer secret-two"))
+	if err != nil || identity.Subject != "key:"+Fingerprint("secret-two") {
+		t.Errorf("Expected unnamed key to authenticate by fingerprint, got %+v, %v", identity, err)
+	}
+	if _, err := a.Authenticate(requestWithHeader("X-API-Key", "wrong")); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Expected invalid key to be rejected, got %v", err)
+	}
+	if _, err := a.Authenticate(requestWithHeader("", "")); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Expected missing key to be rejected, got %v", err)
+	}
+
+	open := NewStaticKeys(ParseStaticKeys(""))
+	if identity, err := open.Authenticate(requestWithHeader("", "")); err != nil || identity.Method != "anonymous" {
+		t.Errorf("Expected no configured keys to accept requests anonymously, got %+v, %v", identity, err)
+	}
+}
+
+func TestJWTHS256(t *testing.T) {
+	a, err := NewJWT(JWTConfig{Secret: "shh", Issuer: "tokenforge", Audience: "api"})
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+	now := time.Now()
+	valid := map[string]interface{}{"sub": "alice", "iss": "tokenforge", "aud": []string{"api"}, "exp": now.Add(time.Hour).Unix()}
+
+	identity, err := a.Authenticate(requestWithHeader("Authorization", "Bearer "+signHS256(t, "shh", valid)))
+	if err != nil || identity.Subject != "jwt:alice" {
+		t.Errorf("Expected valid token to authenticate as jwt:alice, got %+v, %v", identity, err)
+	}
+
+	expired := map[string]interface{}{"sub": "alice", "iss": "tokenforge", "aud": "api", "exp": now.Add(-time.Hour).Unix()}
+	wrongAudience := map[string]interface{}{"sub": "alice", "iss": "tokenforge", "aud": "other"}
+	unsigned := encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, valid) + "."
+
+	rejected := map[string]string{
+		"expired":        signHS256(t, "shh", expired),
+		"wrong audience": signHS256(t, "shh", wrongAudience),
+		"wrong secret":   signHS256(t, "other", valid),
+		"alg none":       unsigned,
+		"malformed":      "not-a-token",
+	}
+	for name, token := range rejected {
+		if _, err := a.Authenticate(requestWithHeader("Authorization", "Bearer "+token)); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("Expected %s token to be rejected, got %v", name, err)
+		}
+	}
+}
+
+func TestJWTRS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	}))
+	defer jwks.Close()
+
+	a, err := NewJWT(JWTConfig{JWKSURL: jwks.URL})
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+	claims := map[string]interface{}{"sub": "svc-bench"}
+
+	identity, err := a.Authenticate(requestWithHeader("Authorization", "Bearer "+signRS256(t, key, "k1", claims)))
+	if err != nil || identity.Subject != "jwt:svc-bench" {
+		t.Errorf("Expected RS256 token to authenticate, got %+v, %v", identity, err)
+	}
+	if _, err := a.Authenticate(requestWithHeader("Authorization", "Bearer "+signHS256(t, "shh", claims))); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Expected HS256 token to be rejected without a secret, got %v", err)
+	}
+}
+
+func TestMiddlewareAttachesIdentity(t *testing.T) {
+	var got Identity
+	handler := Middleware(NewStaticKeys(ParseStaticKeys("ci:secret")))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = FromContext(r.Context())
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, requestWithHeader("X-API-Key", "secret"))
+	if rr.Code != http.StatusOK || got.Subject != "key:ci" {
+		t.Errorf("Expected request to pass with identity key:ci, got %d and %+v", rr.Code, got)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, requestWithHeader("X-API-Key", "wrong"))
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("Expected invalid key to return 401, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksTTL is how long fetched signing keys are trusted before they are refreshed
+	jwksTTL = time.Hour
+	// jwksMinRefresh limits refetches triggered by tokens signed with an unknown key id
+	jwksMinRefresh = time.Minute
+	// jwksFetchTimeout bounds a single JWKS or discovery request
+	jwksFetchTimeout = 10 * time.Second
+)
+
+// jwksCache fetches RSA signing keys from a JWKS document and caches them by key id
+type jwksCache struct {
+	url string
+	// discover marks url as an OpenID Connect discovery document naming the JWKS URL
+	discover bool
+	client   *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+func newJWKSCache(url string, discover bool) *jwksCache {
+	return &jwksCache{
+		url:      url,
+		discover: discover,
+		client:   &http.Client{Timeout: jwksFetchTimeout},
+	}
+}
+
+// key returns the signing key for a key id, refreshing the cache when it is stale or the
+// id is unknown, e.g. after the issuer rotated its keys
+func (c *jwksCache) key(kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	age := time.Since(c.fetchedAt)
+	key, found := c.lookup(kid)
+	if (!found && age >= jwksMinRefresh) || age >= jwksTTL {
+		if err := c.refresh(); err != nil {
+			if found {
+				return key, nil
+			}
+			return nil, err
+		}
+		key, found = c.lookup(kid)
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// lookup finds a cached key; tokens without a key id match when only one key is published
+func (c *jwksCache) lookup(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, found := c.keys[kid]
+	return key, found
+}
+
+// refresh refetches the key set; the caller must hold the lock
+func (c *jwksCache) refresh() error {
+	c.fetchedAt = time.Now()
+
+	url := c.url
+	if c.discover {
+		var discovery struct {
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := c.getJSON(url, &discovery); err != nil {
+			return fmt.Errorf("failed to discover JWKS URL: %w", err)
+		}
+		if discovery.JWKSURI == "" {
+			return errors.New("OpenID configuration has no jwks_uri")
+		}
+		url = discovery.JWKSURI
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := c.getJSON(url, &set); err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := parseRSAKey(jwk.N, jwk.E)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	c.keys = keys
+	return nil
+}
+
+// getJSON fetches a URL and decodes its JSON body
+func (c *jwksCache) getJSON(url string, v interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// parseRSAKey builds an RSA public key from base64url-encoded modulus and exponent
+func parseRSAKey(n, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	if len(modulus) == 0 || len(exponent) == 0 || len(exponent) > 4 {
+		return nil, errors.New("invalid RSA key")
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// clockSkew is how far exp and nbf may be off before a token is rejected
+const clockSkew = 30 * time.Second
+
+// JWTConfig configures bearer token verification. Secret enables HS256; JWKSURL enables
+// RS256 with keys fetched from a JWKS document. Setting only Issuer discovers the JWKS URL
+// from the issuer's OpenID Connect configuration.
+type JWTConfig struct {
+	Secret  string
+	JWKSURL string
+	// Issuer and Audience, when set, must match the token's iss and aud claims
+	Issuer   string
+	Audience string
+}
+
+// JWT authenticates requests carrying a signed JSON Web Token as a bearer token
+type JWT struct {
+	secret   []byte
+	keys     *jwksCache
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWT creates a JWT authenticator
+func NewJWT(cfg JWTConfig) (*JWT, error) {
+	a := &JWT{
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		now:      time.Now,
+	}
+	if cfg.Secret != "" {
+		a.secret = []byte(cfg.Secret)
+	}
+
+	jwksURL := cfg.JWKSURL
+	if jwksURL == "" && cfg.Secret == "" && cfg.Issuer != "" {
+		jwksURL = strings.TrimSuffix(cfg.Issuer, "/") + "/.well-known/openid-configuration"
+		a.keys = newJWKSCache(jwksURL, true)
+	} else if jwksURL != "" {
+		a.keys = newJWKSCache(jwksURL, false)
+	}
+
+	if a.secret == nil && a.keys == nil {
+		return nil, errors.New("jwt auth needs AUTH_JWT_SECRET, AUTH_JWT_JWKS_URL or AUTH_JWT_ISSUER")
+	}
+	return a, nil
+}
+
+// jwtHeader is the decoded JOSE header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtClaims are the registered claims checked on every token
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+}
+
+// audience decodes the aud claim, which may be a single string or a list
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// Authenticate verifies the bearer token's signature and claims
+func (a *JWT) Authenticate(r *http.Request) (Identity, error) {
+	token := bearerToken(r)
+	if token == "" {
+		return Identity{}, fmt.Errorf("%w: missing bearer token", ErrUnauthenticated)
+	}
+	claims, err := a.verify(token)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return Identity{Subject: "jwt:" + claims.Subject, Method: "jwt"}, nil
+}
+
+// verify checks a compact-serialized token and returns its claims
+func (a *JWT) verify(token string) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("invalid token signature encoding")
+	}
+	if err := a.verifySignature(header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+	if err := a.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// verifySignature checks the signature with the key for the token's algorithm. Only HS256
+// and RS256 are accepted, so unsigned "none" tokens are always rejected.
+func (a *JWT) verifySignature(header jwtHeader, signingInput string, signature []byte) error {
+	switch header.Alg {
+	case "HS256":
+		if a.secret == nil {
+			return errors.New("HS256 tokens are not accepted")
+		}
+		mac := hmac.New(sha256.New, a.secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	case "RS256":
+		if a.keys == nil {
+			return errors.New("RS256 tokens are not accepted")
+		}
+		key, err := a.keys.key(header.Kid)
+		if err != nil {
+			return err
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+}
+
+// validateClaims checks the subject, expiry, not-before, issuer and audience claims
+func (a *JWT) validateClaims(claims *jwtClaims) error {
+	now := a.now()
+	if claims.Subject == "" {
+		return errors.New("token has no subject")
+	}
+	if claims.ExpiresAt != nil && now.After(time.Unix(*claims.ExpiresAt, 0).Add(clockSkew)) {
+		return errors.New("token has expired")
+	}
+	if claims.NotBefore != nil && now.Add(clockSkew).Before(time.Unix(*claims.NotBefore, 0)) {
+		return errors.New("token is not valid yet")
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return fmt.Errorf("unexpected token issuer %q", claims.Issuer)
+	}
+	if a.audience != "" {
+		for _, aud := range claims.Audience {
+			if aud == a.audience {
+				return nil
+			}
+		}
+		return errors.New("token is not issued for this audience")
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// StaticKeys authenticates requests against a fixed set of API keys. With no keys configured
+// every request is accepted anonymously, so deployments without auth keep working.
+type StaticKeys struct {
+	// keys maps each key to the name it is audited under
+	keys map[string]string
+}
+
+// NewStaticKeys creates an authenticator for keys mapped to their names
+func NewStaticKeys(keys map[string]string) *StaticKeys {
+	return &StaticKeys{keys: keys}
+}
+
+// ParseStaticKeys parses a comma-separated list of keys, each optionally prefixed with a
+// name as "name:key". Unnamed keys are audited by their fingerprint.
+func ParseStaticKeys(spec string) map[string]string {
+	keys := make(map[string]string)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if name, key, ok := strings.Cut(item, ":"); ok && name != "" && key != "" {
+			keys[key] = name
+			continue
+		}
+		keys[item] = Fingerprint(item)
+	}
+	return keys
+}
+
+// Enabled reports whether any keys are configured
+func (s *StaticKeys) Enabled() bool {
+	return len(s.keys) > 0
+}
+
+// Authenticate checks the request's API key against every configured key
+func (s *StaticKeys) Authenticate(r *http.Request) (Identity, error) {
+	if !s.Enabled() {
+		return Anonymous{}.Authenticate(r)
+	}
+
+	key := APIKey(r)
+	if key == "" {
+		return Identity{}, fmt.Errorf("%w: missing API key", ErrUnauthenticated)
+	}
+
+	// Compare against every key so the time taken does not reveal which one nearly matched
+	name := ""
+	for candidate, candidateName := range s.keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			name = candidateName
+		}
+	}
+	if name == "" {
+		return Identity{}, fmt.Errorf("%w: invalid API key", ErrUnauthenticated)
+	}
+	return Identity{Subject: "key:" + name, Method: "static"}, nil
+}
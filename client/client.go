@@ -0,0 +1,225 @@
+// Package client is a typed Go client for the TokenForge REST API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTimeout bounds requests when no timeout or HTTP client is configured
+const defaultTimeout = 60 * time.Second
+
+// maxErrorBody limits how much of an error response is read into an APIError
+const maxErrorBody = 64 << 10
+
+// APIError is returned when the API responds with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("tokenforge: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Client calls the TokenForge API
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithAPIKey authenticates every request with an API key sent as X-API-Key
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithTimeout sets the timeout for each request. Streaming requests are bounded by their
+// context instead, since a stream can legitimately outlast any fixed timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithHTTPClient replaces the underlying HTTP client, e.g. to configure TLS or a proxy
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New creates a client for the API served at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// newRequest builds a request for an API path, encoding body as JSON when it is not nil
+func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/api/v1"+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	return req, nil
+}
+
+// do sends a request and decodes a successful JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// checkResponse turns a non-2xx response into an APIError. The API reports most errors as
+// plain text and worker errors as JSON with an "error" field.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	message := strings.TrimSpace(string(data))
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && body.Error != "" {
+		message = body.Error
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: message}
+}
+
+// Deploy deploys a model with a runtime
+func (c *Client) Deploy(ctx context.Context, req DeployRequest) (*DeployResponse, error) {
+	var resp DeployResponse
+	if err := c.do(ctx, http.MethodPost, "/deploy", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Infer runs a single non-streaming inference
+func (c *Client) Infer(ctx context.Context, req InferRequest) (*InferResponse, error) {
+	req.Stream = false
+	var resp InferResponse
+	if err := c.do(ctx, http.MethodPost, "/infer", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RunBenchmark starts a benchmark run
+func (c *Client) RunBenchmark(ctx context.Context, req BenchmarkRequest) (*BenchmarkRun, error) {
+	var resp BenchmarkRun
+	if err := c.do(ctx, http.MethodPost, "/benchmarks/run", req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetRun returns the status of a benchmark run
+func (c *Client) GetRun(ctx context.Context, id string) (*Run, error) {
+	var resp Run
+	if err := c.do(ctx, http.MethodGet, "/benchmarks/run/"+url.PathEscape(id), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ListRuns lists benchmark runs
+func (c *Client) ListRuns(ctx context.Context) ([]RunSummary, error) {
+	var resp []RunSummary
+	if err := c.do(ctx, http.MethodGet, "/benchmarks/runs", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListRunsPage lists up to limit benchmark runs, newest first, starting after the run with ID
+// after. An empty after starts from the newest run.
+func (c *Client) ListRunsPage(ctx context.Context, after string, limit int) (*RunsPage, error) {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(limit))
+	if after != "" {
+		query.Set("after", after)
+	}
+	var resp RunsPage
+	if err := c.do(ctx, http.MethodGet, "/benchmarks/runs?"+query.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SetBaseline makes a completed run the baseline of its model
+func (c *Client) SetBaseline(ctx context.Context, runID string) (*Baseline, error) {
+	var resp Baseline
+	if err := c.do(ctx, http.MethodPost, "/benchmarks/run/"+url.PathEscape(runID)+"/baseline", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CheckRegression compares a completed run to its model's baseline. Thresholds map metrics to
+// the regression they may show, in percent of the baseline; nil uses the server's defaults.
+func (c *Client) CheckRegression(ctx context.Context, runID string, thresholds map[string]float64) (*RegressionReport, error) {
+	var body interface{}
+	if thresholds != nil {
+		body = map[string]interface{}{"thresholds": thresholds}
+	}
+	var resp RegressionReport
+	if err := c.do(ctx, http.MethodPost, "/benchmarks/run/"+url.PathEscape(runID)+"/regression", body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -0,0 +1,252 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeploySendsAPIKeyAndDecodesResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/deploy" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("X-API-Key"); got != "secret" {
+			t.Errorf("Expected API key header, got %q", got)
+		}
+		var req DeployRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "test-model" || req.Runtime != "vllm" || req.Mem != "64Gi" {
+			t.Errorf("Unexpected deploy request %+v", req)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":  req.Model,
+			"status": "deploying",
+			"k8s":    map[string]string{"deployment": "worker-vllm-test-model"},
+		})
+	}))
+	defer server.Close()
+
+	c := New(server.URL+"/", WithAPIKey("secret"))
+	resp, err := c.Deploy(context.Background(), DeployRequest{Model: "test-model", Runtime: "vllm", Mem: "64Gi"})
+	if err != nil {
+		t.Fatalf("Deploy failed: %v", err)
+	}
+	if resp.Status != "deploying" || resp.K8s.Deployment != "worker-vllm-test-model" {
+		t.Errorf("Unexpected deploy response %+v", resp)
+	}
+}
+
+func TestErrorsAreReturnedAsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/benchmarks/run/missing":
+			http.Error(w, "run not found", http.StatusNotFound)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`{"error":"worker unavailable"}`))
+		}
+	}))
+	defer server.Close()
+	c := New(server.URL)
+
+	_, err := c.GetRun(context.Background(), "missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "run not found" {
+		t.Errorf("Expected a 404 APIError, got %v", err)
+	}
+
+	_, err = c.Infer(context.Background(), InferRequest{Model: "m", Prompt: "hi"})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway || apiErr.Message != "worker unavailable" {
+		t.Errorf("Expected a 502 APIError with the worker error, got %v", err)
+	}
+}
+
+func TestBenchmarkRunsRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/benchmarks/run":
+			var req BenchmarkRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if len(req.Workloads) != 1 || req.Workloads[0].Ramp == nil {
+				t.Errorf("Unexpected benchmark request %+v", req)
+			}
+			w.Write([]byte(`{"id":"run_000001","status":"queued","trace_id":"abc"}`))
+		case "GET /api/v1/benchmarks/run/run_000001":
+			w.Write([]byte(`{"id":"run_000001","status":"running","summary":{"model":"m","runtimes":["vllm"]}}`))
+		case "GET /api/v1/benchmarks/runs":
+			if r.URL.Query().Get("limit") == "" {
+				w.Write([]byte(`[{"id":"run_000001","model":"m","status":"running"}]`))
+				return
+			}
+			if r.URL.Query().Get("after") != "run_000002" || r.URL.Query().Get("limit") != "1" {
+				t.Errorf("Unexpected page query %q", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"runs":[{"id":"run_000001","model":"m","status":"running"}],"next_cursor":"run_000001"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	c := New(server.URL)
+	ctx := context.Background()
+
+	run, err := c.RunBenchmark(ctx, BenchmarkRequest{
+		Model:     "m",
+		Runtimes:  []string{"vllm"},
+		Workloads: []Workload{{Name: "ramp", Ramp: &Ramp{StartQPS: 1, EndQPS: 4, Step: 1, StepDurationS: 30}}},
+	})
+	if err != nil || run.ID != "run_000001" || run.TraceID != "abc" {
+		t.Fatalf("Unexpected run %+v, %v", run, err)
+	}
+
+	status, err := c.GetRun(ctx, run.ID)
+	if err != nil || status.Status != "running" || status.Summary.Model != "m" {
+		t.Errorf("Unexpected run status %+v, %v", status, err)
+	}
+
+	runs, err := c.ListRuns(ctx)
+	if err != nil || len(runs) != 1 || runs[0].ID != "run_000001" {
+		t.Errorf("Unexpected run list %+v, %v", runs, err)
+	}
+
+	page, err := c.ListRunsPage(ctx, "run_000002", 1)
+	if err != nil || len(page.Runs) != 1 || page.NextCursor != "run_000001" {
+		t.Errorf("Unexpected run page %+v, %v", page, err)
+	}
+}
+
+func TestInferStreamDeliversEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req InferRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Errorf("Expected a streaming request")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i, token := range []string{"hello", "world"} {
+			fmt.Fprintf(w, "data: {\"token\":%q,\"index\":%d,\"is_last\":%t}\n\n", token, i, i == 1)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer server.Close()
+
+	events, err := New(server.URL, WithTimeout(time.Second)).InferStream(context.Background(), InferRequest{Model: "m", Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("InferStream failed: %v", err)
+	}
+	var tokens []string
+	for event := range events {
+		if event.Err != nil {
+			t.Fatalf("Unexpected stream error: %v", event.Err)
+		}
+		tokens = append(tokens, event.Token)
+	}
+	if len(tokens) != 2 || tokens[0] != "hello" || tokens[1] != "world" {
+		t.Errorf("Expected both tokens, got %v", tokens)
+	}
+}
+
+func TestInferStreamReportsErrorEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"token\":\"a\",\"index\":0,\"is_last\":false}\n\n")
+		fmt.Fprint(w, "data: {\"error\":\"CUDA out of memory\"}\n\n")
+	}))
+	defer server.Close()
+
+	events, err := New(server.URL).InferStream(context.Background(), InferRequest{Model: "m", Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("InferStream failed: %v", err)
+	}
+	var last StreamEvent
+	count := 0
+	for event := range events {
+		last = event
+		count++
+	}
+	if count != 2 || last.Err == nil || last.Err.Error() != "CUDA out of memory" {
+		t.Errorf("Expected the stream to end with the worker error, got %d events ending in %+v", count, last)
+	}
+}
+
+func TestInferStreamStopsOnCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; ; i++ {
+			if _, err := fmt.Fprintf(w, "data: {\"token\":\"t\",\"index\":%d,\"is_last\":false}\n\n", i); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := New(server.URL).InferStream(ctx, InferRequest{Model: "m", Prompt: "hi"})
+	if err != nil {
+		t.Fatalf("InferStream failed: %v", err)
+	}
+	<-events
+	cancel()
+
+	select {
+	case <-drain(events):
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the event channel to close after cancellation")
+	}
+}
+
+// drain reads a channel until it is closed
+func drain(events <-chan StreamEvent) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for range events {
+		}
+		close(done)
+	}()
+	return done
+}
+
+func TestBaselineAndRegression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/benchmarks/run/run_000001/baseline":
+			w.Write([]byte(`{"model":"m","run_id":"run_000001","updated_at":"2025-01-01T00:00:00Z"}`))
+		case "POST /api/v1/benchmarks/run/run_000002/regression":
+			var req map[string]map[string]float64
+			json.NewDecoder(r.Body).Decode(&req)
+			if req["thresholds"]["p95_latency_ms"] != 5 {
+				t.Errorf("Unexpected regression request %v", req)
+			}
+			w.Write([]byte(`{"run_id":"run_000002","baseline_run_id":"run_000001","model":"m","passed":false,
+				"checks":[{"metric":"p95_latency_ms","baseline":100,"current":120,"regression_pct":20,"threshold_pct":5,"passed":false}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	c := New(server.URL)
+	ctx := context.Background()
+
+	baseline, err := c.SetBaseline(ctx, "run_000001")
+	if err != nil || baseline.Model != "m" || baseline.RunID != "run_000001" {
+		t.Fatalf("Unexpected baseline %+v, %v", baseline, err)
+	}
+
+	report, err := c.CheckRegression(ctx, "run_000002", map[string]float64{"p95_latency_ms": 5})
+	if err != nil || report.Passed || len(report.Checks) != 1 || *report.Checks[0].RegressionPct != 20 {
+		t.Errorf("Unexpected regression report %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxStreamLine bounds a single server-sent event line
+const maxStreamLine = 1 << 20
+
+// StreamEvent is one event of a streaming inference. Err is set on the last event when the
+// stream failed; the channel is closed after it.
+type StreamEvent struct {
+	Token  string `json:"token"`
+	Index  int    `json:"index"`
+	IsLast bool   `json:"is_last"`
+	Err    error  `json:"-"`
+}
+
+// InferStream runs a streaming inference and returns a channel of its events. The channel is
+// closed when the stream ends, fails or ctx is cancelled; cancel ctx to stop reading early.
+func (c *Client) InferStream(ctx context.Context, req InferRequest) (<-chan StreamEvent, error) {
+	req.Stream = true
+	httpReq, err := c.newRequest(ctx, http.MethodPost, "/infer", req)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	// The per-request timeout would cut long streams off, so streams are bounded by ctx only
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	events := make(chan StreamEvent)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		send := func(event StreamEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64<<10), maxStreamLine)
+		eventType := ""
+		for scanner.Scan() {
+			line := scanner.Text()
+			if name, ok := strings.CutPrefix(line, "event:"); ok {
+				eventType = strings.TrimSpace(name)
+				continue
+			}
+			data, ok := strings.CutPrefix(line, "data:")
+			if !ok {
+				if line == "" {
+					eventType = ""
+				}
+				continue
+			}
+			if eventType == "shutdown" {
+				send(StreamEvent{Err: errors.New("tokenforge: server shut down during the stream")})
+				return
+			}
+
+			var event struct {
+				StreamEvent
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+				send(StreamEvent{Err: fmt.Errorf("failed to decode stream event: %w", err)})
+				return
+			}
+			if event.Error != "" {
+				send(StreamEvent{Err: errors.New(event.Error)})
+				return
+			}
+			if !send(event.StreamEvent) || event.IsLast {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			send(StreamEvent{Err: err})
+		}
+	}()
+	return events, nil
+}
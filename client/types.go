@@ -0,0 +1,172 @@
+package client
+
+import "time"
+
+// DeployRequest deploys a model with a runtime
+type DeployRequest struct {
+	Model   string `json:"model"`
+	Runtime string `json:"runtime"`
+	// Quant defaults to the model's configured default quant when empty
+	Quant string `json:"quant,omitempty"`
+	// Profile selects a resource profile defined for the runtime
+	Profile string `json:"profile,omitempty"`
+	// GPU, CPU and Mem override the runtime's resources; they require an authenticated caller
+	GPU *int   `json:"gpu,omitempty"`
+	CPU string `json:"cpu,omitempty"`
+	Mem string `json:"mem,omitempty"`
+}
+
+// DeployResponse describes a deployed worker
+type DeployResponse struct {
+	Model      string    `json:"model"`
+	Quant      string    `json:"quant"`
+	Endpoint   string    `json:"endpoint"`
+	Status     string    `json:"status"`
+	DeployedAt time.Time `json:"deployed_at"`
+	ConfigHash string    `json:"config_hash,omitempty"`
+	K8s        struct {
+		Namespace  string `json:"namespace"`
+		Deployment string `json:"deployment"`
+		Service    string `json:"service"`
+	} `json:"k8s"`
+}
+
+// InferRequest is a prompt sent to a deployed model
+type InferRequest struct {
+	Model string `json:"model"`
+	// Runtime may be left empty to use the model's default runtime
+	Runtime     string  `json:"runtime,omitempty"`
+	Prompt      string  `json:"prompt"`
+	MaxTokens   int     `json:"max_tokens,omitempty"`
+	Temperature float64 `json:"temperature,omitempty"`
+	TopP        float64 `json:"top_p,omitempty"`
+	Stream      bool    `json:"stream"`
+	// TimeoutMs sets a server-side deadline for the request, capped by the server
+	TimeoutMs int `json:"timeout_ms,omitempty"`
+}
+
+// InferResponse is the result of a non-streaming inference
+type InferResponse struct {
+	Output      string `json:"output"`
+	LatencyMs   int    `json:"latency_ms"`
+	TokensIn    int    `json:"tokens_in"`
+	TokensOut   int    `json:"tokens_out"`
+	RuntimeMeta struct {
+		Engine  string `json:"engine"`
+		Version string `json:"version"`
+		Cuda    string `json:"cuda"`
+		Gpu     string `json:"gpu"`
+	} `json:"runtime_meta"`
+}
+
+// BenchmarkRequest starts a benchmark run
+type BenchmarkRequest struct {
+	Model    string   `json:"model"`
+	Runtimes []string `json:"runtimes"`
+	// Quants optionally lists the quantizations to benchmark for each runtime
+	Quants    map[string][]string `json:"quants,omitempty"`
+	Workloads []Workload          `json:"workloads"`
+}
+
+// Workload is a single workload in a benchmark run
+type Workload struct {
+	Name      string `json:"name"`
+	QPS       int    `json:"qps,omitempty"`
+	DurationS int    `json:"duration_s,omitempty"`
+	PromptLen int    `json:"prompt_len,omitempty"`
+	GenTokens int    `json:"gen_tokens,omitempty"`
+	Stream    bool   `json:"stream,omitempty"`
+	// Ramp replaces the constant QPS with a stepped load profile
+	Ramp *Ramp `json:"ramp,omitempty"`
+}
+
+// Ramp steps the QPS from StartQPS to EndQPS, holding each step for StepDurationS
+type Ramp struct {
+	StartQPS      int `json:"start_qps"`
+	EndQPS        int `json:"end_qps"`
+	Step          int `json:"step"`
+	StepDurationS int `json:"step_duration_s"`
+}
+
+// BenchmarkRun identifies a started benchmark run
+type BenchmarkRun struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	TraceID string `json:"trace_id"`
+}
+
+// Run is the status of a benchmark run
+type Run struct {
+	ID      string `json:"id"`
+	Status  string `json:"status"`
+	TraceID string `json:"trace_id,omitempty"`
+	Summary struct {
+		Model     string   `json:"model"`
+		Runtimes  []string `json:"runtimes"`
+		Artifacts struct {
+			HTML string `json:"html"`
+			CSV  string `json:"csv"`
+			Raw  string `json:"raw"`
+		} `json:"artifacts"`
+	} `json:"summary"`
+}
+
+// RunSummary is a benchmark run as listed by ListRuns
+type RunSummary struct {
+	ID        string    `json:"id"`
+	Model     string    `json:"model"`
+	Runtimes  []string  `json:"runtimes"`
+	Status    string    `json:"status"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time,omitempty"`
+}
+
+// RunRecord is a benchmark run as stored, returned by ListRunsPage
+type RunRecord struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Model     string    `json:"model"`
+	Runtimes  []string  `json:"runtimes"`
+	TraceID   string    `json:"trace_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RunsPage is a page of benchmark runs. Pass NextCursor as After to fetch the next page; it is
+// empty on the last page.
+type RunsPage struct {
+	Runs       []RunRecord `json:"runs"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// Baseline is the run a model's new runs are compared against for regressions
+type Baseline struct {
+	Model     string    `json:"model"`
+	RunID     string    `json:"run_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RegressionCheck compares one metric of a runtime/quant/workload combination to the baseline.
+// RegressionPct is nil when the baseline value is 0.
+type RegressionCheck struct {
+	Runtime       string   `json:"runtime"`
+	Quant         string   `json:"quant"`
+	Workload      string   `json:"workload"`
+	Metric        string   `json:"metric"`
+	Baseline      float64  `json:"baseline"`
+	Current       float64  `json:"current"`
+	RegressionPct *float64 `json:"regression_pct,omitempty"`
+	ThresholdPct  float64  `json:"threshold_pct"`
+	Passed        bool     `json:"passed"`
+}
+
+// RegressionReport is the result of comparing a run to its model's baseline
+type RegressionReport struct {
+	RunID         string             `json:"run_id"`
+	BaselineRunID string             `json:"baseline_run_id"`
+	Model         string             `json:"model"`
+	Passed        bool               `json:"passed"`
+	Thresholds    map[string]float64 `json:"thresholds"`
+	Checks        []RegressionCheck  `json:"checks"`
+	Unmatched     []string           `json:"unmatched,omitempty"`
+}
@@ -2,10 +2,12 @@ package controlplane
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/tokenforge/llm-infra-bench/controlplane/k8s"
+	"github.com/tokenforge/llm-infra-bench/events"
 )
 
 // Controller manages the deployment and lifecycle of worker instances
@@ -21,36 +23,172 @@ func NewController(registry *Registry) *Controller {
 }
 
 // DeployModel deploys a model with the specified runtime and waits for it to be ready
-func (c *Controller) DeployModel(ctx context.Context, model, runtime, quant string) (string, error) {
+func (c *Controller) DeployModel(ctx context.Context, model, runtime, quant string, opts k8s.DeployOptions) (string, error) {
 	// Check if model is already deployed with this runtime
-	if serviceURL, found := c.registry.Get(model, runtime); found {
+	if entry, found := c.registry.Get(model, runtime); found {
 		// Check if the service is healthy
 		// TODO: Add health check
-		return serviceURL, nil
+		return entry.ServiceURL, nil
 	}
 
 	// Deploy the model
-	serviceURL, namespace, deploymentName, serviceName, err := k8s.DeployWorker(ctx, model, runtime, quant)
+	worker, err := k8s.DeployWorker(ctx, model, runtime, quant, opts)
 	if err != nil {
 		return "", fmt.Errorf("failed to deploy worker: %w", err)
 	}
 
 	// Register the service
-	c.registry.Set(model, runtime, serviceURL)
+	c.registry.Set(Entry{
+		Model:      model,
+		Runtime:    runtime,
+		Quant:      quant,
+		ServiceURL: worker.ServiceURL,
+		Status:     "deploying",
+		Namespace:  worker.Namespace,
+		Deployment: worker.Deployment,
+		Service:    worker.Service,
+		ConfigHash: worker.ConfigHash,
+		Profile:    opts.Profile,
+	})
 
-	// Wait for the service to be ready
-	err = c.waitForReady(ctx, namespace, deploymentName, serviceName)
+	// Wait for the service to be ready. A running readiness watcher marks the entry ready and
+	// publishes the event itself; otherwise the deployment is polled.
+	if watcher := readinessWatcherFor(c.registry); watcher != nil {
+		err = watcher.wait(ctx, model, runtime)
+		if err != nil {
+			c.deployFailed(ctx, model, runtime, err)
+		}
+	} else {
+		err = c.pollReady(ctx, model, runtime, worker.Namespace, worker.Deployment, worker.Service)
+	}
 	if err != nil {
 		return "", fmt.Errorf("deployment failed to become ready: %w", err)
 	}
 
-	return serviceURL, nil
+	return worker.ServiceURL, nil
+}
+
+// AwaitReady moves a deploying entry to ready in the background. A running readiness watcher
+// already drives the entry, so the deployment is only polled when there is none.
+func (c *Controller) AwaitReady(model, runtime string) {
+	if readinessWatcherFor(c.registry) != nil {
+		return
+	}
+	entry, found := c.registry.Get(model, runtime)
+	if !found {
+		return
+	}
+	go c.pollReady(context.Background(), model, runtime, entry.Namespace, entry.Deployment, entry.Service)
+}
+
+// pollReady polls a deployment until it is ready and records the outcome on its entry
+func (c *Controller) pollReady(ctx context.Context, model, runtime, namespace, deploymentName, serviceName string) error {
+	if err := c.waitForReady(ctx, model, runtime, namespace, deploymentName, serviceName); err != nil {
+		c.deployFailed(ctx, model, runtime, err)
+		return err
+	}
+	if c.registry.SetStatus(model, runtime, "ready") {
+		events.Publish(ctx, events.Event{Type: events.DeploymentReady, Model: model, Runtime: runtime})
+	}
+	return nil
+}
+
+// deployFailed records a deployment that did not become ready. Pods that never became
+// reachable keep the distinct no_endpoints status.
+func (c *Controller) deployFailed(ctx context.Context, model, runtime string, err error) {
+	if !errors.Is(err, k8s.ErrNoEndpoints) {
+		c.registry.SetStatus(model, runtime, "failed")
+	}
+	events.Publish(ctx, events.Event{Type: events.DeploymentFailed, Model: model, Runtime: runtime})
+}
+
+// ErrNotDeployed is returned for operations on a model and runtime pair that is not registered
+var ErrNotDeployed = errors.New("deployment not found")
+
+// RestartDeployment triggers a rollout restart of a deployment's pods with the same spec.
+// The entry is marked restarting until the rollout completes in the background.
+func (c *Controller) RestartDeployment(ctx context.Context, model, runtime string) error {
+	entry, found := c.registry.Get(model, runtime)
+	if !found {
+		return ErrNotDeployed
+	}
+
+	if err := k8s.RestartDeployment(ctx, entry.Namespace, entry.Deployment); err != nil {
+		return fmt.Errorf("failed to restart deployment: %w", err)
+	}
+	c.registry.SetStatus(model, runtime, "restarting")
+	events.Publish(ctx, events.Event{Type: events.DeploymentRestarted, Model: model, Runtime: runtime})
+
+	go func() {
+		var err error
+		watcher := readinessWatcherFor(c.registry)
+		if watcher != nil {
+			err = watcher.wait(context.Background(), model, runtime)
+		} else {
+			err = c.pollUntil(context.Background(), func(ctx context.Context) (bool, error) {
+				return k8s.IsRolloutComplete(ctx, entry.Namespace, entry.Deployment)
+			})
+		}
+		if err != nil {
+			c.registry.SetStatus(model, runtime, "failed")
+			events.Publish(context.Background(), events.Event{Type: events.DeploymentFailed, Model: model, Runtime: runtime})
+			return
+		}
+		if watcher == nil {
+			c.registry.SetStatus(model, runtime, "ready")
+			events.Publish(context.Background(), events.Event{Type: events.DeploymentReady, Model: model, Runtime: runtime})
+		}
+	}()
+
+	return nil
+}
+
+// TeardownDeployment deletes a deployment's Kubernetes resources and removes it from the
+// registry. Local workers have no resources and are only unregistered. A failed teardown
+// leaves the entry registered so it can be retried.
+func (c *Controller) TeardownDeployment(ctx context.Context, model, runtime string) error {
+	entry, found := c.registry.Get(model, runtime)
+	if !found {
+		return ErrNotDeployed
+	}
+
+	if entry.Runtime != "minimal" && entry.Deployment != "" {
+		if err := k8s.TeardownWorker(ctx, entry.Namespace, entry.Deployment, entry.Service); err != nil {
+			return fmt.Errorf("failed to tear down worker: %w", err)
+		}
+	}
+	c.registry.Delete(model, runtime)
+	events.Publish(ctx, events.Event{Type: events.DeploymentTeardown, Model: model, Runtime: runtime})
+
+	return nil
+}
+
+// waitForReady polls the deployment until it's reachable or times out. While the pods are
+// ready but the service has no endpoints the entry is marked no_endpoints, and a timeout in
+// that state returns ErrNoEndpoints.
+func (c *Controller) waitForReady(ctx context.Context, model, runtime, namespace, deploymentName, serviceName string) error {
+	state := ""
+	err := c.pollUntil(ctx, func(ctx context.Context) (bool, error) {
+		current, err := k8s.CheckReadiness(ctx, namespace, deploymentName, serviceName)
+		if err != nil {
+			return false, err
+		}
+		if current == k8s.ReadinessNoEndpoints && state != current {
+			c.registry.SetStatus(model, runtime, "no_endpoints")
+		}
+		state = current
+		return current == k8s.ReadinessReady, nil
+	})
+	if err != nil && state == k8s.ReadinessNoEndpoints {
+		return fmt.Errorf("%w: service %s: %v", k8s.ErrNoEndpoints, serviceName, err)
+	}
+	return err
 }
 
-// waitForReady polls the deployment until it's ready or times out
-func (c *Controller) waitForReady(ctx context.Context, namespace, deploymentName, serviceName string) error {
+// pollUntil calls check every 5 seconds until it reports done or readinessTimeout passes
+func (c *Controller) pollUntil(ctx context.Context, check func(context.Context) (bool, error)) error {
 	// Create a timeout context
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
 	defer cancel()
 
 	// Poll until ready
@@ -59,11 +197,11 @@ func (c *Controller) waitForReady(ctx context.Context, namespace, deploymentName
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-time.After(5 * time.Second):
-			ready, err := k8s.IsDeploymentReady(ctx, namespace, deploymentName)
+			done, err := check(ctx)
 			if err != nil {
 				return err
 			}
-			if ready {
+			if done {
 				return nil
 			}
 		}
@@ -2,24 +2,38 @@ package k8s
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/homedir"
 )
 
+// DefaultNamespace is the namespace worker deployments are created in
+const DefaultNamespace = "default"
+
 // Client is a wrapper around the Kubernetes client
 type Client struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
 }
 
 // RuntimeConfig represents a runtime configuration from YAML
@@ -30,6 +44,79 @@ type RuntimeConfig struct {
 	CPU   string            `yaml:"cpu"`
 	Mem   string            `yaml:"mem"`
 	Env   map[string]string `yaml:"env"`
+	// Quants lists the quantizations the runtime supports; empty means unrestricted
+	Quants []string `yaml:"quants"`
+	// SupportsCPU marks GPU runtimes that can also run CPU-only when deployed with gpu: 0
+	SupportsCPU bool `yaml:"supports_cpu"`
+	// StartupProbe holds readiness off until slow model loads finish; disabled when nil
+	StartupProbe *StartupProbeConfig `yaml:"startup_probe"`
+	// Images maps a quant to a quant-specific image; Image is used for unmapped quants
+	Images map[string]string `yaml:"images"`
+	// ReadinessPath is the worker endpoint that reports the model is loaded; defaults to /healthz
+	ReadinessPath string `yaml:"readiness_path"`
+	// Command and Args override the image entrypoint; Args may use {{.Model}}, {{.Quant}} and {{.Runtime}}
+	Command []string `yaml:"command"`
+	Args    []string `yaml:"args"`
+	// Replicas is the number of worker pods to run; defaults to 1
+	Replicas int `yaml:"replicas"`
+	// Profiles are named resource tiers merged over the base config at deploy time
+	Profiles map[string]RuntimeProfile `yaml:"profiles"`
+	// CacheAffinity prefers nodes labeled as holding the model in a node-local cache; disabled when nil
+	CacheAffinity *CacheAffinityConfig `yaml:"cache_affinity"`
+
+	// profile is the name of the profile merged into this config, if any
+	profile string
+	// overrides records the deploy request's resource overrides merged into this config, if any
+	overrides string
+}
+
+// RuntimeProfile overrides a runtime's base resources; unset fields keep the base value
+type RuntimeProfile struct {
+	GPU      *int   `yaml:"gpu"`
+	CPU      string `yaml:"cpu"`
+	Mem      string `yaml:"mem"`
+	Replicas int    `yaml:"replicas"`
+}
+
+// replicas returns the number of worker pods to run
+func (r *RuntimeConfig) replicas() int32 {
+	if r.Replicas > 0 {
+		return int32(r.Replicas)
+	}
+	return 1
+}
+
+// readinessPath returns the path probed to decide whether the worker can serve traffic
+func (r *RuntimeConfig) readinessPath() string {
+	if r.ReadinessPath != "" {
+		return r.ReadinessPath
+	}
+	return "/healthz"
+}
+
+// ImageForQuant returns the image that serves the given quant
+func (r *RuntimeConfig) ImageForQuant(quant string) string {
+	if image, ok := r.Images[quant]; ok && image != "" {
+		return image
+	}
+	return r.Image
+}
+
+// StartupProbeConfig configures the worker startup probe
+type StartupProbeConfig struct {
+	PeriodSeconds    int32 `yaml:"period_seconds"`
+	FailureThreshold int32 `yaml:"failure_threshold"`
+}
+
+// CacheAffinityConfig configures the preferred node affinity towards nodes with a warm
+// model cache. Nodes are matched on the label <label_prefix>/<model slug>=<value>.
+type CacheAffinityConfig struct {
+	// LabelPrefix defaults to model-cache
+	LabelPrefix string `yaml:"label_prefix"`
+	// Value defaults to present
+	Value string `yaml:"value"`
+	// Weight is the scheduling preference from 1 to 100; defaults to 100
+	Weight int32 `yaml:"weight"`
 }
 
 // ModelConfig represents a model configuration from YAML
@@ -37,6 +124,35 @@ type ModelConfig struct {
 	Name  string `yaml:"name"`
 	Quant string `yaml:"quant"`
 	Hash  string `yaml:"hash"`
+	// DefaultMaxTokens is applied when a request omits max_tokens
+	DefaultMaxTokens int `yaml:"default_max_tokens"`
+	// MaxTokensCap rejects requests asking for more tokens than this
+	MaxTokensCap int `yaml:"max_tokens_cap"`
+	// Aliases are alternative names that resolve to this model
+	Aliases []string `yaml:"aliases"`
+	// DefaultQuant is deployed when a request omits quant; falls back to Quant
+	DefaultQuant string `yaml:"default_quant"`
+}
+
+// defaultQuant returns the quant to deploy when none is requested
+func (m *ModelConfig) defaultQuant() string {
+	if m.DefaultQuant != "" {
+		return m.DefaultQuant
+	}
+	return m.Quant
+}
+
+// matches reports whether name is the model's canonical name or one of its aliases
+func (m *ModelConfig) matches(name string) bool {
+	if m.Name == name {
+		return true
+	}
+	for _, alias := range m.Aliases {
+		if alias == name {
+			return true
+		}
+	}
+	return false
 }
 
 // RuntimesConfig is the top-level structure for runtimes.yaml
@@ -49,21 +165,142 @@ type ModelsConfig struct {
 	Models []ModelConfig `yaml:"models"`
 }
 
-// NewClient creates a new Kubernetes client
-func NewClient() (*Client, error) {
-	var config *rest.Config
-	var err error
+// ErrInvalidDeploy is wrapped by errors caused by an invalid deploy request rather than a cluster failure
+var ErrInvalidDeploy = errors.New("invalid deploy request")
+
+// ErrDeployConflict is wrapped by errors for a deploy that does not match the worker already
+// running for its model and runtime
+var ErrDeployConflict = errors.New("deploy conflicts with existing worker")
+
+// DeployOptions holds per-deploy overrides applied on top of the runtime config
+type DeployOptions struct {
+	// GPU overrides the runtime's GPU count when set; 0 forces a CPU-only pod
+	GPU *int
+	// Profile selects one of the runtime's resource profiles; empty uses the base config
+	Profile string
+	// CPU and Mem override the runtime's CPU and memory requests and limits when set
+	CPU string
+	Mem string
+}
 
-	// Try in-cluster config first
-	config, err = rest.InClusterConfig()
+// resourceOverrides is the form in which a deploy's explicit resource overrides are recorded
+// on the worker deployment
+type resourceOverrides struct {
+	GPU *int   `json:"gpu,omitempty"`
+	CPU string `json:"cpu,omitempty"`
+	Mem string `json:"mem,omitempty"`
+}
+
+// validateQuantity checks that a resource override is a positive Kubernetes quantity
+func validateQuantity(name, value string) error {
+	quantity, err := resource.ParseQuantity(value)
 	if err != nil {
-		// Fall back to kubeconfig
-		kubeconfig := filepath.Join(homedir.HomeDir(), ".kube", "config")
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+		return fmt.Errorf("%w: invalid %s %q: %v", ErrInvalidDeploy, name, value, err)
+	}
+	if quantity.Sign() <= 0 {
+		return fmt.Errorf("%w: %s must be positive", ErrInvalidDeploy, name)
+	}
+	return nil
+}
+
+// withDefaultProfile selects the RUNTIME_PROFILE profile when the request names none
+func withDefaultProfile(opts DeployOptions) DeployOptions {
+	if opts.Profile == "" {
+		opts.Profile = os.Getenv("RUNTIME_PROFILE")
+	}
+	return opts
+}
+
+// applyDeployOptions returns a copy of the runtime config with the requested profile and then
+// the deploy overrides merged in. CPU and memory overrides must parse as Kubernetes quantities.
+func applyDeployOptions(runtimeConfig *RuntimeConfig, opts DeployOptions) (*RuntimeConfig, error) {
+	merged := *runtimeConfig
+
+	if opts.Profile != "" {
+		profile, ok := runtimeConfig.Profiles[opts.Profile]
+		if !ok {
+			return nil, fmt.Errorf("%w: profile %s is not defined for runtime %s", ErrInvalidDeploy, opts.Profile, runtimeConfig.Name)
+		}
+		if profile.GPU != nil {
+			merged.GPU = *profile.GPU
+		}
+		if profile.CPU != "" {
+			if err := validateQuantity("profile cpu", profile.CPU); err != nil {
+				return nil, err
+			}
+			merged.CPU = profile.CPU
+		}
+		if profile.Mem != "" {
+			if err := validateQuantity("profile mem", profile.Mem); err != nil {
+				return nil, err
+			}
+			merged.Mem = profile.Mem
+		}
+		if profile.Replicas > 0 {
+			merged.Replicas = profile.Replicas
+		}
+		merged.profile = opts.Profile
+	}
+
+	if opts.GPU != nil {
+		if *opts.GPU < 0 {
+			return nil, fmt.Errorf("%w: gpu must not be negative", ErrInvalidDeploy)
+		}
+		merged.GPU = *opts.GPU
+	}
+	if opts.CPU != "" {
+		if err := validateQuantity("cpu", opts.CPU); err != nil {
+			return nil, err
+		}
+		merged.CPU = opts.CPU
+	}
+	if opts.Mem != "" {
+		if err := validateQuantity("mem", opts.Mem); err != nil {
+			return nil, err
+		}
+		merged.Mem = opts.Mem
+	}
+	if opts.GPU != nil || opts.CPU != "" || opts.Mem != "" {
+		encoded, err := json.Marshal(resourceOverrides{GPU: opts.GPU, CPU: opts.CPU, Mem: opts.Mem})
 		if err != nil {
-			return nil, fmt.Errorf("failed to create k8s config: %w", err)
+			return nil, err
+		}
+		merged.overrides = string(encoded)
+	}
+	if merged.GPU < 0 {
+		return nil, fmt.Errorf("%w: gpu must not be negative", ErrInvalidDeploy)
+	}
+	if merged.GPU == 0 && runtimeConfig.GPU > 0 && !runtimeConfig.SupportsCPU {
+		return nil, fmt.Errorf("%w: runtime %s does not support CPU-only mode", ErrInvalidDeploy, runtimeConfig.Name)
+	}
+
+	return &merged, nil
+}
+
+// validateQuant checks that the runtime supports the quant; runtimes without quants accept any
+func validateQuant(runtimeConfig *RuntimeConfig, quant string) error {
+	if quant == "" || len(runtimeConfig.Quants) == 0 {
+		return nil
+	}
+	for _, q := range runtimeConfig.Quants {
+		if q == quant {
+			return nil
 		}
 	}
+	return fmt.Errorf("%w: quant %s is not supported by runtime %s (supported: %v)", ErrInvalidDeploy, quant, runtimeConfig.Name, runtimeConfig.Quants)
+}
+
+// NewClient creates a new Kubernetes client
+func NewClient() (*Client, error) {
+	return NewClientFromPath("")
+}
+
+// NewClientFromPath creates a new Kubernetes client, preferring an explicit kubeconfig path
+func NewClientFromPath(kubeconfigPath string) (*Client, error) {
+	config, err := restConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -75,69 +312,268 @@ func NewClient() (*Client, error) {
 	}, nil
 }
 
+// Ping checks that the Kubernetes API server is reachable and accepts the API's credentials
+func Ping(ctx context.Context) error {
+	client, err := NewClient()
+	if err != nil {
+		return err
+	}
+	if err := client.clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error(); err != nil {
+		return fmt.Errorf("kubernetes API unreachable: %w", err)
+	}
+	return nil
+}
+
+// restConfig resolves the cluster config from, in order, the explicit kubeconfig path, the
+// KUBECONFIG env var (which may list several files), in-cluster config and ~/.kube/config
+func restConfig(kubeconfigPath string) (*rest.Config, error) {
+	var tried []string
+
+	if kubeconfigPath != "" {
+		config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+		if err == nil {
+			return config, nil
+		}
+		tried = append(tried, fmt.Sprintf("kubeconfig %s: %v", kubeconfigPath, err))
+	}
+
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		rules := &clientcmd.ClientConfigLoadingRules{Precedence: filepath.SplitList(env)}
+		config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).ClientConfig()
+		if err == nil {
+			return config, nil
+		}
+		tried = append(tried, fmt.Sprintf("KUBECONFIG %s: %v", env, err))
+	}
+
+	config, err := rest.InClusterConfig()
+	if err == nil {
+		return config, nil
+	}
+	tried = append(tried, fmt.Sprintf("in-cluster: %v", err))
+
+	home := filepath.Join(homedir.HomeDir(), ".kube", "config")
+	config, err = clientcmd.BuildConfigFromFlags("", home)
+	if err == nil {
+		return config, nil
+	}
+	tried = append(tried, fmt.Sprintf("kubeconfig %s: %v", home, err))
+
+	return nil, fmt.Errorf("failed to create k8s config, tried %s", strings.Join(tried, "; "))
+}
+
+// WorkerDeployment describes the Kubernetes resources created for a worker
+type WorkerDeployment struct {
+	ServiceURL string
+	Namespace  string
+	Deployment string
+	Service    string
+	// ConfigHash identifies the effective model and runtime config the worker was built from
+	ConfigHash string
+}
+
 // DeployWorker deploys a worker for the specified model and runtime
-func DeployWorker(ctx context.Context, model, runtime, quant string) (string, string, string, string, error) {
+func DeployWorker(ctx context.Context, model, runtime, quant string, opts DeployOptions) (*WorkerDeployment, error) {
 	// Create a client
 	client, err := NewClient()
 	if err != nil {
-		return "", "", "", "", err
+		return nil, err
 	}
 
 	// Load runtime and model configs
 	runtimeConfig, err := client.loadRuntimeConfig(runtime)
 	if err != nil {
-		return "", "", "", "", err
+		return nil, err
 	}
 
 	modelConfig, err := client.loadModelConfig(model)
 	if err != nil {
-		return "", "", "", "", err
+		return nil, err
+	}
+
+	// Fall back to the model's default quant so workers never start with an empty QUANT
+	if quant == "" {
+		quant = modelConfig.defaultQuant()
+		if quant == "" {
+			return nil, fmt.Errorf("%w: quant is required and model %s has no default quant", ErrInvalidDeploy, model)
+		}
+	}
+
+	if err := validateQuant(runtimeConfig, quant); err != nil {
+		return nil, err
+	}
+
+	runtimeConfig, err = applyDeployOptions(runtimeConfig, withDefaultProfile(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	runtimeConfig, err = renderContainerArgs(runtimeConfig, model, quant)
+	if err != nil {
+		return nil, err
 	}
 
 	// Set namespace
-	namespace := "default"
+	namespace := DefaultNamespace
 
 	// Generate names
-	modelSlug := slugify(model)
-	deploymentName := fmt.Sprintf("worker-%s-%s", runtime, modelSlug)
+	deploymentName := workerResourceName(runtime, model)
 	serviceName := deploymentName
 
+	// Check the URL before creating anything so naming bugs fail the deploy instead of the
+	// first inference
+	serviceURL, err := workerServiceURL(workerScheme(), serviceName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create deployment
-	_, err = client.createDeployment(ctx, namespace, deploymentName, model, runtime, quant, runtimeConfig, modelConfig)
+	deployment, err := client.createDeployment(ctx, namespace, deploymentName, model, runtime, quant, runtimeConfig, modelConfig)
 	if err != nil {
-		return "", "", "", "", err
+		return nil, err
 	}
 
 	// Create service
 	_, err = client.createService(ctx, namespace, serviceName, deploymentName)
 	if err != nil {
-		return "", "", "", "", err
+		return nil, err
+	}
+
+	// An adopted deployment keeps the config it was created with
+	configHash := deployment.Annotations[configHashAnnotation]
+	if configHash == "" {
+		configHash = ConfigHash(runtimeConfig, modelConfig, quant)
+	}
+
+	return &WorkerDeployment{
+		ServiceURL: serviceURL,
+		Namespace:  namespace,
+		Deployment: deploymentName,
+		Service:    serviceName,
+		ConfigHash: configHash,
+	}, nil
+}
+
+// workerServiceURL builds the in-cluster URL of a worker Service. The service and namespace
+// must be valid DNS labels, and the URL is returned in normalized form with no trailing slash
+// so callers can append paths such as /infer.
+func workerServiceURL(scheme, serviceName, namespace string) (string, error) {
+	if errs := validation.IsDNS1123Label(serviceName); len(errs) > 0 {
+		return "", fmt.Errorf("invalid service name %q: %s", serviceName, strings.Join(errs, "; "))
 	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return "", fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, "; "))
+	}
+	return normalizeServiceURL(fmt.Sprintf("%s://%s.%s.svc.cluster.local:8000", scheme, serviceName, namespace))
+}
 
-	// Construct service URL
-	serviceURL := fmt.Sprintf("http://%s.%s.svc.cluster.local:8000", serviceName, namespace)
+// normalizeServiceURL parses a worker URL and returns it with a lowercase scheme and host and
+// without a trailing slash. URLs that are not absolute http(s) URLs with a host, or that carry
+// a query, fragment or credentials, are rejected.
+func normalizeServiceURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid service URL %q: %w", raw, err)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid service URL %q: scheme must be http or https", raw)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("invalid service URL %q: missing host", raw)
+	}
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid service URL %q: must not contain credentials, a query or a fragment", raw)
+	}
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
 
-	return serviceURL, namespace, deploymentName, serviceName, nil
+// workerScheme returns the URL scheme workers are reached over; WORKER_SCHEME=https enables TLS
+func workerScheme() string {
+	if os.Getenv("WORKER_SCHEME") == "https" {
+		return "https"
+	}
+	return "http"
 }
 
-// IsDeploymentReady checks if a deployment is ready
-func IsDeploymentReady(ctx context.Context, namespace, deploymentName string) (bool, error) {
+// RestartDeployment triggers a rollout restart by stamping the pod template with the restart
+// time, the same way kubectl rollout restart does
+func RestartDeployment(ctx context.Context, namespace, deploymentName string) error {
+	client, err := NewClient()
+	if err != nil {
+		return err
+	}
+
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":%q}}}}}`, time.Now().Format(time.RFC3339))
+	return withRetry(ctx, func(ctx context.Context) error {
+		_, err := client.clientset.AppsV1().Deployments(namespace).Patch(ctx, deploymentName, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{})
+		return err
+	})
+}
+
+// IsRolloutComplete checks that every replica runs the latest pod template and is ready
+func IsRolloutComplete(ctx context.Context, namespace, deploymentName string) (bool, error) {
 	client, err := NewClient()
 	if err != nil {
 		return false, err
 	}
 
-	deployment, err := client.clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	deployment, err := client.getDeployment(ctx, namespace, deploymentName)
 	if err != nil {
 		return false, err
 	}
 
-	return deployment.Status.ReadyReplicas == *deployment.Spec.Replicas, nil
+	return RolloutComplete(deployment), nil
+}
+
+// RolloutComplete reports whether every replica of a deployment runs its latest pod template
+// and is ready
+func RolloutComplete(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.ObservedGeneration >= deployment.Generation &&
+		status.UpdatedReplicas == replicas &&
+		status.Replicas == replicas &&
+		status.ReadyReplicas == replicas
+}
+
+// TeardownWorker deletes a worker's deployment and service. Resources that are already gone
+// are not an error, so tearing down twice succeeds.
+func TeardownWorker(ctx context.Context, namespace, deploymentName, serviceName string) error {
+	client, err := NewClient()
+	if err != nil {
+		return err
+	}
+
+	propagation := metav1.DeletePropagationForeground
+	err = withRetry(ctx, func(ctx context.Context) error {
+		return client.clientset.AppsV1().Deployments(namespace).Delete(ctx, deploymentName, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete deployment %s: %w", deploymentName, err)
+	}
+
+	if serviceName == "" {
+		return nil
+	}
+	err = withRetry(ctx, func(ctx context.Context) error {
+		return client.clientset.CoreV1().Services(namespace).Delete(ctx, serviceName, metav1.DeleteOptions{})
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete service %s: %w", serviceName, err)
+	}
+	return nil
 }
 
 // loadRuntimeConfig loads the runtime configuration from YAML
 func (c *Client) loadRuntimeConfig(runtime string) (*RuntimeConfig, error) {
-	data, err := os.ReadFile("configs/runtimes.yaml")
+	data, err := os.ReadFile(filepath.Join(configDir, "runtimes.yaml"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read runtimes config: %w", err)
 	}
@@ -146,6 +582,9 @@ func (c *Client) loadRuntimeConfig(runtime string) (*RuntimeConfig, error) {
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse runtimes config: %w", err)
 	}
+	if len(config.Runtimes) == 0 {
+		return nil, fmt.Errorf("no runtimes defined in config")
+	}
 
 	for _, r := range config.Runtimes {
 		if r.Name == runtime {
@@ -158,7 +597,7 @@ func (c *Client) loadRuntimeConfig(runtime string) (*RuntimeConfig, error) {
 
 // loadModelConfig loads the model configuration from YAML
 func (c *Client) loadModelConfig(model string) (*ModelConfig, error) {
-	data, err := os.ReadFile("configs/models.yaml")
+	data, err := os.ReadFile(filepath.Join(configDir, "models.yaml"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read models config: %w", err)
 	}
@@ -167,9 +606,12 @@ func (c *Client) loadModelConfig(model string) (*ModelConfig, error) {
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse models config: %w", err)
 	}
+	if len(config.Models) == 0 {
+		return nil, fmt.Errorf("no models defined in config")
+	}
 
 	for _, m := range config.Models {
-		if m.Name == model {
+		if m.matches(model) {
 			return &m, nil
 		}
 	}
@@ -180,10 +622,102 @@ func (c *Client) loadModelConfig(model string) (*ModelConfig, error) {
 // createDeployment creates a Kubernetes deployment for a worker
 func (c *Client) createDeployment(ctx context.Context, namespace, name, model, runtime, quant string, runtimeConfig *RuntimeConfig, modelConfig *ModelConfig) (*appsv1.Deployment, error) {
 	// Create deployment spec
-	deployment := buildDeploymentManifest(namespace, name, model, runtime, quant, runtimeConfig, modelConfig)
+	deployment, err := buildDeploymentManifest(namespace, name, model, runtime, quant, runtimeConfig, modelConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	// Create deployment
-	return c.clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+	// Create deployment, adopting one that already exists, e.g. from an earlier deploy whose
+	// registry entry was lost on restart
+	var created *appsv1.Deployment
+	err = withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = c.clientset.AppsV1().Deployments(namespace).Create(ctx, deployment, metav1.CreateOptions{})
+		return err
+	})
+	if apierrors.IsAlreadyExists(err) {
+		existing, err := c.getDeployment(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		// A deployment that is still being torn down, such as the previous quant variant of a
+		// benchmark, must not be adopted; create a fresh one once it is gone
+		if existing.DeletionTimestamp != nil {
+			log.Printf("Waiting for deployment %s/%s to be deleted", namespace, name)
+			if err := c.waitForDeletion(ctx, namespace, name); err != nil {
+				return nil, err
+			}
+			return c.createDeployment(ctx, namespace, name, model, runtime, quant, runtimeConfig, modelConfig)
+		}
+		// Adopting a worker built for another variant would serve it under the wrong quant
+		if reason := adoptionMismatch(existing, deployment); reason != "" {
+			return nil, fmt.Errorf("%w: deployment %s/%s %s; tear it down before deploying another variant", ErrDeployConflict, namespace, name, reason)
+		}
+		log.Printf("Adopting existing deployment %s/%s", namespace, name)
+		return existing, nil
+	}
+	return created, err
+}
+
+// adoptionMismatch describes how an existing deployment differs from the one a deploy would
+// create in quant, profile or resource overrides, or returns "" when it can be adopted
+func adoptionMismatch(existing, wanted *appsv1.Deployment) string {
+	var existingQuant, wantedQuant string
+	if container := workerContainer(&existing.Spec.Template.Spec); container != nil {
+		existingQuant = envValue(container.Env, "QUANT")
+	}
+	if container := workerContainer(&wanted.Spec.Template.Spec); container != nil {
+		wantedQuant = envValue(container.Env, "QUANT")
+	}
+	switch {
+	case existingQuant != wantedQuant:
+		return fmt.Sprintf("runs quant %s, not %s", existingQuant, wantedQuant)
+	case existing.Annotations[profileAnnotation] != wanted.Annotations[profileAnnotation]:
+		return fmt.Sprintf("uses profile %q, not %q", existing.Annotations[profileAnnotation], wanted.Annotations[profileAnnotation])
+	case existing.Annotations[resourceOverridesAnnotation] != wanted.Annotations[resourceOverridesAnnotation]:
+		return fmt.Sprintf("has resource overrides %q, not %q", existing.Annotations[resourceOverridesAnnotation], wanted.Annotations[resourceOverridesAnnotation])
+	}
+	return ""
+}
+
+// deletionTimeout bounds how long a deploy waits for a terminating deployment to go away
+const deletionTimeout = 5 * time.Minute
+
+// deletionPollInterval is how often a terminating deployment is checked; shortened in tests
+var deletionPollInterval = 2 * time.Second
+
+// waitForDeletion polls a deployment until it no longer exists or deletionTimeout passes
+func (c *Client) waitForDeletion(ctx context.Context, namespace, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, deletionTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(deletionPollInterval)
+	defer ticker.Stop()
+	for {
+		_, err := c.getDeployment(ctx, namespace, name)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for deployment %s/%s to be deleted: %w", namespace, name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// getDeployment fetches a deployment, retrying transient API errors
+func (c *Client) getDeployment(ctx context.Context, namespace, name string) (*appsv1.Deployment, error) {
+	var deployment *appsv1.Deployment
+	err := withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		deployment, err = c.clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		return err
+	})
+	return deployment, err
 }
 
 // createService creates a Kubernetes service for a worker
@@ -191,17 +725,95 @@ func (c *Client) createService(ctx context.Context, namespace, name, deploymentN
 	// Create service spec
 	service := buildServiceManifest(namespace, name, deploymentName)
 
-	// Create service
-	return c.clientset.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+	// Create service, adopting one that already exists
+	var created *corev1.Service
+	err := withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = c.clientset.CoreV1().Services(namespace).Create(ctx, service, metav1.CreateOptions{})
+		return err
+	})
+	if apierrors.IsAlreadyExists(err) {
+		err = withRetry(ctx, func(ctx context.Context) error {
+			var err error
+			created, err = c.clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+			return err
+		})
+	}
+	return created, err
+}
+
+// maxResourceNameLen is the Kubernetes limit for resource names and label values
+const maxResourceNameLen = 63
+
+// slugHashLen is the number of hex characters of the name hash appended to slugs
+const slugHashLen = 8
+
+// maxRuntimeSlugLen bounds the runtime part of worker names so the model slug keeps room
+const maxRuntimeSlugLen = 20
+
+// workerResourceName returns the deployment and service name for a model and runtime
+func workerResourceName(runtime, model string) string {
+	prefix := fmt.Sprintf("worker-%s-", runtimeSlug(runtime))
+	return prefix + slugifyMax(model, maxResourceNameLen-len(prefix))
+}
+
+// runtimeSlug converts a runtime name to a DNS-safe slug of at most maxRuntimeSlugLen
+// characters. Runtime names are short and distinct, so no hash is appended.
+func runtimeSlug(runtime string) string {
+	slug := dnsSafe(runtime)
+	if len(slug) > maxRuntimeSlugLen {
+		slug = slug[:maxRuntimeSlugLen]
+	}
+	return strings.Trim(slug, "-")
 }
 
-// slugify converts a model name to a valid Kubernetes resource name
+// dnsSafe lowercases a name and collapses every run of characters other than letters and
+// digits into a single dash
+func dnsSafe(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else if b.Len() > 0 && !strings.HasSuffix(b.String(), "-") {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// slugify converts a model name to a valid Kubernetes label value
 func slugify(name string) string {
-	// Replace slashes with dashes
-	slug := strings.ReplaceAll(name, "/", "-")
-	// Replace dots with dashes
-	slug = strings.ReplaceAll(slug, ".", "-")
-	// Convert to lowercase
-	slug = strings.ToLower(slug)
-	return slug
+	return slugifyMax(name, maxResourceNameLen)
+}
+
+// slugifyMax converts a name to a lowercase DNS-safe slug of at most maxLen characters.
+// By default a short hash of the original name is appended so names that normalize to the
+// same readable part stay distinct; the readable part is truncated to fit, never the hash.
+// SLUG_STRATEGY=legacy keeps the original unhashed slugs for clusters with existing workers,
+// truncated to maxLen.
+func slugifyMax(name string, maxLen int) string {
+	if os.Getenv("SLUG_STRATEGY") == "legacy" {
+		slug := strings.ReplaceAll(name, "/", "-")
+		slug = strings.ReplaceAll(slug, ".", "-")
+		slug = strings.ToLower(slug)
+		if len(slug) > maxLen {
+			slug = strings.TrimRight(slug[:max(maxLen, 0)], "-")
+		}
+		return slug
+	}
+
+	readable := dnsSafe(name)
+
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:])[:slugHashLen]
+
+	room := maxLen - len(hash) - 1
+	if len(readable) > room {
+		readable = readable[:max(room, 0)]
+	}
+	readable = strings.Trim(readable, "-")
+	if readable == "" {
+		return hash
+	}
+	return readable + "-" + hash
 }
@@ -0,0 +1,119 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// configMapKeys are the config files that may be provided by a ConfigMap
+var configMapKeys = []string{"models.yaml", "runtimes.yaml", "routes.yaml"}
+
+// configMapRewatchDelay is how long to wait before re-establishing a closed watch
+const configMapRewatchDelay = 5 * time.Second
+
+// configDir is the directory the control plane reads runtimes.yaml and models.yaml from
+var configDir = "configs"
+
+// SetConfigDir changes the directory the control plane reads its config files from
+func SetConfigDir(dir string) {
+	configDir = dir
+}
+
+// SyncConfigMap copies the config files held by a ConfigMap into dir and keeps them up to
+// date by watching the ConfigMap until ctx is done. Config loaders read their files on every
+// call, so edits made with kubectl take effect without restarting or rebuilding the API.
+func SyncConfigMap(ctx context.Context, namespace, name, dir string) error {
+	client, err := NewClient()
+	if err != nil {
+		return err
+	}
+
+	configMap, err := client.clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get config map %s/%s: %w", namespace, name, err)
+	}
+	if err := writeConfigFiles(dir, configMap.Data); err != nil {
+		return err
+	}
+
+	go client.watchConfigMap(ctx, namespace, name, dir, configMap.ResourceVersion)
+	return nil
+}
+
+// watchConfigMap rewrites the config files whenever the ConfigMap changes, re-establishing
+// the watch when the API server closes it
+func (c *Client) watchConfigMap(ctx context.Context, namespace, name, dir, resourceVersion string) {
+	for {
+		watcher, err := c.clientset.CoreV1().ConfigMaps(namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector:   "metadata.name=" + name,
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			log.Printf("Failed to watch config map %s/%s: %v", namespace, name, err)
+		} else {
+			for event := range watcher.ResultChan() {
+				configMap, ok := event.Object.(*corev1.ConfigMap)
+				if !ok {
+					// Typically an expired resource version; restart from the latest state
+					resourceVersion = ""
+					continue
+				}
+				resourceVersion = configMap.ResourceVersion
+				if event.Type != watch.Added && event.Type != watch.Modified {
+					continue
+				}
+				if err := writeConfigFiles(dir, configMap.Data); err != nil {
+					log.Printf("Failed to update config from config map %s/%s: %v", namespace, name, err)
+					continue
+				}
+				log.Printf("Reloaded config from config map %s/%s", namespace, name)
+			}
+			watcher.Stop()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(configMapRewatchDelay):
+		}
+	}
+}
+
+// writeConfigFiles writes the known config files present in data to dir. Each file is
+// written to a temporary file and renamed so readers never see a partial config.
+func writeConfigFiles(dir string, data map[string]string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+	for _, key := range configMapKeys {
+		content, ok := data[key]
+		if !ok {
+			continue
+		}
+		tmp, err := os.CreateTemp(dir, "."+key+".*")
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", key, err)
+		}
+		_, writeErr := tmp.WriteString(content)
+		closeErr := tmp.Close()
+		if writeErr == nil {
+			writeErr = closeErr
+		}
+		if writeErr == nil {
+			writeErr = os.Rename(tmp.Name(), filepath.Join(dir, key))
+		}
+		if writeErr != nil {
+			os.Remove(tmp.Name())
+			return fmt.Errorf("failed to write %s: %w", key, writeErr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteConfigFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config")
+	data := map[string]string{
+		"models.yaml":   "models:\n  - name: test-model\n",
+		"runtimes.yaml": "runtimes:\n  - name: vllm\n",
+		"secrets.yaml":  "password: hunter2\n",
+	}
+
+	if err := writeConfigFiles(dir, data); err != nil {
+		t.Fatalf("Expected config files to be written, got %v", err)
+	}
+
+	for _, key := range []string{"models.yaml", "runtimes.yaml"} {
+		got, err := os.ReadFile(filepath.Join(dir, key))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", key, err)
+		}
+		if string(got) != data[key] {
+			t.Errorf("Expected %s to contain %q, got %q", key, data[key], got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "secrets.yaml")); !os.IsNotExist(err) {
+		t.Errorf("Expected unknown keys not to be written")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("Expected no temporary files to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestLoadRuntimeConfigUsesConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeConfigFiles(dir, map[string]string{"runtimes.yaml": "runtimes:\n  - name: vllm\n    image: test-image\n"}); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	SetConfigDir(dir)
+	defer SetConfigDir("configs")
+
+	runtimeConfig, err := (&Client{}).loadRuntimeConfig("vllm")
+	if err != nil {
+		t.Fatalf("Expected runtime config to load from the config dir, got %v", err)
+	}
+	if runtimeConfig.Image != "test-image" {
+		t.Errorf("Expected image test-image, got %s", runtimeConfig.Image)
+	}
+}
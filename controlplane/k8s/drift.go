@@ -0,0 +1,248 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FieldDiff describes a single field that differs between a live deployment and its expected manifest
+type FieldDiff struct {
+	Field    string `json:"field"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// DeploymentDrift reports how a live worker deployment differs from the current config
+type DeploymentDrift struct {
+	Namespace  string `json:"namespace"`
+	Deployment string `json:"deployment"`
+	Model      string `json:"model"`
+	Runtime    string `json:"runtime"`
+	Quant      string `json:"quant"`
+	// ConfigHash is the hash the live deployment was created with; ExpectedConfigHash is the
+	// hash of the current config. They differ whenever the config changed, even in fields
+	// that do not affect the worker container.
+	ConfigHash         string      `json:"config_hash,omitempty"`
+	ExpectedConfigHash string      `json:"expected_config_hash,omitempty"`
+	Error              string      `json:"error,omitempty"`
+	Diffs              []FieldDiff `json:"diffs,omitempty"`
+}
+
+// DetectDrift compares every live worker deployment in the namespace against the manifest
+// that would be built from the current config, returning only the deployments that differ
+func DetectDrift(ctx context.Context, namespace string) ([]DeploymentDrift, int, error) {
+	client, err := NewClient()
+	if err != nil {
+		return nil, 0, err
+	}
+	return client.detectDrift(ctx, namespace)
+}
+
+// detectDrift checks the worker deployments in the namespace on the client's cluster
+func (c *Client) detectDrift(ctx context.Context, namespace string) ([]DeploymentDrift, int, error) {
+	deployments, err := c.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app=worker",
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list worker deployments: %w", err)
+	}
+
+	drifted := []DeploymentDrift{}
+	for i := range deployments.Items {
+		live := &deployments.Items[i]
+		if drift, ok := c.checkDrift(live); ok {
+			drifted = append(drifted, drift)
+		}
+	}
+
+	return drifted, len(deployments.Items), nil
+}
+
+// checkDrift rebuilds the expected manifest for a live deployment and reports whether it drifted
+func (c *Client) checkDrift(live *appsv1.Deployment) (DeploymentDrift, bool) {
+	container := workerContainer(&live.Spec.Template.Spec)
+	drift := DeploymentDrift{
+		Namespace:  live.Namespace,
+		Deployment: live.Name,
+		Runtime:    live.Labels["runtime"],
+		ConfigHash: live.Annotations[configHashAnnotation],
+	}
+	if container == nil {
+		drift.Error = "deployment has no worker container"
+		return drift, true
+	}
+	drift.Model = envValue(container.Env, "MODEL_NAME")
+	drift.Quant = envValue(container.Env, "QUANT")
+
+	runtimeConfig, err := c.loadRuntimeConfig(drift.Runtime)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift, true
+	}
+
+	// Deployments are compared against the profile and resource overrides they were created
+	// with. Deployments that predate recorded overrides and run CPU-only are compared against
+	// the CPU variant.
+	opts := DeployOptions{Profile: live.Annotations[profileAnnotation]}
+	if recorded, ok := live.Annotations[resourceOverridesAnnotation]; ok {
+		var overrides resourceOverrides
+		if err := json.Unmarshal([]byte(recorded), &overrides); err != nil {
+			drift.Error = fmt.Sprintf("invalid %s annotation: %v", resourceOverridesAnnotation, err)
+			return drift, true
+		}
+		opts.GPU, opts.CPU, opts.Mem = overrides.GPU, overrides.CPU, overrides.Mem
+	} else if envValue(container.Env, "CPU_ONLY") == "true" {
+		cpuOnly := 0
+		opts.GPU = &cpuOnly
+	}
+	overridden, err := applyDeployOptions(runtimeConfig, opts)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift, true
+	}
+	runtimeConfig = overridden
+
+	modelConfig, err := c.loadModelConfig(drift.Model)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift, true
+	}
+
+	runtimeConfig, err = renderContainerArgs(runtimeConfig, drift.Model, drift.Quant)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift, true
+	}
+
+	drift.ExpectedConfigHash = ConfigHash(runtimeConfig, modelConfig, drift.Quant)
+	expected, err := buildDeploymentManifest(live.Namespace, live.Name, drift.Model, drift.Runtime, drift.Quant, runtimeConfig, modelConfig)
+	if err != nil {
+		drift.Error = err.Error()
+		return drift, true
+	}
+	drift.Diffs = diffContainers(workerContainer(&expected.Spec.Template.Spec), container)
+
+	return drift, len(drift.Diffs) > 0
+}
+
+// diffContainers compares the image, resources and env of two worker containers
+func diffContainers(expected, actual *corev1.Container) []FieldDiff {
+	var diffs []FieldDiff
+
+	if expected.Image != actual.Image {
+		diffs = append(diffs, FieldDiff{Field: "image", Expected: expected.Image, Actual: actual.Image})
+	}
+
+	if want, got := strings.Join(expected.Command, " "), strings.Join(actual.Command, " "); want != got {
+		diffs = append(diffs, FieldDiff{Field: "command", Expected: want, Actual: got})
+	}
+	if want, got := strings.Join(expected.Args, " "), strings.Join(actual.Args, " "); want != got {
+		diffs = append(diffs, FieldDiff{Field: "args", Expected: want, Actual: got})
+	}
+
+	diffs = append(diffs, diffResourceList("resources.limits", expected.Resources.Limits, actual.Resources.Limits)...)
+	diffs = append(diffs, diffResourceList("resources.requests", expected.Resources.Requests, actual.Resources.Requests)...)
+
+	expectedEnv := envMap(expected.Env)
+	actualEnv := envMap(actual.Env)
+	for _, name := range unionKeys(expectedEnv, actualEnv) {
+		want, wantOK := expectedEnv[name]
+		got, gotOK := actualEnv[name]
+		if wantOK != gotOK || want != got {
+			diffs = append(diffs, FieldDiff{Field: "env." + name, Expected: want, Actual: got})
+		}
+	}
+
+	return diffs
+}
+
+// diffResourceList compares two resource lists quantity by quantity
+func diffResourceList(prefix string, expected, actual corev1.ResourceList) []FieldDiff {
+	names := make(map[corev1.ResourceName]bool)
+	for name := range expected {
+		names[name] = true
+	}
+	for name := range actual {
+		names[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, string(name))
+	}
+	sort.Strings(sorted)
+
+	var diffs []FieldDiff
+	for _, name := range sorted {
+		want, wantOK := expected[corev1.ResourceName(name)]
+		got, gotOK := actual[corev1.ResourceName(name)]
+		if wantOK && gotOK && want.Cmp(got) == 0 {
+			continue
+		}
+		diff := FieldDiff{Field: prefix + "." + name}
+		if wantOK {
+			diff.Expected = want.String()
+		}
+		if gotOK {
+			diff.Actual = got.String()
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// workerContainer returns the container named "worker", or the first container if none matches
+func workerContainer(spec *corev1.PodSpec) *corev1.Container {
+	for i := range spec.Containers {
+		if spec.Containers[i].Name == "worker" {
+			return &spec.Containers[i]
+		}
+	}
+	if len(spec.Containers) > 0 {
+		return &spec.Containers[0]
+	}
+	return nil
+}
+
+// envValue returns the value of the named environment variable
+func envValue(env []corev1.EnvVar, name string) string {
+	for _, e := range env {
+		if e.Name == name {
+			return e.Value
+		}
+	}
+	return ""
+}
+
+// envMap converts a list of environment variables to a map
+func envMap(env []corev1.EnvVar) map[string]string {
+	result := make(map[string]string, len(env))
+	for _, e := range env {
+		result[e.Name] = e.Value
+	}
+	return result
+}
+
+// unionKeys returns the sorted union of the keys of two maps
+func unionKeys(a, b map[string]string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
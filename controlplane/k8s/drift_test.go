@@ -0,0 +1,151 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const driftTestConfig = `runtimes:
+  - name: vllm
+    image: ghcr.io/tokenforge/worker-vllm:latest
+    gpu: 1
+    cpu: "2"
+    mem: 16Gi
+`
+
+const driftTestModels = `models:
+  - name: test-model
+    quant: fp16
+    hash: sha256:test
+`
+
+// liveWorker builds the deployment a deploy of test-model on vllm creates from the test config
+func liveWorker(t *testing.T) *appsv1.Deployment {
+	t.Helper()
+	c := &Client{}
+	runtimeConfig, err := c.loadRuntimeConfig("vllm")
+	if err != nil {
+		t.Fatalf("Failed to load runtime config: %v", err)
+	}
+	modelConfig, err := c.loadModelConfig("test-model")
+	if err != nil {
+		t.Fatalf("Failed to load model config: %v", err)
+	}
+	runtimeConfig, err = renderContainerArgs(runtimeConfig, "test-model", "fp16")
+	if err != nil {
+		t.Fatalf("Failed to render container args: %v", err)
+	}
+	return mustBuildDeploymentManifest(t, "default", "worker-vllm-test-model", "test-model", "vllm", "fp16", runtimeConfig, modelConfig)
+}
+
+func TestDetectDrift(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeConfigFiles(dir, map[string]string{"runtimes.yaml": driftTestConfig, "models.yaml": driftTestModels}); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	SetConfigDir(dir)
+	defer SetConfigDir("configs")
+
+	tests := []struct {
+		name      string
+		modify    func(*appsv1.Deployment)
+		wantDrift bool
+		wantField string
+		wantError bool
+	}{
+		{
+			name:   "matching deployment",
+			modify: func(*appsv1.Deployment) {},
+		},
+		{
+			name: "image drifted",
+			modify: func(d *appsv1.Deployment) {
+				workerContainer(&d.Spec.Template.Spec).Image = "ghcr.io/tokenforge/worker-vllm:old"
+			},
+			wantDrift: true,
+			wantField: "image",
+		},
+		{
+			name: "env drifted",
+			modify: func(d *appsv1.Deployment) {
+				container := workerContainer(&d.Spec.Template.Spec)
+				container.Env = append(container.Env, corev1.EnvVar{Name: "EXTRA", Value: "1"})
+			},
+			wantDrift: true,
+			wantField: "env.EXTRA",
+		},
+		{
+			name: "runtime missing from config",
+			modify: func(d *appsv1.Deployment) {
+				d.Labels["runtime"] = "tgi"
+			},
+			wantDrift: true,
+			wantError: true,
+		},
+		{
+			name: "model missing from config",
+			modify: func(d *appsv1.Deployment) {
+				container := workerContainer(&d.Spec.Template.Spec)
+				for i := range container.Env {
+					if container.Env[i].Name == "MODEL_NAME" {
+						container.Env[i].Value = "removed-model"
+					}
+				}
+			},
+			wantDrift: true,
+			wantError: true,
+		},
+		{
+			name: "worker container missing",
+			modify: func(d *appsv1.Deployment) {
+				d.Spec.Template.Spec.Containers = nil
+			},
+			wantDrift: true,
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live := liveWorker(t)
+			tt.modify(live)
+			c := &Client{clientset: fake.NewClientset(live)}
+
+			drifted, checked, err := c.detectDrift(context.Background(), "default")
+			if err != nil {
+				t.Fatalf("Expected drift detection to succeed, got %v", err)
+			}
+			if checked != 1 {
+				t.Errorf("Expected 1 deployment checked, got %d", checked)
+			}
+			if !tt.wantDrift {
+				if len(drifted) != 0 {
+					t.Errorf("Expected no drift, got %+v", drifted)
+				}
+				return
+			}
+			if len(drifted) != 1 {
+				t.Fatalf("Expected 1 drifted deployment, got %+v", drifted)
+			}
+			drift := drifted[0]
+			if (drift.Error != "") != tt.wantError {
+				t.Errorf("Expected error %v, got %q", tt.wantError, drift.Error)
+			}
+			if tt.wantField != "" && (len(drift.Diffs) != 1 || drift.Diffs[0].Field != tt.wantField) {
+				t.Errorf("Expected a single %s diff, got %+v", tt.wantField, drift.Diffs)
+			}
+		})
+	}
+}
+
+func TestDetectDriftWithoutDeployments(t *testing.T) {
+	c := &Client{clientset: fake.NewClientset()}
+	drifted, checked, err := c.detectDrift(context.Background(), "default")
+	if err != nil || checked != 0 || len(drifted) != 0 {
+		t.Errorf("Expected nothing to check, got %+v, %d, %v", drifted, checked, err)
+	}
+}
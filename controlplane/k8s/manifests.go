@@ -1,7 +1,12 @@
 package k8s
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"text/template"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -10,13 +15,171 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+const (
+	// defaultStartupPeriodSeconds is the startup probe period when not configured
+	defaultStartupPeriodSeconds = 10
+	// defaultStartupFailureThreshold allows 10 minutes of startup at the default period
+	defaultStartupFailureThreshold = 60
+	// profileAnnotation records the runtime profile a worker deployment was built from
+	profileAnnotation = "tokenforge.io/profile"
+	// configHashAnnotation records the hash of the effective config a worker was deployed from
+	configHashAnnotation = "tokenforge.io/config-hash"
+	// defaultCacheLabelPrefix is the node label prefix marking node-local model caches
+	defaultCacheLabelPrefix = "model-cache"
+	// defaultCacheLabelValue is the node label value marking a model as cached
+	defaultCacheLabelValue = "present"
+	// maxCacheAffinityWeight is the highest weight Kubernetes allows for a preferred term
+	maxCacheAffinityWeight = 100
+	// resourceOverridesAnnotation records the resource overrides a worker deployment was requested with
+	resourceOverridesAnnotation = "tokenforge.io/resource-overrides"
+)
+
+// ConfigHash returns a stable hash of the effective model and runtime config a worker is
+// deployed with, after profiles and arg templates are applied. The runtime's other profiles
+// are left out so that editing an unused profile does not change the hash.
+func ConfigHash(runtimeConfig *RuntimeConfig, modelConfig *ModelConfig, quant string) string {
+	effective := *runtimeConfig
+	effective.Profiles = nil
+	encoded, err := json.Marshal(struct {
+		Runtime RuntimeConfig
+		Profile string
+		Model   ModelConfig
+		Quant   string
+	}{effective, runtimeConfig.profile, *modelConfig, quant})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(encoded)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// buildStartupProbe creates the startup probe for a runtime, or nil if it is not configured
+func buildStartupProbe(cfg *StartupProbeConfig, path string) *corev1.Probe {
+	if cfg == nil {
+		return nil
+	}
+
+	period := cfg.PeriodSeconds
+	if period <= 0 {
+		period = defaultStartupPeriodSeconds
+	}
+	failureThreshold := cfg.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = defaultStartupFailureThreshold
+	}
+
+	return &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: path,
+				Port: intstr.FromInt(8000),
+			},
+		},
+		PeriodSeconds:    period,
+		TimeoutSeconds:   2,
+		SuccessThreshold: 1,
+		FailureThreshold: failureThreshold,
+	}
+}
+
+// cacheLabelKey returns the node label that marks a model as present in a node-local cache
+func cacheLabelKey(cfg *CacheAffinityConfig, model string) string {
+	prefix := cfg.LabelPrefix
+	if prefix == "" {
+		prefix = defaultCacheLabelPrefix
+	}
+	return prefix + "/" + slugify(model)
+}
+
+// buildCacheAffinity creates a preferred node affinity for nodes that already cache the model,
+// or nil if cache affinity is not configured. It is only a preference, so workers still
+// schedule on cold nodes when no warm node has room.
+func buildCacheAffinity(cfg *CacheAffinityConfig, model string) *corev1.Affinity {
+	if cfg == nil {
+		return nil
+	}
+
+	value := cfg.Value
+	if value == "" {
+		value = defaultCacheLabelValue
+	}
+	weight := cfg.Weight
+	if weight <= 0 || weight > maxCacheAffinityWeight {
+		weight = maxCacheAffinityWeight
+	}
+
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+				{
+					Weight: weight,
+					Preference: corev1.NodeSelectorTerm{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      cacheLabelKey(cfg, model),
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{value},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// argsTemplateData is the data available to templated container args
+type argsTemplateData struct {
+	Model   string
+	Quant   string
+	Runtime string
+}
+
+// renderContainerArgs returns a copy of the runtime config with the model, quant and runtime
+// templated into its container args
+func renderContainerArgs(runtimeConfig *RuntimeConfig, model, quant string) (*RuntimeConfig, error) {
+	if len(runtimeConfig.Args) == 0 {
+		return runtimeConfig, nil
+	}
+
+	data := argsTemplateData{Model: model, Quant: quant, Runtime: runtimeConfig.Name}
+	rendered := *runtimeConfig
+	rendered.Args = make([]string, len(runtimeConfig.Args))
+	for i, arg := range runtimeConfig.Args {
+		tmpl, err := template.New("arg").Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid arg template %q for runtime %s: %w", arg, runtimeConfig.Name, err)
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("failed to render arg %q for runtime %s: %w", arg, runtimeConfig.Name, err)
+		}
+		rendered.Args[i] = b.String()
+	}
+
+	return &rendered, nil
+}
+
 // buildDeploymentManifest creates a Kubernetes Deployment manifest for a worker
-func buildDeploymentManifest(namespace, name, model, runtime, quant string, runtimeConfig *RuntimeConfig, modelConfig *ModelConfig) *appsv1.Deployment {
-	replicas := int32(1)
+func buildDeploymentManifest(namespace, name, model, runtime, quant string, runtimeConfig *RuntimeConfig, modelConfig *ModelConfig) (*appsv1.Deployment, error) {
+	replicas := runtimeConfig.replicas()
+
+	// Parse quantities up front so a bad profile or runtimes.yaml value surfaces as an error
+	// rather than a panic
+	cpu, err := resource.ParseQuantity(runtimeConfig.CPU)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cpu %q for runtime %s: %v", ErrInvalidDeploy, runtimeConfig.CPU, runtime, err)
+	}
+	mem, err := resource.ParseQuantity(runtimeConfig.Mem)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid mem %q for runtime %s: %v", ErrInvalidDeploy, runtimeConfig.Mem, runtime, err)
+	}
 
 	// Create labels
+	// The name label is what the worker Service selects on
 	labels := map[string]string{
 		"app":     "worker",
+		"name":    name,
 		"runtime": runtime,
 		"model":   slugify(model),
 	}
@@ -48,27 +211,44 @@ func buildDeploymentManifest(namespace, name, model, runtime, quant string, runt
 	// Create resource requirements
 	resources := corev1.ResourceRequirements{
 		Limits: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse(runtimeConfig.CPU),
-			corev1.ResourceMemory: resource.MustParse(runtimeConfig.Mem),
+			corev1.ResourceCPU:    cpu,
+			corev1.ResourceMemory: mem,
 		},
 		Requests: corev1.ResourceList{
-			corev1.ResourceCPU:    resource.MustParse(runtimeConfig.CPU),
-			corev1.ResourceMemory: resource.MustParse(runtimeConfig.Mem),
+			corev1.ResourceCPU:    cpu.DeepCopy(),
+			corev1.ResourceMemory: mem.DeepCopy(),
 		},
 	}
 
-	// Add GPU if required
+	// Add GPU if required, otherwise tell the worker to run CPU-only
 	if runtimeConfig.GPU > 0 {
-		resources.Limits["nvidia.com/gpu"] = resource.MustParse(fmt.Sprintf("%d", runtimeConfig.GPU))
-		resources.Requests["nvidia.com/gpu"] = resource.MustParse(fmt.Sprintf("%d", runtimeConfig.GPU))
+		resources.Limits["nvidia.com/gpu"] = *resource.NewQuantity(int64(runtimeConfig.GPU), resource.DecimalSI)
+		resources.Requests["nvidia.com/gpu"] = *resource.NewQuantity(int64(runtimeConfig.GPU), resource.DecimalSI)
+	} else {
+		env = append(env, corev1.EnvVar{
+			Name:  "CPU_ONLY",
+			Value: "true",
+		})
+	}
+
+	// Record the resource profile and overrides so drift detection can rebuild the same
+	// manifest, and the config hash so the deployment can be traced back to the config that
+	// produced it
+	annotations := map[string]string{configHashAnnotation: ConfigHash(runtimeConfig, modelConfig, quant)}
+	if runtimeConfig.profile != "" {
+		annotations[profileAnnotation] = runtimeConfig.profile
+	}
+	if runtimeConfig.overrides != "" {
+		annotations[resourceOverridesAnnotation] = runtimeConfig.overrides
 	}
 
 	// Create deployment
-	return &appsv1.Deployment{
+	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-			Labels:    labels,
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
@@ -80,11 +260,14 @@ func buildDeploymentManifest(namespace, name, model, runtime, quant string, runt
 					Labels: labels,
 				},
 				Spec: corev1.PodSpec{
+					Affinity: buildCacheAffinity(runtimeConfig.CacheAffinity, model),
 					Containers: []corev1.Container{
 						{
 							Name:            "worker",
-							Image:           runtimeConfig.Image,
+							Image:           runtimeConfig.ImageForQuant(quant),
 							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         runtimeConfig.Command,
+							Args:            runtimeConfig.Args,
 							Env:             env,
 							Resources:       resources,
 							Ports: []corev1.ContainerPort{
@@ -97,7 +280,7 @@ func buildDeploymentManifest(namespace, name, model, runtime, quant string, runt
 							ReadinessProbe: &corev1.Probe{
 								ProbeHandler: corev1.ProbeHandler{
 									HTTPGet: &corev1.HTTPGetAction{
-										Path: "/healthz",
+										Path: runtimeConfig.readinessPath(),
 										Port: intstr.FromInt(8000),
 									},
 								},
@@ -107,12 +290,14 @@ func buildDeploymentManifest(namespace, name, model, runtime, quant string, runt
 								SuccessThreshold:    1,
 								FailureThreshold:    3,
 							},
+							StartupProbe: buildStartupProbe(runtimeConfig.StartupProbe, runtimeConfig.readinessPath()),
 						},
 					},
 				},
 			},
 		},
 	}
+	return deployment, nil
 }
 
 // buildServiceManifest creates a Kubernetes Service manifest for a worker
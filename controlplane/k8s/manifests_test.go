@@ -0,0 +1,365 @@
+package k8s
+
+import (
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func testRuntimeConfig() *RuntimeConfig {
+	return &RuntimeConfig{
+		Name:  "vllm",
+		Image: "ghcr.io/tokenforge/worker-vllm:latest",
+		GPU:   1,
+		CPU:   "2",
+		Mem:   "16Gi",
+	}
+}
+
+func testModelConfig() *ModelConfig {
+	return &ModelConfig{
+		Name:  "meta-llama/Llama-3-8b-instruct",
+		Quant: "fp16",
+		Hash:  "sha256:test",
+	}
+}
+
+func mustBuildDeploymentManifest(t *testing.T, namespace, name, model, runtime, quant string, runtimeConfig *RuntimeConfig, modelConfig *ModelConfig) *appsv1.Deployment {
+	t.Helper()
+	deployment, err := buildDeploymentManifest(namespace, name, model, runtime, quant, runtimeConfig, modelConfig)
+	if err != nil {
+		t.Fatalf("buildDeploymentManifest failed: %v", err)
+	}
+	return deployment
+}
+
+func TestBuildDeploymentManifestRejectsInvalidQuantity(t *testing.T) {
+	runtimeConfig := testRuntimeConfig()
+	runtimeConfig.Mem = "lots"
+
+	_, err := buildDeploymentManifest("default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", runtimeConfig, testModelConfig())
+	if !errors.Is(err, ErrInvalidDeploy) {
+		t.Errorf("Expected ErrInvalidDeploy for an unparseable mem, got %v", err)
+	}
+}
+
+func TestApplyDeployOptionsRejectsInvalidProfileQuantity(t *testing.T) {
+	runtimeConfig := testRuntimeConfig()
+	runtimeConfig.Profiles = map[string]RuntimeProfile{
+		"small": {CPU: "two"},
+	}
+
+	_, err := applyDeployOptions(runtimeConfig, DeployOptions{Profile: "small"})
+	if !errors.Is(err, ErrInvalidDeploy) {
+		t.Errorf("Expected ErrInvalidDeploy for an unparseable profile cpu, got %v", err)
+	}
+}
+
+func TestBuildDeploymentManifestWithoutStartupProbe(t *testing.T) {
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", testRuntimeConfig(), testModelConfig())
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.StartupProbe != nil {
+		t.Errorf("Expected no startup probe by default, got %+v", container.StartupProbe)
+	}
+	if container.ReadinessProbe == nil {
+		t.Error("Expected readiness probe to be set")
+	}
+}
+
+func TestBuildDeploymentManifestWithStartupProbe(t *testing.T) {
+	runtimeConfig := testRuntimeConfig()
+	runtimeConfig.StartupProbe = &StartupProbeConfig{FailureThreshold: 120}
+
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", runtimeConfig, testModelConfig())
+
+	probe := deployment.Spec.Template.Spec.Containers[0].StartupProbe
+	if probe == nil {
+		t.Fatal("Expected startup probe to be set")
+	}
+	if probe.FailureThreshold != 120 {
+		t.Errorf("Expected failure threshold 120, got %d", probe.FailureThreshold)
+	}
+	if probe.PeriodSeconds != defaultStartupPeriodSeconds {
+		t.Errorf("Expected default period %d, got %d", defaultStartupPeriodSeconds, probe.PeriodSeconds)
+	}
+	if probe.HTTPGet == nil || probe.HTTPGet.Path != "/healthz" {
+		t.Errorf("Expected startup probe to check /healthz, got %+v", probe.HTTPGet)
+	}
+}
+
+func TestBuildDeploymentManifestCacheAffinity(t *testing.T) {
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", testRuntimeConfig(), testModelConfig())
+	if deployment.Spec.Template.Spec.Affinity != nil {
+		t.Errorf("Expected no affinity unless cache_affinity is configured, got %+v", deployment.Spec.Template.Spec.Affinity)
+	}
+
+	runtimeConfig := testRuntimeConfig()
+	runtimeConfig.CacheAffinity = &CacheAffinityConfig{}
+	deployment = mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", runtimeConfig, testModelConfig())
+
+	affinity := deployment.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil {
+		t.Fatal("Expected a node affinity")
+	}
+	if affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		t.Error("Expected cache affinity to be a preference, not a requirement")
+	}
+	terms := affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	if len(terms) != 1 || terms[0].Weight != maxCacheAffinityWeight {
+		t.Fatalf("Expected one preferred term with weight %d, got %+v", maxCacheAffinityWeight, terms)
+	}
+	expr := terms[0].Preference.MatchExpressions[0]
+	wantKey := "model-cache/" + slugify("meta-llama/Llama-3-8b-instruct")
+	if expr.Key != wantKey || expr.Operator != corev1.NodeSelectorOpIn || len(expr.Values) != 1 || expr.Values[0] != "present" {
+		t.Errorf("Expected %s in [present], got %+v", wantKey, expr)
+	}
+}
+
+func TestBuildCacheAffinityCustomLabel(t *testing.T) {
+	affinity := buildCacheAffinity(&CacheAffinityConfig{LabelPrefix: "cache.example.com", Value: "warm", Weight: 50}, "llama3-8b")
+
+	term := affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[0]
+	expr := term.Preference.MatchExpressions[0]
+	if term.Weight != 50 || expr.Key != "cache.example.com/"+slugify("llama3-8b") || expr.Values[0] != "warm" {
+		t.Errorf("Expected the configured label, value and weight, got weight %d and %+v", term.Weight, expr)
+	}
+}
+
+func TestBuildDeploymentManifestReadinessPath(t *testing.T) {
+	runtimeConfig := testRuntimeConfig()
+	runtimeConfig.ReadinessPath = "/ready"
+	runtimeConfig.StartupProbe = &StartupProbeConfig{}
+
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", runtimeConfig, testModelConfig())
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if path := container.ReadinessProbe.HTTPGet.Path; path != "/ready" {
+		t.Errorf("Expected readiness probe on /ready, got %s", path)
+	}
+	if path := container.StartupProbe.HTTPGet.Path; path != "/ready" {
+		t.Errorf("Expected startup probe on /ready, got %s", path)
+	}
+}
+
+func TestValidateQuant(t *testing.T) {
+	runtimeConfig := testRuntimeConfig()
+	runtimeConfig.Quants = []string{"fp16", "int8"}
+
+	if err := validateQuant(runtimeConfig, "int8"); err != nil {
+		t.Errorf("Expected int8 to be supported, got %v", err)
+	}
+	err := validateQuant(runtimeConfig, "awq")
+	if err == nil || !errors.Is(err, ErrInvalidDeploy) {
+		t.Errorf("Expected unsupported quant to wrap ErrInvalidDeploy, got %v", err)
+	}
+
+	runtimeConfig.Quants = nil
+	if err := validateQuant(runtimeConfig, "awq"); err != nil {
+		t.Errorf("Expected runtime without quants to accept any quant, got %v", err)
+	}
+}
+
+func TestBuildDeploymentManifestTemplatedArgs(t *testing.T) {
+	runtimeConfig := testRuntimeConfig()
+	runtimeConfig.Command = []string{"python", "-m", "vllm.entrypoints.api_server"}
+	runtimeConfig.Args = []string{"--model", "{{.Model}}", "--quantization={{.Quant}}", "--max-model-len", "8192"}
+
+	rendered, err := renderContainerArgs(runtimeConfig, "meta-llama/Llama-3-8b-instruct", "awq")
+	if err != nil {
+		t.Fatalf("Failed to render args: %v", err)
+	}
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "awq", rendered, testModelConfig())
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	wantArgs := []string{"--model", "meta-llama/Llama-3-8b-instruct", "--quantization=awq", "--max-model-len", "8192"}
+	if len(container.Args) != len(wantArgs) {
+		t.Fatalf("Expected args %v, got %v", wantArgs, container.Args)
+	}
+	for i := range wantArgs {
+		if container.Args[i] != wantArgs[i] {
+			t.Errorf("Arg %d: expected %q, got %q", i, wantArgs[i], container.Args[i])
+		}
+	}
+	if len(container.Command) != 3 || container.Command[0] != "python" {
+		t.Errorf("Expected command override, got %v", container.Command)
+	}
+
+	// The runtime config itself keeps the templates
+	if runtimeConfig.Args[1] != "{{.Model}}" {
+		t.Errorf("Expected rendering not to modify the runtime config, got %q", runtimeConfig.Args[1])
+	}
+}
+
+func TestBuildDeploymentManifestDefaultEntrypoint(t *testing.T) {
+	rendered, err := renderContainerArgs(testRuntimeConfig(), "meta-llama/Llama-3-8b-instruct", "fp16")
+	if err != nil {
+		t.Fatalf("Failed to render args: %v", err)
+	}
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", rendered, testModelConfig())
+
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if container.Command != nil || container.Args != nil {
+		t.Errorf("Expected default entrypoint, got command %v args %v", container.Command, container.Args)
+	}
+}
+
+func TestRenderContainerArgsRejectsUnknownField(t *testing.T) {
+	runtimeConfig := testRuntimeConfig()
+	runtimeConfig.Args = []string{"--model", "{{.Unknown}}"}
+
+	if _, err := renderContainerArgs(runtimeConfig, "test-model", "fp16"); err == nil {
+		t.Errorf("Expected error for unknown template field")
+	}
+}
+
+func TestApplyDeployOptionsMergesProfile(t *testing.T) {
+	runtimeConfig := testRuntimeConfig()
+	gpus := 2
+	runtimeConfig.Profiles = map[string]RuntimeProfile{
+		"large": {GPU: &gpus, Mem: "64Gi", Replicas: 3},
+	}
+
+	merged, err := applyDeployOptions(runtimeConfig, DeployOptions{Profile: "large"})
+	if err != nil {
+		t.Fatalf("Expected profile to apply, got %v", err)
+	}
+	if merged.GPU != 2 || merged.Mem != "64Gi" || merged.CPU != "2" {
+		t.Errorf("Expected profile merged over base resources, got gpu=%d cpu=%s mem=%s", merged.GPU, merged.CPU, merged.Mem)
+	}
+	if runtimeConfig.Mem != "16Gi" {
+		t.Errorf("Expected base config to be left untouched, got mem=%s", runtimeConfig.Mem)
+	}
+
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", merged, testModelConfig())
+	if *deployment.Spec.Replicas != 3 {
+		t.Errorf("Expected 3 replicas from the profile, got %d", *deployment.Spec.Replicas)
+	}
+	if got := deployment.Annotations[profileAnnotation]; got != "large" {
+		t.Errorf("Expected profile annotation large, got %q", got)
+	}
+}
+
+func TestApplyDeployOptionsRejectsUnknownProfile(t *testing.T) {
+	_, err := applyDeployOptions(testRuntimeConfig(), DeployOptions{Profile: "huge"})
+	if !errors.Is(err, ErrInvalidDeploy) {
+		t.Errorf("Expected ErrInvalidDeploy for an undefined profile, got %v", err)
+	}
+}
+
+func TestApplyDeployOptionsMergesResourceOverrides(t *testing.T) {
+	runtimeConfig := testRuntimeConfig()
+	gpus := 2
+	runtimeConfig.Profiles = map[string]RuntimeProfile{
+		"large": {GPU: &gpus, Mem: "64Gi"},
+	}
+
+	merged, err := applyDeployOptions(runtimeConfig, DeployOptions{Profile: "large", CPU: "8", Mem: "96Gi"})
+	if err != nil {
+		t.Fatalf("Expected overrides to apply, got %v", err)
+	}
+	if merged.GPU != 2 || merged.CPU != "8" || merged.Mem != "96Gi" {
+		t.Errorf("Expected overrides merged over the profile, got gpu=%d cpu=%s mem=%s", merged.GPU, merged.CPU, merged.Mem)
+	}
+
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", merged, testModelConfig())
+	limits := deployment.Spec.Template.Spec.Containers[0].Resources.Limits
+	if got := limits.Memory().String(); got != "96Gi" {
+		t.Errorf("Expected memory limit 96Gi, got %s", got)
+	}
+	if got := deployment.Annotations[resourceOverridesAnnotation]; got != `{"cpu":"8","mem":"96Gi"}` {
+		t.Errorf("Expected overrides annotation, got %q", got)
+	}
+
+	for _, opts := range []DeployOptions{{Mem: "lots"}, {CPU: "-1"}, {Mem: "0"}} {
+		if _, err := applyDeployOptions(testRuntimeConfig(), opts); !errors.Is(err, ErrInvalidDeploy) {
+			t.Errorf("Expected ErrInvalidDeploy for %+v, got %v", opts, err)
+		}
+	}
+}
+
+func TestApplyDeployOptionsCPUOnly(t *testing.T) {
+	runtimeConfig := testRuntimeConfig()
+	runtimeConfig.SupportsCPU = true
+	cpuOnly := 0
+
+	merged, err := applyDeployOptions(runtimeConfig, DeployOptions{GPU: &cpuOnly})
+	if err != nil {
+		t.Fatalf("Expected a CPU-only variant of a CPU-capable runtime, got %v", err)
+	}
+	if merged.GPU != 0 {
+		t.Errorf("Expected no GPUs, got %d", merged.GPU)
+	}
+
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", merged, testModelConfig())
+	container := deployment.Spec.Template.Spec.Containers[0]
+	if _, ok := container.Resources.Limits["nvidia.com/gpu"]; ok {
+		t.Errorf("Expected no GPU limit on a CPU-only worker")
+	}
+	if got := envValue(container.Env, "CPU_ONLY"); got != "true" {
+		t.Errorf("Expected CPU_ONLY=true, got %q", got)
+	}
+	if got := deployment.Annotations[resourceOverridesAnnotation]; got != `{"gpu":0}` {
+		t.Errorf("Expected the gpu override to be recorded, got %q", got)
+	}
+
+	gpuDeployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", testRuntimeConfig(), testModelConfig())
+	if got := envValue(gpuDeployment.Spec.Template.Spec.Containers[0].Env, "CPU_ONLY"); got != "" {
+		t.Errorf("Expected GPU workers not to set CPU_ONLY, got %q", got)
+	}
+}
+
+func TestApplyDeployOptionsRejectsUnsupportedCPUOnly(t *testing.T) {
+	cpuOnly := 0
+	_, err := applyDeployOptions(testRuntimeConfig(), DeployOptions{GPU: &cpuOnly})
+	if !errors.Is(err, ErrInvalidDeploy) {
+		t.Errorf("Expected ErrInvalidDeploy for a CPU-only deploy of a GPU-only runtime, got %v", err)
+	}
+
+	// A profile without GPUs is rejected the same way
+	runtimeConfig := testRuntimeConfig()
+	runtimeConfig.Profiles = map[string]RuntimeProfile{"cpu": {GPU: &cpuOnly}}
+	if _, err := applyDeployOptions(runtimeConfig, DeployOptions{Profile: "cpu"}); !errors.Is(err, ErrInvalidDeploy) {
+		t.Errorf("Expected ErrInvalidDeploy for a GPU-less profile of a GPU-only runtime, got %v", err)
+	}
+
+	negative := -1
+	if _, err := applyDeployOptions(testRuntimeConfig(), DeployOptions{GPU: &negative}); !errors.Is(err, ErrInvalidDeploy) {
+		t.Errorf("Expected ErrInvalidDeploy for a negative gpu count, got %v", err)
+	}
+}
+
+func TestServiceSelectorMatchesPodLabels(t *testing.T) {
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", testRuntimeConfig(), testModelConfig())
+	service := buildServiceManifest("default", "worker-vllm-test", "worker-vllm-test")
+
+	podLabels := deployment.Spec.Template.Labels
+	for key, value := range service.Spec.Selector {
+		if podLabels[key] != value {
+			t.Errorf("Service selector %s=%s does not match pod label %q", key, value, podLabels[key])
+		}
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	hash := ConfigHash(testRuntimeConfig(), testModelConfig(), "fp16")
+	if hash != ConfigHash(testRuntimeConfig(), testModelConfig(), "fp16") {
+		t.Error("Expected the config hash to be stable")
+	}
+	if hash == ConfigHash(testRuntimeConfig(), testModelConfig(), "int8") {
+		t.Error("Expected the quant to change the config hash")
+	}
+
+	withProfiles := testRuntimeConfig()
+	withProfiles.Profiles = map[string]RuntimeProfile{"large": {Mem: "64Gi"}}
+	if hash != ConfigHash(withProfiles, testModelConfig(), "fp16") {
+		t.Error("Expected unused profiles not to change the config hash")
+	}
+
+	deployment := mustBuildDeploymentManifest(t, "default", "worker-vllm-test", "meta-llama/Llama-3-8b-instruct", "vllm", "fp16", testRuntimeConfig(), testModelConfig())
+	if got := deployment.Annotations[configHashAnnotation]; got != hash {
+		t.Errorf("Expected config hash annotation %s, got %s", hash, got)
+	}
+}
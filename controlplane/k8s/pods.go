@@ -0,0 +1,49 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ErrPodNotInDeployment is returned when a targeted pod does not back the deployment
+var ErrPodNotInDeployment = errors.New("pod does not belong to deployment")
+
+// PodURL returns the direct worker URL of a running pod after checking that it is selected
+// by the deployment, so requests can bypass the load-balanced service
+func PodURL(ctx context.Context, namespace, deploymentName, podName string) (string, error) {
+	client, err := NewClient()
+	if err != nil {
+		return "", err
+	}
+
+	deployment, err := client.getDeployment(ctx, namespace, deploymentName)
+	if err != nil {
+		return "", err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return "", fmt.Errorf("invalid selector on deployment %s: %w", deploymentName, err)
+	}
+
+	pod, err := client.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", fmt.Errorf("%w: pod %s not found", ErrPodNotInDeployment, podName)
+		}
+		return "", err
+	}
+	if !selector.Matches(labels.Set(pod.Labels)) {
+		return "", fmt.Errorf("%w: pod %s is not part of %s", ErrPodNotInDeployment, podName, deploymentName)
+	}
+	if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+		return "", fmt.Errorf("pod %s is not running", podName)
+	}
+
+	return fmt.Sprintf("%s://%s:8000", workerScheme(), pod.Status.PodIP), nil
+}
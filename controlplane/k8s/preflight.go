@@ -0,0 +1,156 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// gpuResource is the extended resource name used for NVIDIA GPUs
+const gpuResource corev1.ResourceName = "nvidia.com/gpu"
+
+// PreflightCheck is the outcome of a single deploy validation
+type PreflightCheck struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// Preflight runs the deploy validations for a model and runtime without creating anything.
+// Config checks run even when the cluster is unreachable; cluster checks then fail.
+func Preflight(ctx context.Context, model, runtime, quant string, opts DeployOptions) []PreflightCheck {
+	var checks []PreflightCheck
+	add := func(name string, err error, okMessage string) bool {
+		check := PreflightCheck{Name: name, Passed: err == nil, Message: okMessage}
+		if err != nil {
+			check.Message = err.Error()
+		}
+		checks = append(checks, check)
+		return err == nil
+	}
+
+	client, clientErr := NewClient()
+	if clientErr != nil {
+		client = &Client{}
+	}
+
+	runtimeConfig, err := client.loadRuntimeConfig(runtime)
+	if !add("runtime_config", err, fmt.Sprintf("runtime %s is configured", runtime)) {
+		return checks
+	}
+	modelConfig, err := client.loadModelConfig(model)
+	add("model_config", err, fmt.Sprintf("model %s is configured", model))
+	if quant == "" && modelConfig != nil {
+		quant = modelConfig.defaultQuant()
+	}
+	add("quant", validateQuant(runtimeConfig, quant), fmt.Sprintf("quant %q is supported", quant))
+
+	runtimeConfig, err = applyDeployOptions(runtimeConfig, withDefaultProfile(opts))
+	if !add("deploy_options", err, "deploy options are valid") {
+		return checks
+	}
+
+	if clientErr != nil {
+		add("gpu_capacity", clientErr, "")
+		add("image", clientErr, "")
+		return checks
+	}
+
+	message, err := client.checkGPUCapacity(ctx, runtimeConfig.GPU)
+	add("gpu_capacity", err, message)
+	message, err = client.checkImage(ctx, runtimeConfig.ImageForQuant(quant))
+	add("image", err, message)
+
+	return checks
+}
+
+// checkGPUCapacity reports whether any node has enough unallocated GPUs for a worker
+func (c *Client) checkGPUCapacity(ctx context.Context, required int) (string, error) {
+	if required == 0 {
+		return "no GPUs required", nil
+	}
+
+	nodes, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := c.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	used := make(map[string]int64)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			if q, ok := container.Resources.Requests[gpuResource]; ok {
+				used[pod.Spec.NodeName] += q.Value()
+			} else if q, ok := container.Resources.Limits[gpuResource]; ok {
+				used[pod.Spec.NodeName] += q.Value()
+			}
+		}
+	}
+
+	var mostFree int64
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		allocatable := node.Status.Allocatable[gpuResource]
+		free := allocatable.Value() - used[node.Name]
+		if free >= int64(required) {
+			return fmt.Sprintf("node %s has %d of %s GPUs free", node.Name, free, allocatable.String()), nil
+		}
+		if free > mostFree {
+			mostFree = free
+		}
+	}
+
+	return "", fmt.Errorf("no node has %d free GPUs (most free on a node: %d)", required, mostFree)
+}
+
+// checkImage looks for the image on the cluster's nodes and for existing pods failing to pull it.
+// An image that is neither cached nor known to fail is assumed pullable.
+func (c *Client) checkImage(ctx context.Context, image string) (string, error) {
+	if image == "" {
+		return "", fmt.Errorf("runtime has no image configured")
+	}
+
+	nodes, err := c.clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes: %w", err)
+	}
+	for _, node := range nodes.Items {
+		for _, cached := range node.Status.Images {
+			for _, name := range cached.Names {
+				if name == image {
+					return fmt.Sprintf("image %s is cached on node %s", image, node.Name), nil
+				}
+			}
+		}
+	}
+
+	pods, err := c.clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Image != image || status.State.Waiting == nil {
+				continue
+			}
+			switch status.State.Waiting.Reason {
+			case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+				return "", fmt.Errorf("image %s failed to pull for pod %s/%s: %s", image, pod.Namespace, pod.Name, status.State.Waiting.Message)
+			}
+		}
+	}
+
+	return fmt.Sprintf("image %s is not cached on any node and will be pulled on deploy", image), nil
+}
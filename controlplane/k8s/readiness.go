@@ -0,0 +1,100 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	appsv1 "k8s.io/api/apps/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Readiness states reported by CheckReadiness
+const (
+	ReadinessPodsNotReady = "pods_not_ready"
+	ReadinessNoEndpoints  = "no_endpoints"
+	ReadinessReady        = "ready"
+)
+
+// ErrNoEndpoints is returned when a worker's pods are ready but its Service has no ready
+// endpoints, usually because the Service selector does not match the pod labels
+var ErrNoEndpoints = errors.New("pods are ready but the service has no ready endpoints")
+
+// requireEndpoints reports whether readiness also requires the Service to have a ready
+// endpoint; READINESS_REQUIRE_ENDPOINTS=false checks the pods only
+func requireEndpoints() bool {
+	return os.Getenv("READINESS_REQUIRE_ENDPOINTS") != "false"
+}
+
+// CheckReadiness reports whether a worker can serve traffic: all replicas must be ready and,
+// unless disabled, the Service must have at least one ready endpoint
+func CheckReadiness(ctx context.Context, namespace, deploymentName, serviceName string) (string, error) {
+	client, err := NewClient()
+	if err != nil {
+		return "", err
+	}
+
+	deployment, err := client.clientset.AppsV1().Deployments(namespace).Get(ctx, deploymentName, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	return deploymentReadiness(ctx, client.clientset, deployment, serviceName)
+}
+
+// deploymentReadiness reports the readiness state of an already fetched deployment, listing
+// its Service endpoints only once the replicas are ready
+func deploymentReadiness(ctx context.Context, clientset kubernetes.Interface, deployment *appsv1.Deployment, serviceName string) (string, error) {
+	var slices []discoveryv1.EndpointSlice
+	check := requireEndpoints() && serviceName != "" && replicasReady(deployment)
+	if check {
+		list, err := clientset.DiscoveryV1().EndpointSlices(deployment.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: discoveryv1.LabelServiceName + "=" + serviceName,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list endpoints for service %s: %w", serviceName, err)
+		}
+		slices = list.Items
+	}
+
+	return readinessState(deployment, slices, check), nil
+}
+
+// readinessState combines the deployment's replica status with its Service endpoints
+func readinessState(deployment *appsv1.Deployment, slices []discoveryv1.EndpointSlice, checkEndpoints bool) string {
+	if !replicasReady(deployment) {
+		return ReadinessPodsNotReady
+	}
+	if checkEndpoints && !hasReadyEndpoint(slices) {
+		return ReadinessNoEndpoints
+	}
+	return ReadinessReady
+}
+
+// replicasReady reports whether every desired replica of the deployment is ready
+func replicasReady(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.ReadyReplicas == replicas
+}
+
+// hasReadyEndpoint reports whether any endpoint with an address is ready. Endpoints without
+// a ready condition are treated as ready, as the EndpointSlice API specifies.
+func hasReadyEndpoint(slices []discoveryv1.EndpointSlice) bool {
+	for _, slice := range slices {
+		for _, endpoint := range slice.Endpoints {
+			if len(endpoint.Addresses) == 0 {
+				continue
+			}
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package k8s
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+)
+
+func TestReadinessState(t *testing.T) {
+	replicas := int32(1)
+	notReady := false
+	ready := &appsv1.Deployment{
+		Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+	starting := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &replicas}}
+
+	withEndpoint := []discoveryv1.EndpointSlice{{Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.5"}}}}}
+	unreadyEndpoint := []discoveryv1.EndpointSlice{{Endpoints: []discoveryv1.Endpoint{{
+		Addresses:  []string{"10.0.0.5"},
+		Conditions: discoveryv1.EndpointConditions{Ready: &notReady},
+	}}}}
+
+	tests := []struct {
+		name       string
+		deployment *appsv1.Deployment
+		slices     []discoveryv1.EndpointSlice
+		check      bool
+		want       string
+	}{
+		{"pods starting", starting, withEndpoint, true, ReadinessPodsNotReady},
+		{"reachable", ready, withEndpoint, true, ReadinessReady},
+		{"no endpoint slices", ready, nil, true, ReadinessNoEndpoints},
+		{"only unready endpoints", ready, unreadyEndpoint, true, ReadinessNoEndpoints},
+		{"endpoint check disabled", ready, nil, false, ReadinessReady},
+	}
+	for _, tt := range tests {
+		if got := readinessState(tt.deployment, tt.slices, tt.check); got != tt.want {
+			t.Errorf("%s: got %s want %s", tt.name, got, tt.want)
+		}
+	}
+}
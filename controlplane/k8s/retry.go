@@ -0,0 +1,107 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+const (
+	// defaultRetryAttempts is how many times a Kubernetes API call is tried when K8S_RETRY_ATTEMPTS is unset
+	defaultRetryAttempts = 4
+	// defaultRetryBackoff is the delay before the first retry when K8S_RETRY_BACKOFF is unset
+	defaultRetryBackoff = 500 * time.Millisecond
+	// maxRetryBackoff caps the exponential backoff between retries
+	maxRetryBackoff = 10 * time.Second
+	// defaultCallTimeout bounds a call and all of its retries when K8S_CALL_TIMEOUT is unset
+	defaultCallTimeout = 30 * time.Second
+)
+
+// retryPolicy controls how Kubernetes API calls are retried on transient errors
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+	timeout  time.Duration
+}
+
+// retryPolicyFromEnv reads the policy from K8S_RETRY_ATTEMPTS, K8S_RETRY_BACKOFF and
+// K8S_CALL_TIMEOUT
+func retryPolicyFromEnv() retryPolicy {
+	policy := retryPolicy{attempts: defaultRetryAttempts, backoff: defaultRetryBackoff, timeout: defaultCallTimeout}
+	if v, err := strconv.Atoi(os.Getenv("K8S_RETRY_ATTEMPTS")); err == nil && v > 0 {
+		policy.attempts = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("K8S_RETRY_BACKOFF")); err == nil && v > 0 {
+		policy.backoff = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("K8S_CALL_TIMEOUT")); err == nil && v > 0 {
+		policy.timeout = v
+	}
+	return policy
+}
+
+// isRetryable reports whether a Kubernetes API error is likely transient. Validation,
+// authorization, not-found and conflict errors are not retried.
+func isRetryable(err error) bool {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return false
+	case apierrors.IsTooManyRequests(err),
+		apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err),
+		apierrors.IsServiceUnavailable(err),
+		apierrors.IsInternalError(err):
+		return true
+	case utilnet.IsConnectionRefused(err), utilnet.IsConnectionReset(err), utilnet.IsProbableEOF(err):
+		return true
+	}
+	return false
+}
+
+// retryDelay returns how long to wait before retrying after the given attempt (starting at 1),
+// honoring the Retry-After the API server sent with a 429
+func (p retryPolicy) retryDelay(err error, attempt int) time.Duration {
+	if seconds, ok := apierrors.SuggestsClientDelay(err); ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	delay := p.backoff << (attempt - 1)
+	if delay <= 0 || delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
+
+// do runs call until it succeeds, fails with a non-retryable error, runs out of attempts or
+// the policy's timeout expires. The call receives a context bounded by the timeout.
+func (p retryPolicy) do(ctx context.Context, call func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = call(ctx)
+		if !isRetryable(err) || attempt >= p.attempts {
+			return err
+		}
+
+		delay := p.retryDelay(err, attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// withRetry runs a Kubernetes API call under the policy configured in the environment
+func withRetry(ctx context.Context, call func(ctx context.Context) error) error {
+	return retryPolicyFromEnv().do(ctx, call)
+}
@@ -0,0 +1,80 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestRetryPolicyRetriesTransientErrors(t *testing.T) {
+	policy := retryPolicy{attempts: 4, backoff: time.Millisecond, timeout: time.Second}
+
+	calls := 0
+	err := policy.do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return apierrors.NewTooManyRequests("busy", 0)
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("Expected success on the third attempt, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = policy.do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return apierrors.NewServiceUnavailable("down")
+	})
+	if !apierrors.IsServiceUnavailable(err) || calls != 4 {
+		t.Errorf("Expected the last error after 4 attempts, got %v after %d calls", err, calls)
+	}
+}
+
+func TestRetryPolicySurfacesNonRetryableErrors(t *testing.T) {
+	policy := retryPolicy{attempts: 4, backoff: time.Millisecond, timeout: time.Second}
+	resource := schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+	for _, callErr := range []error{
+		apierrors.NewForbidden(resource, "worker", nil),
+		apierrors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, "worker", nil),
+		apierrors.NewAlreadyExists(resource, "worker"),
+	} {
+		calls := 0
+		err := policy.do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return callErr
+		})
+		if err != callErr || calls != 1 {
+			t.Errorf("Expected %v to be returned without retrying, got %v after %d calls", callErr, err, calls)
+		}
+	}
+}
+
+func TestRetryDelayHonorsRetryAfter(t *testing.T) {
+	policy := retryPolicy{attempts: 4, backoff: 100 * time.Millisecond, timeout: time.Minute}
+
+	if got := policy.retryDelay(apierrors.NewTooManyRequests("busy", 3), 1); got != 3*time.Second {
+		t.Errorf("Expected Retry-After of 3s, got %v", got)
+	}
+	if got := policy.retryDelay(apierrors.NewServiceUnavailable("down"), 3); got != 400*time.Millisecond {
+		t.Errorf("Expected exponential backoff of 400ms, got %v", got)
+	}
+}
+
+func TestRetryPolicyStopsAtTimeout(t *testing.T) {
+	policy := retryPolicy{attempts: 10, backoff: time.Second, timeout: 50 * time.Millisecond}
+
+	calls := 0
+	start := time.Now()
+	policy.do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return apierrors.NewTooManyRequests("busy", 0)
+	})
+	if calls != 1 || time.Since(start) > time.Second {
+		t.Errorf("Expected to give up when the next retry would pass the timeout, got %d calls in %v", calls, time.Since(start))
+	}
+}
@@ -0,0 +1,143 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrDeploymentNotFound is returned when a worker deployment does not exist in the cluster
+var ErrDeploymentNotFound = errors.New("deployment not found in cluster")
+
+// DeploymentCondition is a condition reported on a Deployment, such as Available or Progressing
+type DeploymentCondition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastUpdateTime     time.Time `json:"last_update_time"`
+	LastTransitionTime time.Time `json:"last_transition_time"`
+}
+
+// PodStatus summarizes the state of a single pod behind a deployment
+type PodStatus struct {
+	Name     string `json:"name"`
+	Phase    string `json:"phase"`
+	Ready    bool   `json:"ready"`
+	Restarts int32  `json:"restarts"`
+	// Reason explains why a container is waiting or terminated, e.g. CrashLoopBackOff
+	Reason string `json:"reason,omitempty"`
+	Node   string `json:"node,omitempty"`
+}
+
+// DeploymentK8sStatus is the live status of a worker deployment and its pods
+type DeploymentK8sStatus struct {
+	Namespace           string                `json:"namespace"`
+	Deployment          string                `json:"deployment"`
+	ConfigHash          string                `json:"config_hash,omitempty"`
+	Generation          int64                 `json:"generation"`
+	ObservedGeneration  int64                 `json:"observed_generation"`
+	DesiredReplicas     int32                 `json:"desired_replicas"`
+	Replicas            int32                 `json:"replicas"`
+	UpdatedReplicas     int32                 `json:"updated_replicas"`
+	ReadyReplicas       int32                 `json:"ready_replicas"`
+	AvailableReplicas   int32                 `json:"available_replicas"`
+	UnavailableReplicas int32                 `json:"unavailable_replicas"`
+	Conditions          []DeploymentCondition `json:"conditions"`
+	PodPhases           map[string]int        `json:"pod_phases"`
+	Pods                []PodStatus           `json:"pods"`
+}
+
+// GetDeploymentStatus returns the live status conditions and pod states of a worker deployment
+func GetDeploymentStatus(ctx context.Context, namespace, deploymentName string) (*DeploymentK8sStatus, error) {
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := client.getDeployment(ctx, namespace, deploymentName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s/%s", ErrDeploymentNotFound, namespace, deploymentName)
+		}
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on deployment %s: %w", deploymentName, err)
+	}
+
+	pods, err := client.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	return summarizeDeploymentStatus(deployment, pods.Items), nil
+}
+
+// summarizeDeploymentStatus flattens a deployment's status and its pods into a response
+func summarizeDeploymentStatus(deployment *appsv1.Deployment, pods []corev1.Pod) *DeploymentK8sStatus {
+	status := &DeploymentK8sStatus{
+		Namespace:           deployment.Namespace,
+		Deployment:          deployment.Name,
+		ConfigHash:          deployment.Annotations[configHashAnnotation],
+		Generation:          deployment.Generation,
+		ObservedGeneration:  deployment.Status.ObservedGeneration,
+		Replicas:            deployment.Status.Replicas,
+		UpdatedReplicas:     deployment.Status.UpdatedReplicas,
+		ReadyReplicas:       deployment.Status.ReadyReplicas,
+		AvailableReplicas:   deployment.Status.AvailableReplicas,
+		UnavailableReplicas: deployment.Status.UnavailableReplicas,
+		Conditions:          []DeploymentCondition{},
+		PodPhases:           map[string]int{},
+		Pods:                []PodStatus{},
+	}
+	if deployment.Spec.Replicas != nil {
+		status.DesiredReplicas = *deployment.Spec.Replicas
+	}
+
+	for _, c := range deployment.Status.Conditions {
+		status.Conditions = append(status.Conditions, DeploymentCondition{
+			Type:               string(c.Type),
+			Status:             string(c.Status),
+			Reason:             c.Reason,
+			Message:            c.Message,
+			LastUpdateTime:     c.LastUpdateTime.Time,
+			LastTransitionTime: c.LastTransitionTime.Time,
+		})
+	}
+
+	for _, pod := range pods {
+		status.PodPhases[string(pod.Status.Phase)]++
+		podStatus := PodStatus{
+			Name:  pod.Name,
+			Phase: string(pod.Status.Phase),
+			Node:  pod.Spec.NodeName,
+		}
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodReady {
+				podStatus.Ready = c.Status == corev1.ConditionTrue
+			}
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			podStatus.Restarts += cs.RestartCount
+			if podStatus.Reason != "" {
+				continue
+			}
+			if cs.State.Waiting != nil {
+				podStatus.Reason = cs.State.Waiting.Reason
+			} else if cs.State.Terminated != nil {
+				podStatus.Reason = cs.State.Terminated.Reason
+			}
+		}
+		status.Pods = append(status.Pods, podStatus)
+	}
+
+	return status
+}
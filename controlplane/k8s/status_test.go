@@ -0,0 +1,63 @@
+package k8s
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSummarizeDeploymentStatus(t *testing.T) {
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-vllm-test", Namespace: "default", Generation: 3},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 3,
+			Replicas:           2,
+			ReadyReplicas:      1,
+			Conditions: []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Reason: "MinimumReplicasUnavailable", Message: "Deployment does not have minimum availability."},
+			},
+		},
+	}
+	pods := []corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-a"},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-b"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					RestartCount: 4,
+					State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				}},
+			},
+		},
+	}
+
+	status := summarizeDeploymentStatus(deployment, pods)
+
+	if status.DesiredReplicas != 2 || status.ReadyReplicas != 1 {
+		t.Errorf("Expected 1 of 2 replicas ready, got %d of %d", status.ReadyReplicas, status.DesiredReplicas)
+	}
+	if len(status.Conditions) != 2 || status.Conditions[1].Reason != "MinimumReplicasUnavailable" {
+		t.Errorf("Expected both conditions with reasons, got %+v", status.Conditions)
+	}
+	if status.PodPhases["Running"] != 2 {
+		t.Errorf("Expected 2 running pods, got %v", status.PodPhases)
+	}
+	if !status.Pods[0].Ready || status.Pods[1].Ready {
+		t.Errorf("Expected only worker-a to be ready, got %+v", status.Pods)
+	}
+	if status.Pods[1].Restarts != 4 || status.Pods[1].Reason != "CrashLoopBackOff" {
+		t.Errorf("Expected worker-b restarts and waiting reason, got %+v", status.Pods[1])
+	}
+}
@@ -0,0 +1,115 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ErrMetricsUnavailable is returned when the cluster does not serve the metrics.k8s.io API
+var ErrMetricsUnavailable = errors.New("metrics-server is not available")
+
+// PodUsage is the current resource usage of a single worker pod
+type PodUsage struct {
+	Pod         string `json:"pod"`
+	CPUMillis   int64  `json:"cpu_millicores"`
+	MemoryBytes int64  `json:"memory_bytes"`
+}
+
+// DeploymentUsage is the current resource usage of a deployment aggregated across its pods.
+// GPU usage is not reported by metrics-server, so GPUsAllocated counts the requested GPUs.
+type DeploymentUsage struct {
+	Namespace     string     `json:"namespace"`
+	Deployment    string     `json:"deployment"`
+	Replicas      int        `json:"replicas"`
+	CPUMillis     int64      `json:"cpu_millicores"`
+	MemoryBytes   int64      `json:"memory_bytes"`
+	GPUsAllocated int64      `json:"gpus_allocated"`
+	Pods          []PodUsage `json:"pods"`
+}
+
+// podMetricsList mirrors the subset of the metrics.k8s.io PodMetricsList that is read
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Containers []struct {
+			Usage map[string]resource.Quantity `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// GetDeploymentUsage reads current CPU and memory usage for a deployment's pods from metrics-server
+func GetDeploymentUsage(ctx context.Context, namespace, deploymentName string) (*DeploymentUsage, error) {
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := client.getDeployment(ctx, namespace, deploymentName)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector on deployment %s: %w", deploymentName, err)
+	}
+
+	usage := &DeploymentUsage{
+		Namespace:  namespace,
+		Deployment: deploymentName,
+		Pods:       []PodUsage{},
+	}
+
+	pods, err := client.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	for _, pod := range pods.Items {
+		for _, container := range pod.Spec.Containers {
+			if q, ok := container.Resources.Requests[gpuResource]; ok {
+				usage.GPUsAllocated += q.Value()
+			}
+		}
+	}
+
+	raw, err := client.clientset.CoreV1().RESTClient().Get().
+		AbsPath("/apis/metrics.k8s.io/v1beta1/namespaces", namespace, "pods").
+		Param("labelSelector", selector.String()).
+		DoRaw(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+			return nil, fmt.Errorf("%w: %v", ErrMetricsUnavailable, err)
+		}
+		return nil, fmt.Errorf("failed to query pod metrics: %w", err)
+	}
+
+	var metrics podMetricsList
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		return nil, fmt.Errorf("failed to parse pod metrics: %w", err)
+	}
+
+	for _, item := range metrics.Items {
+		pod := PodUsage{Pod: item.Metadata.Name}
+		for _, container := range item.Containers {
+			if cpu, ok := container.Usage["cpu"]; ok {
+				pod.CPUMillis += cpu.MilliValue()
+			}
+			if mem, ok := container.Usage["memory"]; ok {
+				pod.MemoryBytes += mem.Value()
+			}
+		}
+		usage.CPUMillis += pod.CPUMillis
+		usage.MemoryBytes += pod.MemoryBytes
+		usage.Pods = append(usage.Pods, pod)
+	}
+	usage.Replicas = len(usage.Pods)
+
+	return usage, nil
+}
@@ -0,0 +1,110 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// defaultWatcherResync is how often the watcher replays every cached Deployment when
+	// READINESS_RESYNC_INTERVAL is unset, which catches Service endpoints that become ready
+	// after the replicas
+	defaultWatcherResync = 30 * time.Second
+	// watcherSyncTimeout bounds how long Start waits for the initial list of Deployments
+	watcherSyncTimeout = 30 * time.Second
+)
+
+// workerSelector selects the worker Deployments the watcher tracks
+const workerSelector = "app=worker"
+
+// DeploymentWatcher keeps an informer cache of the worker Deployments in a namespace, so the
+// readiness of every worker can be driven by a single watch instead of a polling loop each
+type DeploymentWatcher struct {
+	clientset kubernetes.Interface
+	factory   informers.SharedInformerFactory
+	informer  cache.SharedIndexInformer
+}
+
+// NewDeploymentWatcher creates a watcher for the worker Deployments in namespace. The
+// resync interval is read from READINESS_RESYNC_INTERVAL.
+func NewDeploymentWatcher(namespace string) (*DeploymentWatcher, error) {
+	client, err := NewClient()
+	if err != nil {
+		return nil, err
+	}
+	resync := defaultWatcherResync
+	if v, err := time.ParseDuration(os.Getenv("READINESS_RESYNC_INTERVAL")); err == nil && v > 0 {
+		resync = v
+	}
+	return newDeploymentWatcher(client.clientset, namespace, resync), nil
+}
+
+// newDeploymentWatcher creates a watcher on the given clientset
+func newDeploymentWatcher(clientset kubernetes.Interface, namespace string, resync time.Duration) *DeploymentWatcher {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, resync,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = workerSelector
+		}),
+	)
+	return &DeploymentWatcher{
+		clientset: clientset,
+		factory:   factory,
+		informer:  factory.Apps().V1().Deployments().Informer(),
+	}
+}
+
+// OnChange calls fn with every Deployment that is added or updated, and with every cached
+// Deployment on each resync. Handlers must be added before Start.
+func (w *DeploymentWatcher) OnChange(fn func(*appsv1.Deployment)) error {
+	_, err := w.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if deployment, ok := obj.(*appsv1.Deployment); ok {
+				fn(deployment)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if deployment, ok := obj.(*appsv1.Deployment); ok {
+				fn(deployment)
+			}
+		},
+	})
+	return err
+}
+
+// Start runs the watch until ctx is done and waits for the initial list to sync. An error
+// means the watch could not be established, for example because the API is not allowed to
+// list and watch Deployments; the watcher is stopped and callers should poll instead.
+func (w *DeploymentWatcher) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	w.factory.Start(ctx.Done())
+
+	syncCtx, syncCancel := context.WithTimeout(ctx, watcherSyncTimeout)
+	defer syncCancel()
+	if !cache.WaitForCacheSync(syncCtx.Done(), w.informer.HasSynced) {
+		cancel()
+		w.factory.Shutdown()
+		return errors.New("timed out waiting for the deployment watch to sync")
+	}
+
+	go func() {
+		<-ctx.Done()
+		cancel()
+		w.factory.Shutdown()
+	}()
+	return nil
+}
+
+// Readiness reports the readiness state of a watched Deployment as CheckReadiness does, using
+// the cached Deployment instead of fetching it
+func (w *DeploymentWatcher) Readiness(ctx context.Context, deployment *appsv1.Deployment, serviceName string) (string, error) {
+	return deploymentReadiness(ctx, w.clientset, deployment, serviceName)
+}
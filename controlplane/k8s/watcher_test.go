@@ -0,0 +1,100 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// testWorkerDeployment returns a worker Deployment with the given number of ready replicas
+func testWorkerDeployment(ready int32) *appsv1.Deployment {
+	replicas := int32(1)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-vllm-test", Namespace: "default", Labels: map[string]string{"app": "worker"}},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: ready},
+	}
+}
+
+func TestDeploymentWatcherDeliversChanges(t *testing.T) {
+	other := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default", Labels: map[string]string{"app": "api"}}}
+	clientset := fake.NewClientset(testWorkerDeployment(0), other)
+	watcher := newDeploymentWatcher(clientset, "default", time.Hour)
+
+	changes := make(chan *appsv1.Deployment, 10)
+	if err := watcher.OnChange(func(d *appsv1.Deployment) { changes <- d }); err != nil {
+		t.Fatalf("OnChange failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := watcher.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	select {
+	case d := <-changes:
+		if d.Name != "worker-vllm-test" {
+			t.Errorf("Expected only worker deployments, got %s", d.Name)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the existing worker deployment to be delivered")
+	}
+
+	if _, err := clientset.AppsV1().Deployments("default").UpdateStatus(ctx, testWorkerDeployment(1), metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("Failed to update deployment: %v", err)
+	}
+	select {
+	case d := <-changes:
+		if d.Status.ReadyReplicas != 1 {
+			t.Errorf("Expected the updated status, got %+v", d.Status)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the status update to be delivered")
+	}
+}
+
+func TestDeploymentWatcherReadinessListsEndpoints(t *testing.T) {
+	t.Setenv("READINESS_REQUIRE_ENDPOINTS", "true")
+	ready := true
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-vllm-test-abc", Namespace: "default", Labels: map[string]string{discoveryv1.LabelServiceName: "worker-vllm-test"}},
+		Endpoints:  []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: &ready}}},
+	}
+	watcher := newDeploymentWatcher(fake.NewClientset(slice), "default", time.Hour)
+	ctx := context.Background()
+
+	if state, err := watcher.Readiness(ctx, testWorkerDeployment(0), "worker-vllm-test"); err != nil || state != ReadinessPodsNotReady {
+		t.Errorf("Expected %s, got %s, %v", ReadinessPodsNotReady, state, err)
+	}
+	if state, err := watcher.Readiness(ctx, testWorkerDeployment(1), "worker-vllm-test"); err != nil || state != ReadinessReady {
+		t.Errorf("Expected %s, got %s, %v", ReadinessReady, state, err)
+	}
+	if state, err := watcher.Readiness(ctx, testWorkerDeployment(1), "worker-other"); err != nil || state != ReadinessNoEndpoints {
+		t.Errorf("Expected %s for a service without endpoints, got %s, %v", ReadinessNoEndpoints, state, err)
+	}
+}
+
+func TestRolloutComplete(t *testing.T) {
+	deployment := testWorkerDeployment(1)
+	deployment.Generation = 2
+	deployment.Status = appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, ReadyReplicas: 1}
+	if !RolloutComplete(deployment) {
+		t.Error("Expected the rollout to be complete")
+	}
+
+	deployment.Status.UpdatedReplicas = 0
+	if RolloutComplete(deployment) {
+		t.Error("Expected a rollout with outdated replicas to be incomplete")
+	}
+
+	deployment.Spec.Replicas = nil
+	deployment.Status.UpdatedReplicas = 1
+	if !RolloutComplete(deployment) {
+		t.Error("Expected a deployment without replicas set to default to one")
+	}
+}
@@ -0,0 +1,164 @@
+package controlplane
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane/k8s"
+	"github.com/tokenforge/llm-infra-bench/events"
+)
+
+// readinessTimeout bounds how long a deploy or restart waits for its worker to become ready
+const readinessTimeout = 5 * time.Minute
+
+// ReadinessWatcher moves registry entries to ready as Deployment change events arrive, so a
+// single shared watch replaces a polling loop per deployment
+type ReadinessWatcher struct {
+	registry *Registry
+	// readiness checks a changed Deployment; the Kubernetes watcher's check outside tests
+	readiness func(ctx context.Context, deployment *appsv1.Deployment, serviceName string) (string, error)
+
+	mu      sync.Mutex
+	waiters map[string][]chan struct{}
+}
+
+var (
+	watcherMu     sync.Mutex
+	activeWatcher *ReadinessWatcher
+)
+
+// StartReadinessWatcher watches the worker Deployments in namespace and drives the readiness
+// of the registry's entries from it. Until it returns successfully, and whenever it fails,
+// deploys and restarts poll for readiness instead.
+func StartReadinessWatcher(ctx context.Context, registry *Registry, namespace string) error {
+	watcher, err := k8s.NewDeploymentWatcher(namespace)
+	if err != nil {
+		return err
+	}
+
+	w := newReadinessWatcher(registry, watcher.Readiness)
+	if err := watcher.OnChange(func(deployment *appsv1.Deployment) { w.handle(ctx, deployment) }); err != nil {
+		return err
+	}
+	if err := watcher.Start(ctx); err != nil {
+		return err
+	}
+
+	watcherMu.Lock()
+	defer watcherMu.Unlock()
+	activeWatcher = w
+	return nil
+}
+
+// newReadinessWatcher creates a watcher for registry using the given readiness check
+func newReadinessWatcher(registry *Registry, readiness func(context.Context, *appsv1.Deployment, string) (string, error)) *ReadinessWatcher {
+	return &ReadinessWatcher{
+		registry:  registry,
+		readiness: readiness,
+		waiters:   make(map[string][]chan struct{}),
+	}
+}
+
+// readinessWatcherFor returns the running watcher for registry, or nil when readiness must be polled
+func readinessWatcherFor(registry *Registry) *ReadinessWatcher {
+	watcherMu.Lock()
+	defer watcherMu.Unlock()
+	if activeWatcher == nil || activeWatcher.registry != registry {
+		return nil
+	}
+	return activeWatcher
+}
+
+// handle updates the entries backed by a changed Deployment. Deploying entries become ready
+// once their pods and Service endpoints are, and restarting entries once the rollout completes.
+func (w *ReadinessWatcher) handle(ctx context.Context, deployment *appsv1.Deployment) {
+	for _, entry := range w.registry.GetAll() {
+		if entry.Namespace != deployment.Namespace || entry.Deployment != deployment.Name {
+			continue
+		}
+
+		ready := false
+		switch entry.Status {
+		case "restarting":
+			ready = k8s.RolloutComplete(deployment)
+		case "deploying", "no_endpoints":
+			state, err := w.readiness(ctx, deployment, entry.Service)
+			if err != nil {
+				log.Printf("Failed to check readiness of %s/%s: %v", deployment.Namespace, deployment.Name, err)
+				continue
+			}
+			if state == k8s.ReadinessNoEndpoints && entry.Status != "no_endpoints" {
+				w.registry.SetStatus(entry.Model, entry.Runtime, "no_endpoints")
+			}
+			ready = state == k8s.ReadinessReady
+		}
+		if !ready {
+			continue
+		}
+
+		if w.registry.SetStatus(entry.Model, entry.Runtime, "ready") {
+			events.Publish(ctx, events.Event{Type: events.DeploymentReady, Model: entry.Model, Runtime: entry.Runtime})
+		}
+		w.notify(makeKey(entry.Model, entry.Runtime))
+	}
+}
+
+// notify wakes everything waiting for an entry to become ready
+func (w *ReadinessWatcher) notify(key string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, ch := range w.waiters[key] {
+		close(ch)
+	}
+	delete(w.waiters, key)
+}
+
+// wait blocks until the watcher marks an entry ready or readinessTimeout passes. A timeout
+// while the entry has no endpoints returns k8s.ErrNoEndpoints.
+func (w *ReadinessWatcher) wait(ctx context.Context, model, runtime string) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	key := makeKey(model, runtime)
+	ch := make(chan struct{})
+	w.mu.Lock()
+	w.waiters[key] = append(w.waiters[key], ch)
+	w.mu.Unlock()
+	defer w.removeWaiter(key, ch)
+
+	// The entry may have become ready before the waiter was added
+	if entry, found := w.registry.Get(model, runtime); found && entry.Status == "ready" {
+		return nil
+	}
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		if entry, found := w.registry.Get(model, runtime); found && entry.Status == "no_endpoints" {
+			return fmt.Errorf("%w: service %s: %v", k8s.ErrNoEndpoints, entry.Service, ctx.Err())
+		}
+		return ctx.Err()
+	}
+}
+
+// removeWaiter drops a waiter that notify has not already woken
+func (w *ReadinessWatcher) removeWaiter(key string, ch chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	waiters := w.waiters[key]
+	for i, waiter := range waiters {
+		if waiter == ch {
+			w.waiters[key] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(w.waiters[key]) == 0 {
+		delete(w.waiters, key)
+	}
+}
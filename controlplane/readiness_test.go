@@ -0,0 +1,94 @@
+package controlplane
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tokenforge/llm-infra-bench/controlplane/k8s"
+)
+
+// staticReadiness returns a readiness check that always reports state
+func staticReadiness(state string) func(context.Context, *appsv1.Deployment, string) (string, error) {
+	return func(context.Context, *appsv1.Deployment, string) (string, error) {
+		return state, nil
+	}
+}
+
+func testDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "worker-vllm-m", Namespace: "default"}}
+}
+
+func TestReadinessWatcherMarksDeployingEntriesReady(t *testing.T) {
+	registry := NewRegistry()
+	registry.Set(Entry{Model: "m", Runtime: "vllm", Status: "deploying", Namespace: "default", Deployment: "worker-vllm-m", Service: "worker-vllm-m"})
+	registry.Set(Entry{Model: "other", Runtime: "vllm", Status: "deploying", Namespace: "default", Deployment: "worker-vllm-other"})
+
+	w := newReadinessWatcher(registry, staticReadiness(k8s.ReadinessNoEndpoints))
+	w.handle(context.Background(), testDeployment())
+	if entry, _ := registry.Get("m", "vllm"); entry.Status != "no_endpoints" {
+		t.Errorf("Expected no_endpoints while the service has no endpoints, got %s", entry.Status)
+	}
+
+	w.readiness = staticReadiness(k8s.ReadinessReady)
+	w.handle(context.Background(), testDeployment())
+	if entry, _ := registry.Get("m", "vllm"); entry.Status != "ready" {
+		t.Errorf("Expected the entry to be marked ready, got %s", entry.Status)
+	}
+	if entry, _ := registry.Get("other", "vllm"); entry.Status != "deploying" {
+		t.Errorf("Expected entries for other deployments to be untouched, got %s", entry.Status)
+	}
+}
+
+func TestReadinessWatcherWaitsForRollout(t *testing.T) {
+	registry := NewRegistry()
+	registry.Set(Entry{Model: "m", Runtime: "vllm", Status: "restarting", Namespace: "default", Deployment: "worker-vllm-m"})
+	w := newReadinessWatcher(registry, staticReadiness(k8s.ReadinessReady))
+
+	done := make(chan error, 1)
+	go func() { done <- w.wait(context.Background(), "m", "vllm") }()
+
+	// A restart is only ready once the rollout has replaced every replica
+	deployment := testDeployment()
+	deployment.Generation = 2
+	deployment.Status = appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 0, ReadyReplicas: 1}
+	w.handle(context.Background(), deployment)
+	select {
+	case err := <-done:
+		t.Fatalf("Expected the wait to continue during the rollout, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	deployment.Status.UpdatedReplicas = 1
+	w.handle(context.Background(), deployment)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected the wait to succeed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the wait to end when the rollout completed")
+	}
+	if entry, _ := registry.Get("m", "vllm"); entry.Status != "ready" {
+		t.Errorf("Expected the entry to be marked ready, got %s", entry.Status)
+	}
+}
+
+func TestReadinessWatcherWaitReportsNoEndpoints(t *testing.T) {
+	registry := NewRegistry()
+	registry.Set(Entry{Model: "m", Runtime: "vllm", Status: "no_endpoints", Namespace: "default", Deployment: "worker-vllm-m", Service: "worker-vllm-m"})
+	w := newReadinessWatcher(registry, staticReadiness(k8s.ReadinessNoEndpoints))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.wait(ctx, "m", "vllm"); !errors.Is(err, k8s.ErrNoEndpoints) {
+		t.Errorf("Expected ErrNoEndpoints, got %v", err)
+	}
+	if len(w.waiters) != 0 {
+		t.Errorf("Expected the waiter to be removed, got %d", len(w.waiters))
+	}
+}
@@ -2,19 +2,72 @@ package controlplane
 
 import (
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 )
 
-// Registry is a thread-safe registry for mapping models and runtimes to service URLs
+// Entry describes a model and runtime pair tracked by the registry
+type Entry struct {
+	Model      string `json:"model"`
+	Runtime    string `json:"runtime"`
+	Quant      string `json:"quant"`
+	ServiceURL string `json:"service_url"`
+	Status     string `json:"status"`
+	Namespace  string `json:"namespace,omitempty"`
+	Deployment string `json:"deployment,omitempty"`
+	Service    string `json:"service,omitempty"`
+	// ConfigHash identifies the effective model and runtime config the worker was deployed from
+	ConfigHash string `json:"config_hash,omitempty"`
+	// Profile is the resource profile the deploy request selected, if any
+	Profile string `json:"profile,omitempty"`
+	// Overrides are the resource overrides the deploy request applied over the runtime config
+	Overrides *ResourceOverrides `json:"overrides,omitempty"`
+	// Paused deployments stay live but receive no inference traffic
+	Paused bool `json:"paused"`
+	// Warm records that warmup requests have been sent since the worker last became ready
+	Warm bool `json:"warm"`
+	// MaxContext is the context window reported by the worker; 0 when unknown
+	MaxContext int `json:"max_context,omitempty"`
+	// MetadataFetched records that the worker's metadata has been queried
+	MetadataFetched bool `json:"-"`
+	// QueueDepth is the number of requests waiting on the worker, valid when QueueDepthKnown
+	QueueDepth      int       `json:"queue_depth"`
+	QueueDepthKnown bool      `json:"queue_depth_known"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ResourceOverrides are per-deploy resource settings that replace the runtime config's values
+type ResourceOverrides struct {
+	GPU *int   `json:"gpu,omitempty"`
+	CPU string `json:"cpu,omitempty"`
+	Mem string `json:"mem,omitempty"`
+}
+
+// Equal reports whether two sets of overrides, either of which may be nil, are the same
+func (o *ResourceOverrides) Equal(other *ResourceOverrides) bool {
+	if o == nil || other == nil {
+		return o == other
+	}
+	if (o.GPU == nil) != (other.GPU == nil) || (o.GPU != nil && *o.GPU != *other.GPU) {
+		return false
+	}
+	return o.CPU == other.CPU && o.Mem == other.Mem
+}
+
+// Registry is a thread-safe registry for mapping models and runtimes to deployments
 type Registry struct {
 	mu    sync.RWMutex
-	store map[string]string
+	store map[string]Entry
+	// readyHooks are called with each entry that becomes ready
+	readyHooks []func(Entry)
 }
 
 // NewRegistry creates a new registry
 func NewRegistry() *Registry {
 	return &Registry{
-		store: make(map[string]string),
+		store: make(map[string]Entry),
 	}
 }
 
@@ -23,19 +76,200 @@ func makeKey(model, runtime string) string {
 	return fmt.Sprintf("%s::%s", model, runtime)
 }
 
-// Set adds or updates a mapping for a model and runtime to a service URL
-func (r *Registry) Set(model, runtime, serviceURL string) {
+// Set adds or updates the entry for its model and runtime pair.
+// The creation time of an existing entry is preserved.
+func (r *Registry) Set(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := makeKey(entry.Model, entry.Runtime)
+	now := time.Now()
+	if existing, found := r.store[key]; found {
+		entry.CreatedAt = existing.CreatedAt
+	} else if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = now
+	}
+	entry.UpdatedAt = now
+	r.store[key] = entry
+}
+
+// OnReady registers fn to be called with an entry whenever SetStatus moves it to ready. Hooks
+// run on the caller's goroutine, so slow work should be started in the background.
+func (r *Registry) OnReady(fn func(Entry)) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.store[makeKey(model, runtime)] = serviceURL
+	r.readyHooks = append(r.readyHooks, fn)
+}
+
+// SetStatus updates the status of an existing entry, returning false if it is not registered
+func (r *Registry) SetStatus(model, runtime, status string) bool {
+	r.mu.Lock()
+	key := makeKey(model, runtime)
+	entry, found := r.store[key]
+	if !found {
+		r.mu.Unlock()
+		return false
+	}
+	becameReady := status == "ready" && entry.Status != "ready"
+	entry.Status = status
+	if status != "ready" {
+		// Restarted or failed pods lose whatever the warmup loaded
+		entry.Warm = false
+	}
+	entry.UpdatedAt = time.Now()
+	r.store[key] = entry
+	hooks := r.readyHooks
+	r.mu.Unlock()
+
+	if becameReady {
+		for _, hook := range hooks {
+			hook(entry)
+		}
+	}
+	return true
+}
+
+// SetPaused pauses or resumes routing to an existing entry, returning false if it is not registered
+func (r *Registry) SetPaused(model, runtime string, paused bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := makeKey(model, runtime)
+	entry, found := r.store[key]
+	if !found {
+		return false
+	}
+	entry.Paused = paused
+	entry.UpdatedAt = time.Now()
+	r.store[key] = entry
+	return true
+}
+
+// SetWarm marks an existing entry as warmed up, returning false if it is not registered
+func (r *Registry) SetWarm(model, runtime string, warm bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := makeKey(model, runtime)
+	entry, found := r.store[key]
+	if !found {
+		return false
+	}
+	entry.Warm = warm
+	entry.UpdatedAt = time.Now()
+	r.store[key] = entry
+	return true
+}
+
+// SetMaxContext caches the worker-reported context window on an existing entry
+func (r *Registry) SetMaxContext(model, runtime string, maxContext int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := makeKey(model, runtime)
+	entry, found := r.store[key]
+	if !found {
+		return false
+	}
+	entry.MaxContext = maxContext
+	entry.MetadataFetched = true
+	r.store[key] = entry
+	return true
 }
 
-// Get retrieves the service URL for a model and runtime pair
-func (r *Registry) Get(model, runtime string) (string, bool) {
+// SetQueueDepth records the worker-reported queue depth on an existing entry. Passing
+// known as false marks the depth as unknown, e.g. for workers that do not export it.
+func (r *Registry) SetQueueDepth(model, runtime string, depth int, known bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := makeKey(model, runtime)
+	entry, found := r.store[key]
+	if !found {
+		return false
+	}
+	if !known {
+		depth = 0
+	}
+	entry.QueueDepth = depth
+	entry.QueueDepthKnown = known
+	r.store[key] = entry
+	return true
+}
+
+// LimitError is returned by Reserve when a deployment limit would be exceeded
+type LimitError struct {
+	// Scope is "model" for the per-model limit or "global" for the overall limit
+	Scope   string
+	Limit   int
+	Current int
+}
+
+func (e *LimitError) Error() string {
+	if e.Scope == "model" {
+		return fmt.Sprintf("per-model deployment limit reached: %d of %d deployments in use", e.Current, e.Limit)
+	}
+	return fmt.Sprintf("global deployment limit reached: %d of %d deployments in use", e.Current, e.Limit)
+}
+
+// Reserve atomically registers a placeholder entry for a new model and runtime pair unless
+// doing so would exceed the per-model or global deployment limits. Limits of zero are
+// unlimited. It reports whether a new entry was created; existing pairs are left untouched
+// and do not count against the limits.
+func (r *Registry) Reserve(entry Entry, maxPerModel, maxTotal int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := makeKey(entry.Model, entry.Runtime)
+	if _, found := r.store[key]; found {
+		return false, nil
+	}
+	if err := r.checkLimitsLocked(entry.Model, maxPerModel, maxTotal); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	r.store[key] = entry
+	return true, nil
+}
+
+// CheckLimits reports whether deploying a model and runtime pair would exceed the deployment
+// limits, without reserving anything. Existing pairs never exceed the limits.
+func (r *Registry) CheckLimits(model, runtime string, maxPerModel, maxTotal int) error {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	url, found := r.store[makeKey(model, runtime)]
-	return url, found
+
+	if _, found := r.store[makeKey(model, runtime)]; found {
+		return nil
+	}
+	return r.checkLimitsLocked(model, maxPerModel, maxTotal)
+}
+
+// checkLimitsLocked checks the limits for a new pair; the caller must hold the lock
+func (r *Registry) checkLimitsLocked(model string, maxPerModel, maxTotal int) error {
+	perModel := 0
+	for _, existing := range r.store {
+		if existing.Model == model {
+			perModel++
+		}
+	}
+	if maxPerModel > 0 && perModel >= maxPerModel {
+		return &LimitError{Scope: "model", Limit: maxPerModel, Current: perModel}
+	}
+	if maxTotal > 0 && len(r.store) >= maxTotal {
+		return &LimitError{Scope: "global", Limit: maxTotal, Current: len(r.store)}
+	}
+	return nil
+}
+
+// Get retrieves the entry for a model and runtime pair
+func (r *Registry) Get(model, runtime string) (Entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, found := r.store[makeKey(model, runtime)]
+	return entry, found
 }
 
 // Delete removes a mapping for a model and runtime pair
@@ -45,6 +279,24 @@ func (r *Registry) Delete(model, runtime string) {
 	delete(r.store, makeKey(model, runtime))
 }
 
+// GetAll returns a snapshot of all registered entries ordered by model and runtime
+func (r *Registry) GetAll() []Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]Entry, 0, len(r.store))
+	for _, entry := range r.store {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Model != result[j].Model {
+			return result[i].Model < result[j].Model
+		}
+		return result[i].Runtime < result[j].Runtime
+	})
+	return result
+}
+
 // List returns all registered model and runtime pairs with their service URLs
 func (r *Registry) List() map[string]string {
 	r.mu.RLock()
@@ -52,7 +304,7 @@ func (r *Registry) List() map[string]string {
 
 	result := make(map[string]string)
 	for k, v := range r.store {
-		result[k] = v
+		result[k] = v.ServiceURL
 	}
 	return result
 }
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AuditEvent is a record of an action that changed cluster or benchmark state
+type AuditEvent struct {
+	Time       time.Time `json:"time"`
+	Action     string    `json:"action"`
+	Identity   string    `json:"identity"`
+	Model      string    `json:"model,omitempty"`
+	Runtime    string    `json:"runtime,omitempty"`
+	RunID      string    `json:"run_id,omitempty"`
+	StatusCode int       `json:"status_code"`
+	Detail     string    `json:"detail,omitempty"`
+}
+
+// RecordAuditEvent stores an audit event. Empty optional fields are stored as NULL.
+func (c *Client) RecordAuditEvent(ctx context.Context, event AuditEvent) error {
+	if c == nil {
+		return ErrNotConnected
+	}
+
+	_, err := c.pool.Exec(
+		ctx,
+		`INSERT INTO audit (created_at, action, identity, model, runtime, run_id, status_code, detail)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), $7, NULLIF($8, ''))`,
+		event.Time, event.Action, event.Identity, event.Model, event.Runtime, event.RunID, event.StatusCode, event.Detail,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Baseline is the run a model's new runs are compared against for regressions
+type Baseline struct {
+	Model     string    `json:"model"`
+	RunID     string    `json:"run_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetBaseline makes a run the baseline of a model, replacing any previous baseline
+func (c *Client) SetBaseline(ctx context.Context, model, runID string) (*Baseline, error) {
+	if c == nil {
+		return nil, ErrNotConnected
+	}
+
+	baseline := Baseline{Model: model, RunID: runID}
+	err := c.pool.QueryRow(
+		ctx,
+		`INSERT INTO baselines (model, run_id) VALUES ($1, $2)
+		ON CONFLICT (model) DO UPDATE SET run_id = EXCLUDED.run_id, updated_at = now()
+		RETURNING updated_at`,
+		model, runID,
+	).Scan(&baseline.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set baseline: %w", err)
+	}
+	return &baseline, nil
+}
+
+// GetBaseline returns the baseline of a model, or nil when none has been set
+func (c *Client) GetBaseline(ctx context.Context, model string) (*Baseline, error) {
+	if c == nil {
+		return nil, ErrNotConnected
+	}
+
+	baseline := Baseline{Model: model}
+	err := c.pool.QueryRow(
+		ctx,
+		"SELECT run_id, updated_at FROM baselines WHERE model = $1",
+		model,
+	).Scan(&baseline.RunID, &baseline.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get baseline: %w", err)
+	}
+	return &baseline, nil
+}
@@ -2,14 +2,23 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"sync/atomic"
+	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// maxCreateRunAttempts bounds how many IDs CreateRun tries before giving up
+const maxCreateRunAttempts = 5
+
+// uniqueViolation is the PostgreSQL error code for a unique constraint violation
+const uniqueViolation = "23505"
+
 // Client represents a PostgreSQL database client
 type Client struct {
 	pool      *pgxpool.Pool
@@ -26,6 +35,10 @@ type Run struct {
 	HTMLUrl    string   `json:"html_url"`
 	CSVUrl     string   `json:"csv_url"`
 	RawUrl     string   `json:"raw_url"`
+	// TraceID tags every request the harness sends for the run
+	TraceID   string    `json:"trace_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // NewClient creates a new database client
@@ -72,36 +85,99 @@ func (c *Client) Close() {
 	}
 }
 
+// Ping checks that the database is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	if c == nil || c.pool == nil {
+		return ErrNotConnected
+	}
+	return c.pool.Ping(ctx)
+}
+
 // GetNextRunID gets the next run ID and increments the counter
 func (c *Client) GetNextRunID() uint64 {
 	return atomic.AddUint64(&c.nextRunID, 1) - 1
 }
 
-// CreateRun creates a new benchmark run
-func (c *Client) CreateRun(ctx context.Context, id, status, model string, runtimes []string, configPath string) error {
-	// Read config YAML
-	configYAML, err := os.ReadFile(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to read config YAML: %w", err)
+// NewRunID allocates the next run ID in its formatted form
+func (c *Client) NewRunID() string {
+	return formatRunID(c.GetNextRunID())
+}
+
+// formatRunID formats a numeric run ID
+func formatRunID(n uint64) string {
+	return fmt.Sprintf("run_%06d", n)
+}
+
+// CreateRun creates a new benchmark run and returns the ID it was stored under.
+// If the ID is already taken (e.g. another replica allocated it concurrently),
+// a fresh ID is allocated and the insert is retried a bounded number of times.
+func (c *Client) CreateRun(ctx context.Context, id, status, model string, runtimes []string, configYAML []byte, traceID string) (string, error) {
+	insert := func(id string) error {
+		_, err := c.pool.Exec(
+			ctx,
+			"INSERT INTO runs (id, status, model, runtimes, config_yaml, trace_id) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))",
+			id, status, model, runtimes, string(configYAML), traceID,
+		)
+		return err
 	}
 
-	// Insert run
-	_, err = c.pool.Exec(
-		ctx,
-		"INSERT INTO runs (id, status, model, runtimes, config_yaml) VALUES ($1, $2, $3, $4, $5)",
-		id, status, model, runtimes, string(configYAML),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to insert run: %w", err)
+	nextID := func() (string, error) {
+		if err := c.resyncRunID(ctx); err != nil {
+			return "", err
+		}
+		return c.NewRunID(), nil
 	}
 
-	return nil
+	return createRunWithRetry(id, insert, nextID)
+}
+
+// createRunWithRetry inserts a run, allocating a new ID via nextID on unique violations
+// and stopping early when no new ID can be allocated
+func createRunWithRetry(id string, insert func(id string) error, nextID func() (string, error)) (string, error) {
+	var err error
+	for attempt := 1; attempt <= maxCreateRunAttempts; attempt++ {
+		err = insert(id)
+		if err == nil {
+			return id, nil
+		}
+		if !isUniqueViolation(err) {
+			return "", fmt.Errorf("failed to insert run: %w", err)
+		}
+		// Only allocate a fresh ID when another attempt will use it
+		if attempt < maxCreateRunAttempts {
+			if id, err = nextID(); err != nil {
+				return "", fmt.Errorf("failed to allocate a new run ID: %w", err)
+			}
+		}
+	}
+	return "", fmt.Errorf("failed to insert run after %d attempts due to duplicate IDs: %w", maxCreateRunAttempts, err)
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// resyncRunID advances the local run ID counter past the highest ID stored in the database
+func (c *Client) resyncRunID(ctx context.Context) error {
+	var maxID uint64
+	err := c.pool.QueryRow(ctx, "SELECT COALESCE(MAX(CAST(SUBSTRING(id FROM 5) AS INTEGER)), 0) FROM runs").Scan(&maxID)
+	if err != nil {
+		return fmt.Errorf("failed to read the highest run ID: %w", err)
+	}
+	for {
+		current := atomic.LoadUint64(&c.nextRunID)
+		if current > maxID || atomic.CompareAndSwapUint64(&c.nextRunID, current, maxID+1) {
+			return nil
+		}
+	}
 }
 
 // UpdateRunStatus updates the status of a benchmark run
 func (c *Client) UpdateRunStatus(ctx context.Context, id, status string, htmlURL, csvURL, rawURL *string) error {
 	// Build query
-	query := "UPDATE runs SET status = $1"
+	query := "UPDATE runs SET status = $1, updated_at = now()"
 	args := []interface{}{status, id}
 	argIndex := 3
 
@@ -134,13 +210,53 @@ func (c *Client) UpdateRunStatus(ctx context.Context, id, status string, htmlURL
 	return nil
 }
 
+// TouchRun records a heartbeat for a run so it is not considered orphaned
+func (c *Client) TouchRun(ctx context.Context, id string) error {
+	_, err := c.pool.Exec(ctx, "UPDATE runs SET updated_at = now() WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to touch run: %w", err)
+	}
+	return nil
+}
+
+// FailStaleRuns marks queued or running runs without a heartbeat for longer than
+// threshold as failed with reason "orphaned", returning the IDs of the runs it updated
+func (c *Client) FailStaleRuns(ctx context.Context, threshold time.Duration) ([]string, error) {
+	rows, err := c.pool.Query(
+		ctx,
+		`UPDATE runs SET status = 'failed', error = 'orphaned', updated_at = now()
+		WHERE status IN ('queued', 'running') AND updated_at < now() - make_interval(secs => $1)
+		RETURNING id`,
+		threshold.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail stale runs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan run ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return ids, nil
+}
+
 // GetRun gets a benchmark run by ID
 func (c *Client) GetRun(ctx context.Context, id string) (*Run, error) {
 	var run Run
 
 	err := c.pool.QueryRow(
 		ctx,
-		"SELECT id, status, model, runtimes, config_yaml, html_url, csv_url, raw_url FROM runs WHERE id = $1",
+		"SELECT id, status, model, runtimes, config_yaml, COALESCE(html_url, ''), COALESCE(csv_url, ''), COALESCE(raw_url, ''), COALESCE(trace_id, ''), created_at, updated_at FROM runs WHERE id = $1",
 		id,
 	).Scan(
 		&run.ID,
@@ -151,6 +267,9 @@ func (c *Client) GetRun(ctx context.Context, id string) (*Run, error) {
 		&run.HTMLUrl,
 		&run.CSVUrl,
 		&run.RawUrl,
+		&run.TraceID,
+		&run.CreatedAt,
+		&run.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -162,13 +281,42 @@ func (c *Client) GetRun(ctx context.Context, id string) (*Run, error) {
 	return &run, nil
 }
 
-// ListRuns lists benchmark runs with pagination
+// ErrUnknownCursor is returned when a runs cursor names a run that does not exist
+var ErrUnknownCursor = errors.New("unknown run cursor")
+
+// runColumns are the columns scanned into a Run by queryRuns
+const runColumns = "id, status, model, runtimes, config_yaml, COALESCE(html_url, ''), COALESCE(csv_url, ''), COALESCE(raw_url, ''), COALESCE(trace_id, ''), created_at, updated_at"
+
+// ListRuns lists benchmark runs with pagination, newest first
 func (c *Client) ListRuns(ctx context.Context, limit, offset int) ([]*Run, error) {
-	rows, err := c.pool.Query(
+	return c.queryRuns(
 		ctx,
-		"SELECT id, status, model, runtimes, config_yaml, html_url, csv_url, raw_url FROM runs ORDER BY created_at DESC LIMIT $1 OFFSET $2",
+		"SELECT "+runColumns+" FROM runs ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2",
 		limit, offset,
 	)
+}
+
+// ListRunsAfter lists up to limit benchmark runs that come after the run afterID in ListRuns
+// order. Unlike offset paging, pages stay stable while new runs are being created.
+func (c *Client) ListRunsAfter(ctx context.Context, afterID string, limit int) ([]*Run, error) {
+	var exists bool
+	if err := c.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM runs WHERE id = $1)", afterID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to look up cursor: %w", err)
+	}
+	if !exists {
+		return nil, ErrUnknownCursor
+	}
+
+	return c.queryRuns(
+		ctx,
+		"SELECT "+runColumns+" FROM runs WHERE (created_at, id) < (SELECT created_at, id FROM runs WHERE id = $1) ORDER BY created_at DESC, id DESC LIMIT $2",
+		afterID, limit,
+	)
+}
+
+// queryRuns runs a query selecting runColumns and scans the rows into runs
+func (c *Client) queryRuns(ctx context.Context, query string, args ...interface{}) ([]*Run, error) {
+	rows, err := c.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list runs: %w", err)
 	}
@@ -186,6 +334,9 @@ func (c *Client) ListRuns(ctx context.Context, limit, offset int) ([]*Run, error
 			&run.HTMLUrl,
 			&run.CSVUrl,
 			&run.RawUrl,
+			&run.TraceID,
+			&run.CreatedAt,
+			&run.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan run: %w", err)
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestCreateRunWithRetryConflictOnFirstInsert(t *testing.T) {
+	var attempted []string
+	insert := func(id string) error {
+		attempted = append(attempted, id)
+		if id == "run_000001" {
+			return &pgconn.PgError{Code: uniqueViolation, Message: "duplicate key value violates unique constraint \"runs_pkey\""}
+		}
+		return nil
+	}
+	nextID := func() (string, error) { return "run_000002", nil }
+
+	id, err := createRunWithRetry("run_000001", insert, nextID)
+	if err != nil {
+		t.Fatalf("Expected retry to succeed, got error: %v", err)
+	}
+	if id != "run_000002" {
+		t.Errorf("Expected run to be stored under fresh ID run_000002, got %s", id)
+	}
+	if len(attempted) != 2 {
+		t.Errorf("Expected 2 insert attempts, got %d: %v", len(attempted), attempted)
+	}
+}
+
+func TestCreateRunWithRetryExhausted(t *testing.T) {
+	attempts := 0
+	insert := func(id string) error {
+		attempts++
+		return &pgconn.PgError{Code: uniqueViolation}
+	}
+	allocated := 0
+	nextID := func() (string, error) {
+		allocated++
+		return "run_000003", nil
+	}
+
+	if _, err := createRunWithRetry("run_000001", insert, nextID); err == nil {
+		t.Fatal("Expected error after exhausting retries")
+	}
+	if attempts != maxCreateRunAttempts {
+		t.Errorf("Expected %d attempts, got %d", maxCreateRunAttempts, attempts)
+	}
+	if allocated != maxCreateRunAttempts-1 {
+		t.Errorf("Expected a fresh ID only before each retry, got %d allocations", allocated)
+	}
+}
+
+func TestCreateRunWithRetryNonConflictError(t *testing.T) {
+	attempts := 0
+	insert := func(id string) error {
+		attempts++
+		return errors.New("connection refused")
+	}
+	nextID := func() (string, error) {
+		t.Fatal("nextID should not be called for non-conflict errors")
+		return "", nil
+	}
+
+	if _, err := createRunWithRetry("run_000001", insert, nextID); err == nil {
+		t.Fatal("Expected error to be surfaced")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestCreateRunWithRetryStopsWhenResyncFails(t *testing.T) {
+	attempts := 0
+	insert := func(id string) error {
+		attempts++
+		return &pgconn.PgError{Code: uniqueViolation}
+	}
+	resyncErr := errors.New("connection reset")
+	nextID := func() (string, error) { return "", resyncErr }
+
+	if _, err := createRunWithRetry("run_000001", insert, nextID); !errors.Is(err, resyncErr) {
+		t.Fatalf("Expected the resync error to be surfaced, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected no retry once a new ID cannot be allocated, got %d attempts", attempts)
+	}
+}
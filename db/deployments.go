@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Deployment records a worker deployment and the config it was built from
+type Deployment struct {
+	Time       time.Time `json:"time"`
+	Model      string    `json:"model"`
+	Runtime    string    `json:"runtime"`
+	Quant      string    `json:"quant"`
+	Namespace  string    `json:"namespace,omitempty"`
+	Deployment string    `json:"deployment,omitempty"`
+	ConfigHash string    `json:"config_hash,omitempty"`
+}
+
+// RecordDeployment stores a deployment. Empty optional fields are stored as NULL.
+func (c *Client) RecordDeployment(ctx context.Context, deployment Deployment) error {
+	if c == nil {
+		return ErrNotConnected
+	}
+
+	_, err := c.pool.Exec(
+		ctx,
+		`INSERT INTO deployments (created_at, model, runtime, quant, namespace, deployment, config_hash)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''))`,
+		deployment.Time, deployment.Model, deployment.Runtime, deployment.Quant,
+		deployment.Namespace, deployment.Deployment, deployment.ConfigHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record deployment: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Inference is a recorded inference request served by a worker
+type Inference struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Model     string    `json:"model"`
+	Runtime   string    `json:"runtime"`
+	// Route is the weighted route that selected this backend, if any
+	Route      string `json:"route,omitempty"`
+	StatusCode int    `json:"status_code"`
+	LatencyMs  int    `json:"latency_ms"`
+	TokensIn   int    `json:"tokens_in"`
+	TokensOut  int    `json:"tokens_out"`
+	Stream     bool   `json:"stream"`
+	Prompt     string `json:"prompt,omitempty"`
+	Output     string `json:"output,omitempty"`
+}
+
+// RecordInference stores an inference record. Empty prompt and output are stored as NULL.
+func (c *Client) RecordInference(ctx context.Context, inf Inference) error {
+	if c == nil {
+		return ErrNotConnected
+	}
+
+	_, err := c.pool.Exec(
+		ctx,
+		`INSERT INTO inferences (model, runtime, route, status_code, latency_ms, tokens_in, tokens_out, stream, prompt, output)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''))`,
+		inf.Model, inf.Runtime, inf.Route, inf.StatusCode, inf.LatencyMs, inf.TokensIn, inf.TokensOut, inf.Stream, inf.Prompt, inf.Output,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record inference: %w", err)
+	}
+
+	return nil
+}
+
+// ListRecentInferences returns the most recent inferences for a model and runtime, newest first
+func (c *Client) ListRecentInferences(ctx context.Context, model, runtime string, limit int) ([]Inference, error) {
+	if c == nil {
+		return nil, ErrNotConnected
+	}
+
+	rows, err := c.pool.Query(
+		ctx,
+		`SELECT id, created_at, model, runtime, COALESCE(route, ''), status_code, latency_ms, tokens_in, tokens_out, stream, COALESCE(prompt, ''), COALESCE(output, '')
+		FROM inferences WHERE model = $1 AND runtime = $2 ORDER BY created_at DESC, id DESC LIMIT $3`,
+		model, runtime, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inferences: %w", err)
+	}
+	defer rows.Close()
+
+	inferences := []Inference{}
+	for rows.Next() {
+		var inf Inference
+		err := rows.Scan(
+			&inf.ID,
+			&inf.CreatedAt,
+			&inf.Model,
+			&inf.Runtime,
+			&inf.Route,
+			&inf.StatusCode,
+			&inf.LatencyMs,
+			&inf.TokensIn,
+			&inf.TokensOut,
+			&inf.Stream,
+			&inf.Prompt,
+			&inf.Output,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan inference: %w", err)
+		}
+		inferences = append(inferences, inf)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return inferences, nil
+}
+
+// InferenceStats aggregates recorded inferences over a time window. Latency and throughput
+// only cover successful requests; TokensPerSecond is output tokens per second of request time.
+type InferenceStats struct {
+	Requests        int     `json:"requests"`
+	Errors          int     `json:"errors"`
+	AvgLatencyMs    float64 `json:"avg_latency_ms"`
+	P50LatencyMs    float64 `json:"p50_latency_ms"`
+	P95LatencyMs    float64 `json:"p95_latency_ms"`
+	P99LatencyMs    float64 `json:"p99_latency_ms"`
+	TokensOut       int64   `json:"tokens_out"`
+	TokensPerSecond float64 `json:"tokens_per_second"`
+}
+
+// GetInferenceStats computes latency percentiles and throughput for inferences recorded within
+// the window. Empty model or runtime match all. A window with no data returns zeros.
+func (c *Client) GetInferenceStats(ctx context.Context, model, runtime string, window time.Duration) (InferenceStats, error) {
+	var stats InferenceStats
+	if c == nil {
+		return stats, ErrNotConnected
+	}
+
+	var latencySumMs int64
+	err := c.pool.QueryRow(
+		ctx,
+		`SELECT
+			count(*),
+			count(*) FILTER (WHERE status_code >= 400),
+			COALESCE(avg(latency_ms) FILTER (WHERE status_code < 400), 0),
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_ms) FILTER (WHERE status_code < 400), 0),
+			COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms) FILTER (WHERE status_code < 400), 0),
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY latency_ms) FILTER (WHERE status_code < 400), 0),
+			COALESCE(sum(tokens_out) FILTER (WHERE status_code < 400), 0),
+			COALESCE(sum(latency_ms) FILTER (WHERE status_code < 400), 0)
+		FROM inferences
+		WHERE created_at >= now() - make_interval(secs => $1)
+			AND ($2 = '' OR model = $2)
+			AND ($3 = '' OR runtime = $3)`,
+		window.Seconds(), model, runtime,
+	).Scan(
+		&stats.Requests,
+		&stats.Errors,
+		&stats.AvgLatencyMs,
+		&stats.P50LatencyMs,
+		&stats.P95LatencyMs,
+		&stats.P99LatencyMs,
+		&stats.TokensOut,
+		&latencySumMs,
+	)
+	if err != nil {
+		return stats, fmt.Errorf("failed to compute inference stats: %w", err)
+	}
+
+	if latencySumMs > 0 {
+		stats.TokensPerSecond = float64(stats.TokensOut) / (float64(latencySumMs) / 1000)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,16 @@
+CREATE TABLE run_results (
+  run_id TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+  runtime TEXT NOT NULL,
+  quant TEXT NOT NULL DEFAULT '',
+  workload TEXT NOT NULL,
+  avg_latency_ms DOUBLE PRECISION,
+  p50_latency_ms DOUBLE PRECISION,
+  p95_latency_ms DOUBLE PRECISION,
+  p99_latency_ms DOUBLE PRECISION,
+  throughput_rps DOUBLE PRECISION,
+  tokens_per_second DOUBLE PRECISION,
+  error_rate DOUBLE PRECISION,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (run_id, runtime, quant, workload)
+);
+CREATE INDEX run_results_workload_idx ON run_results(workload);
@@ -0,0 +1,14 @@
+CREATE TABLE inferences (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  model TEXT NOT NULL,
+  runtime TEXT NOT NULL,
+  status_code INTEGER NOT NULL,
+  latency_ms INTEGER NOT NULL,
+  tokens_in INTEGER NOT NULL DEFAULT 0,
+  tokens_out INTEGER NOT NULL DEFAULT 0,
+  stream BOOLEAN NOT NULL DEFAULT false,
+  prompt TEXT,
+  output TEXT
+);
+CREATE INDEX inferences_model_runtime_created_idx ON inferences(model, runtime, created_at DESC);
@@ -0,0 +1,3 @@
+ALTER TABLE runs ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE runs ADD COLUMN error TEXT;
+CREATE INDEX runs_status_updated_idx ON runs(status, updated_at);
@@ -0,0 +1,12 @@
+CREATE TABLE audit (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  action TEXT NOT NULL,
+  identity TEXT NOT NULL,
+  model TEXT,
+  runtime TEXT,
+  run_id TEXT,
+  status_code INTEGER NOT NULL,
+  detail TEXT
+);
+CREATE INDEX audit_created_idx ON audit(created_at DESC);
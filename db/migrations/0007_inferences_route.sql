@@ -0,0 +1 @@
+ALTER TABLE inferences ADD COLUMN route TEXT;
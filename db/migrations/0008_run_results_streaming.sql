@@ -0,0 +1,7 @@
+ALTER TABLE run_results
+  ADD COLUMN p50_ttft_ms DOUBLE PRECISION,
+  ADD COLUMN p95_ttft_ms DOUBLE PRECISION,
+  ADD COLUMN p99_ttft_ms DOUBLE PRECISION,
+  ADD COLUMN p50_itl_ms DOUBLE PRECISION,
+  ADD COLUMN p95_itl_ms DOUBLE PRECISION,
+  ADD COLUMN p99_itl_ms DOUBLE PRECISION;
@@ -0,0 +1,11 @@
+CREATE TABLE deployments (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  model TEXT NOT NULL,
+  runtime TEXT NOT NULL,
+  quant TEXT NOT NULL,
+  namespace TEXT,
+  deployment TEXT,
+  config_hash TEXT
+);
+CREATE INDEX deployments_model_runtime_idx ON deployments(model, runtime, created_at DESC);
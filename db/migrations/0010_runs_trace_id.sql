@@ -0,0 +1,2 @@
+ALTER TABLE runs ADD COLUMN trace_id TEXT;
+CREATE INDEX runs_trace_id_idx ON runs(trace_id);
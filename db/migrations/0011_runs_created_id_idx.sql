@@ -0,0 +1 @@
+CREATE INDEX runs_created_id_idx ON runs(created_at DESC, id DESC);
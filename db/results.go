@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// ResultMetrics lists the run_results columns that can be used to rank results,
+// mapped to whether a higher value is better
+var ResultMetrics = map[string]bool{
+	"tokens_per_second": true,
+	"throughput_rps":    true,
+	"avg_latency_ms":    false,
+	"p50_latency_ms":    false,
+	"p95_latency_ms":    false,
+	"p99_latency_ms":    false,
+	"error_rate":        false,
+	"p50_ttft_ms":       false,
+	"p95_ttft_ms":       false,
+	"p99_ttft_ms":       false,
+	"p50_itl_ms":        false,
+	"p95_itl_ms":        false,
+	"p99_itl_ms":        false,
+}
+
+// RunResult is a single metric value recorded for a runtime/quant/workload in a run
+type RunResult struct {
+	RunID    string  `json:"run_id"`
+	Runtime  string  `json:"runtime"`
+	Quant    string  `json:"quant,omitempty"`
+	Workload string  `json:"workload"`
+	Value    float64 `json:"value"`
+}
+
+// ListCompletedResults returns the given metric for every result of completed runs of a model.
+// An empty workload matches all workloads. Results without a value for the metric are skipped.
+func (c *Client) ListCompletedResults(ctx context.Context, model, workload, metric string) ([]RunResult, error) {
+	if c == nil {
+		return nil, ErrNotConnected
+	}
+	if _, ok := ResultMetrics[metric]; !ok {
+		return nil, fmt.Errorf("unknown metric %s", metric)
+	}
+
+	// The metric is validated against ResultMetrics above, so it is safe to interpolate
+	query := fmt.Sprintf(`SELECT res.run_id, res.runtime, res.quant, res.workload, res.%s
+		FROM run_results res JOIN runs r ON r.id = res.run_id
+		WHERE r.status = 'completed' AND r.model = $1 AND ($2 = '' OR res.workload = $2) AND res.%s IS NOT NULL`,
+		metric, metric)
+
+	rows, err := c.pool.Query(ctx, query, model, workload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list run results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []RunResult
+	for rows.Next() {
+		var result RunResult
+		if err := rows.Scan(&result.RunID, &result.Runtime, &result.Quant, &result.Workload, &result.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan run result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// ResultSummary holds the summary metrics of one runtime/quant/workload combination in a run
+type ResultSummary struct {
+	Runtime         string
+	Quant           string
+	Workload        string
+	AvgLatencyMs    float64
+	P50LatencyMs    float64
+	P95LatencyMs    float64
+	P99LatencyMs    float64
+	ThroughputRPS   float64
+	TokensPerSecond float64
+	ErrorRate       float64
+	// Streaming holds time-to-first-token and inter-token latency; nil for non-streaming workloads
+	Streaming *StreamingSummary
+}
+
+// StreamingSummary holds the latency percentiles only measured for streaming workloads
+type StreamingSummary struct {
+	P50TTFTMs float64
+	P95TTFTMs float64
+	P99TTFTMs float64
+	P50ITLMs  float64
+	P95ITLMs  float64
+	P99ITLMs  float64
+}
+
+// GetRunResults returns the summary metrics saved for a run, ordered by runtime, quant and workload
+func (c *Client) GetRunResults(ctx context.Context, runID string) ([]ResultSummary, error) {
+	if c == nil {
+		return nil, ErrNotConnected
+	}
+
+	rows, err := c.pool.Query(
+		ctx,
+		`SELECT runtime, quant, workload, COALESCE(avg_latency_ms, 0), COALESCE(p50_latency_ms, 0), COALESCE(p95_latency_ms, 0), COALESCE(p99_latency_ms, 0),
+			COALESCE(throughput_rps, 0), COALESCE(tokens_per_second, 0), COALESCE(error_rate, 0),
+			p50_ttft_ms, p95_ttft_ms, p99_ttft_ms, p50_itl_ms, p95_itl_ms, p99_itl_ms
+		FROM run_results WHERE run_id = $1 ORDER BY runtime, quant, workload`,
+		runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get run results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []ResultSummary
+	for rows.Next() {
+		var res ResultSummary
+		var p50TTFT, p95TTFT, p99TTFT, p50ITL, p95ITL, p99ITL *float64
+		if err := rows.Scan(
+			&res.Runtime, &res.Quant, &res.Workload,
+			&res.AvgLatencyMs, &res.P50LatencyMs, &res.P95LatencyMs, &res.P99LatencyMs,
+			&res.ThroughputRPS, &res.TokensPerSecond, &res.ErrorRate,
+			&p50TTFT, &p95TTFT, &p99TTFT, &p50ITL, &p95ITL, &p99ITL,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan run result: %w", err)
+		}
+		// Only streaming workloads have time-to-first-token values
+		if p50TTFT != nil {
+			res.Streaming = &StreamingSummary{
+				P50TTFTMs: *p50TTFT,
+				P95TTFTMs: valueOrZero(p95TTFT),
+				P99TTFTMs: valueOrZero(p99TTFT),
+				P50ITLMs:  valueOrZero(p50ITL),
+				P95ITLMs:  valueOrZero(p95ITL),
+				P99ITLMs:  valueOrZero(p99ITL),
+			}
+		}
+		results = append(results, res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return results, nil
+}
+
+// valueOrZero returns the value of a nullable column, or 0 when it is NULL
+func valueOrZero(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// SaveRunResults stores the summary metrics of a run, replacing any previously saved values
+func (c *Client) SaveRunResults(ctx context.Context, runID string, results []ResultSummary) error {
+	if c == nil {
+		return ErrNotConnected
+	}
+
+	for _, res := range results {
+		// Streaming columns stay NULL for non-streaming workloads so they never rank
+		var p50TTFT, p95TTFT, p99TTFT, p50ITL, p95ITL, p99ITL *float64
+		if s := res.Streaming; s != nil {
+			p50TTFT, p95TTFT, p99TTFT = &s.P50TTFTMs, &s.P95TTFTMs, &s.P99TTFTMs
+			p50ITL, p95ITL, p99ITL = &s.P50ITLMs, &s.P95ITLMs, &s.P99ITLMs
+		}
+
+		_, err := c.pool.Exec(
+			ctx,
+			`INSERT INTO run_results (run_id, runtime, quant, workload, avg_latency_ms, p50_latency_ms, p95_latency_ms, p99_latency_ms, throughput_rps, tokens_per_second, error_rate,
+				p50_ttft_ms, p95_ttft_ms, p99_ttft_ms, p50_itl_ms, p95_itl_ms, p99_itl_ms)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+			ON CONFLICT (run_id, runtime, quant, workload) DO UPDATE SET
+				avg_latency_ms = EXCLUDED.avg_latency_ms,
+				p50_latency_ms = EXCLUDED.p50_latency_ms,
+				p95_latency_ms = EXCLUDED.p95_latency_ms,
+				p99_latency_ms = EXCLUDED.p99_latency_ms,
+				throughput_rps = EXCLUDED.throughput_rps,
+				tokens_per_second = EXCLUDED.tokens_per_second,
+				error_rate = EXCLUDED.error_rate,
+				p50_ttft_ms = EXCLUDED.p50_ttft_ms,
+				p95_ttft_ms = EXCLUDED.p95_ttft_ms,
+				p99_ttft_ms = EXCLUDED.p99_ttft_ms,
+				p50_itl_ms = EXCLUDED.p50_itl_ms,
+				p95_itl_ms = EXCLUDED.p95_itl_ms,
+				p99_itl_ms = EXCLUDED.p99_itl_ms`,
+			runID, res.Runtime, res.Quant, res.Workload,
+			res.AvgLatencyMs, res.P50LatencyMs, res.P95LatencyMs, res.P99LatencyMs,
+			res.ThroughputRPS, res.TokensPerSecond, res.ErrorRate,
+			p50TTFT, p95TTFT, p99TTFT, p50ITL, p95ITL, p99ITL,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save run result: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Event types published on state changes
+const (
+	DeploymentCreated   = "deployment.created"
+	DeploymentReady     = "deployment.ready"
+	DeploymentFailed    = "deployment.failed"
+	DeploymentPaused    = "deployment.paused"
+	DeploymentResumed   = "deployment.resumed"
+	DeploymentRestarted = "deployment.restarted"
+	DeploymentTeardown  = "deployment.teardown"
+	InferenceCompleted  = "inference.completed"
+	RunStarted          = "run.started"
+	RunCompleted        = "run.completed"
+	RunFailed           = "run.failed"
+)
+
+// Event is a structured lifecycle event
+type Event struct {
+	Type    string                 `json:"type"`
+	Time    time.Time              `json:"time"`
+	Model   string                 `json:"model,omitempty"`
+	Runtime string                 `json:"runtime,omitempty"`
+	RunID   string                 `json:"run_id,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// Publisher sends events to an external system
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
+
+// NoopPublisher discards every event; it is the default when no broker is configured
+type NoopPublisher struct{}
+
+// Publish discards the event
+func (NoopPublisher) Publish(ctx context.Context, event Event) error { return nil }
+
+// Close does nothing
+func (NoopPublisher) Close() error { return nil }
+
+var (
+	mu               sync.RWMutex
+	defaultPublisher Publisher = NoopPublisher{}
+)
+
+// SetDefault replaces the publisher used by Publish
+func SetDefault(p Publisher) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultPublisher = p
+}
+
+// Publish sends an event through the default publisher, stamping its time. Failures are
+// logged rather than returned so state transitions never depend on the broker.
+func Publish(ctx context.Context, event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	mu.RLock()
+	p := defaultPublisher
+	mu.RUnlock()
+
+	if err := p.Publish(ctx, event); err != nil {
+		log.Printf("Failed to publish %s event: %v", event.Type, err)
+	}
+}
+
+// Close closes the default publisher, flushing any pending events
+func Close() error {
+	mu.RLock()
+	p := defaultPublisher
+	mu.RUnlock()
+	return p.Close()
+}
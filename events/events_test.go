@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type recordingPublisher struct {
+	events []Event
+	err    error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event Event) error {
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func TestPublishUsesDefaultPublisher(t *testing.T) {
+	recorder := &recordingPublisher{}
+	SetDefault(recorder)
+	defer SetDefault(NoopPublisher{})
+
+	Publish(context.Background(), Event{Type: DeploymentReady, Model: "test-model", Runtime: "vllm"})
+
+	if len(recorder.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(recorder.events))
+	}
+	if recorder.events[0].Time.IsZero() {
+		t.Errorf("Expected Publish to stamp the event time")
+	}
+}
+
+func TestPublishIgnoresPublisherErrors(t *testing.T) {
+	SetDefault(&recordingPublisher{err: errors.New("broker down")})
+	defer SetDefault(NoopPublisher{})
+
+	// Must not panic or block when the broker fails
+	Publish(context.Background(), Event{Type: RunCompleted, RunID: "run_000001"})
+}
+
+func TestNewPublisherFromEnvDefaultsToNoop(t *testing.T) {
+	t.Setenv("EVENTS_NATS_URL", "")
+
+	publisher, err := NewPublisherFromEnv()
+	if err != nil {
+		t.Fatalf("Expected no error without a broker, got %v", err)
+	}
+	if _, ok := publisher.(NoopPublisher); !ok {
+		t.Errorf("Expected a NoopPublisher, got %T", publisher)
+	}
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/nats-io/nats.go"
+)
+
+// defaultSubjectPrefix is prepended to event types to form NATS subjects
+const defaultSubjectPrefix = "tokenforge"
+
+// NATSPublisher publishes events to NATS on "<prefix>.<event type>" subjects
+type NATSPublisher struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSPublisher connects to a NATS server
+func NewNATSPublisher(url, prefix string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("tokenforge-api"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	if prefix == "" {
+		prefix = defaultSubjectPrefix
+	}
+	return &NATSPublisher{conn: conn, prefix: prefix}, nil
+}
+
+// Publish encodes the event as JSON and publishes it
+func (p *NATSPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	return p.conn.Publish(p.prefix+"."+event.Type, data)
+}
+
+// Close flushes pending events and closes the connection
+func (p *NATSPublisher) Close() error {
+	err := p.conn.Flush()
+	p.conn.Close()
+	return err
+}
+
+// NewPublisherFromEnv returns a NATS publisher when EVENTS_NATS_URL is set, using
+// EVENTS_SUBJECT_PREFIX for subjects, and a no-op publisher otherwise
+func NewPublisherFromEnv() (Publisher, error) {
+	url := os.Getenv("EVENTS_NATS_URL")
+	if url == "" {
+		return NoopPublisher{}, nil
+	}
+	return NewNATSPublisher(url, os.Getenv("EVENTS_SUBJECT_PREFIX"))
+}
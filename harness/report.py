@@ -4,6 +4,35 @@ import json
 from typing import Dict, List, Any
 from datetime import datetime
 
+def _variant_label(result: Dict[str, Any]) -> str:
+    """
+    Build the display label for a result, distinguishing quant variants of a runtime.
+    
+    Args:
+        result: Workload result for a single runtime/quant variant
+        
+    Returns:
+        "runtime" or "runtime (quant)" when the result was run with an explicit quant
+    """
+    quant = result.get("quant")
+    if quant:
+        return f"{result['runtime']} ({quant})"
+    return result["runtime"]
+
+def _streaming_cells(summary: Dict[str, Any]) -> str:
+    """
+    Render the TTFT, inter-token latency and token rate cells of a streaming result.
+    
+    Args:
+        summary: Summary metrics of a streaming workload result
+        
+    Returns:
+        HTML table cells in the order of the streaming column headers
+    """
+    keys = ["p50_ttft_ms", "p95_ttft_ms", "p50_inter_token_latency_ms", "p95_inter_token_latency_ms", "p99_inter_token_latency_ms", "avg_token_gen_rate"]
+    return "".join(f"<td>{summary.get(key, 0):.2f}</td>" for key in keys)
+
+
 def _generate_memory_chart_js(workload_name: str, workload_results: List[Dict[str, Any]]) -> str:
     """
     Generate JavaScript for memory usage chart.
@@ -24,7 +53,7 @@ def _generate_memory_chart_js(workload_name: str, workload_results: List[Dict[st
     memory_datasets = []
     for i, result in enumerate(workload_results):
         if "memory_profile" in result:
-            runtime = result["runtime"]
+            runtime = _variant_label(result)
             profile = result["memory_profile"]
             
             # CPU memory
@@ -241,8 +270,12 @@ def generate_report(results: Dict[str, Any], output_path: str) -> None:
                 <h2>Workload: {workload_name}</h2>
         """
         
+        # Streaming columns are only shown for workloads run with stream: true
+        has_streaming = any(r.get("stream", False) for r in workload_results)
+        has_any_evaluation = any('rouge' in k or 'bleu' in k or 'factual' in k for r in workload_results for k in r["summary"].keys())
+        
         # Add comparison table
-        html += """
+        html += f"""
                 <h3>Runtime Comparison</h3>
                 <table>
                     <thead>
@@ -253,16 +286,15 @@ def generate_report(results: Dict[str, Any], output_path: str) -> None:
                             <th>p99 Latency (ms)</th>
                             <th>Tokens/sec</th>
                             <th>Error Rate</th>
-                            {f'<th>TTFT (ms)</th>' if result.get('stream', False) else ''}
-                            {f'<th>Token Rate</th>' if result.get('stream', False) else ''}
-                            {'<th>Quality</th>' if any('rouge' in k or 'bleu' in k or 'factual' in k for k in result['summary'].keys()) else ''}
+                            {'<th>p50 TTFT (ms)</th><th>p95 TTFT (ms)</th><th>p50 ITL (ms)</th><th>p95 ITL (ms)</th><th>p99 ITL (ms)</th><th>Token Rate</th>' if has_streaming else ''}
+                            {'<th>Quality</th>' if has_any_evaluation else ''}
                         </tr>
                     </thead>
                     <tbody>
         """
         
         for result in workload_results:
-            runtime = result["runtime"]
+            runtime = _variant_label(result)
             summary = result["summary"]
             is_streaming = result.get("stream", False)
             has_evaluation = any('rouge' in k or 'bleu' in k or 'factual' in k for k in summary.keys())
@@ -295,9 +327,8 @@ def generate_report(results: Dict[str, Any], output_path: str) -> None:
                             <td>{summary["p99_latency_ms"]:.2f}</td>
                             <td>{summary["tokens_per_second"]:.2f}</td>
                             <td>{summary["error_rate"]*100:.2f}%</td>
-                            {f'<td>{summary.get("avg_ttft_ms", 0):.2f}</td>' if is_streaming else ''}
-                            {f'<td>{summary.get("avg_token_gen_rate", 0):.2f}</td>' if is_streaming else ''}
-                            {f'<td>{quality_score:.4f}</td>' if has_evaluation else ''}
+                            {_streaming_cells(summary) if is_streaming else ('<td>-</td>' * 6 if has_streaming else '')}
+                            {f'<td>{quality_score:.4f}</td>' if has_evaluation else ('<td>-</td>' if has_any_evaluation else '')}
                         </tr>
             """
         
@@ -336,7 +367,7 @@ def generate_report(results: Dict[str, Any], output_path: str) -> None:
         """
         
         for result in workload_results:
-            runtime = result["runtime"]
+            runtime = _variant_label(result)
             summary = result["summary"]
             
             # Check if we have evaluation metrics
@@ -383,6 +414,46 @@ def generate_report(results: Dict[str, Any], output_path: str) -> None:
         
         html += """
                 </div>
+        """
+        
+        # Add per-step metrics for ramping workloads
+        ramp_results = [result for result in workload_results if result.get("steps")]
+        if ramp_results:
+            html += """
+                <h3>QPS Ramp</h3>
+                <table>
+                    <thead>
+                        <tr>
+                            <th>Runtime</th>
+                            <th>QPS</th>
+                            <th>p50 Latency (ms)</th>
+                            <th>p95 Latency (ms)</th>
+                            <th>Tokens/sec</th>
+                            <th>Error Rate</th>
+                        </tr>
+                    </thead>
+                    <tbody>
+            """
+            for result in ramp_results:
+                runtime = _variant_label(result)
+                for step in result["steps"]:
+                    step_summary = step["summary"]
+                    html += f"""
+                        <tr>
+                            <td>{runtime}</td>
+                            <td>{step["qps"]}</td>
+                            <td>{step_summary["p50_latency_ms"]:.2f}</td>
+                            <td>{step_summary["p95_latency_ms"]:.2f}</td>
+                            <td>{step_summary["tokens_per_second"]:.2f}</td>
+                            <td>{step_summary["error_rate"]*100:.2f}%</td>
+                        </tr>
+                    """
+            html += """
+                    </tbody>
+                </table>
+            """
+        
+        html += """
             </div>
         """
     
@@ -392,7 +463,7 @@ def generate_report(results: Dict[str, Any], output_path: str) -> None:
     """
     
     for workload_name, workload_results in results["workloads"].items():
-        runtimes = [result["runtime"] for result in workload_results]
+        runtimes = [_variant_label(result) for result in workload_results]
         p50_latencies = [result["summary"]["p50_latency_ms"] for result in workload_results]
         p95_latencies = [result["summary"]["p95_latency_ms"] for result in workload_results]
         throughputs = [result["summary"]["tokens_per_second"] for result in workload_results]
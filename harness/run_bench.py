@@ -8,6 +8,9 @@ import argparse
 import asyncio
 import logging
 import statistics
+import secrets
+import uuid
+import urllib.parse
 from typing import Dict, List, Any
 from datetime import datetime
 
@@ -29,9 +32,17 @@ from harness.evaluation import evaluate_response
 from harness.evaluation.references import get_reference_for_question, get_reference_for_logical_problem, get_reference_for_code
 from harness.profiling import MemoryProfiler
 
+# Measured requests opt out of gateway retries: a retried request reports the latency of
+# every attempt and hides the failure, which skews latency percentiles and error rates
+MEASUREMENT_HEADERS = {"X-No-Retry": "true"}
+
+# Sent on requests to the API when it requires authentication; never sent to worker endpoints
+API_HEADERS = {"Authorization": f"Bearer {os.environ['API_KEY']}"} if os.environ.get("API_KEY") else {}
+
 class BenchmarkRunner:
-    def __init__(self, run_id: str, config_path: str):
+    def __init__(self, run_id: str, config_path: str, trace_id: str = None):
         self.run_id = run_id
+        self.trace_id = trace_id or uuid.uuid4().hex
         self.config_path = config_path
         self.config = self._load_config()
         self.api_url = os.environ.get("API_URL", "http://localhost:8080/api/v1")
@@ -39,12 +50,25 @@ class BenchmarkRunner:
         self.s3_bucket = os.environ.get("S3_BUCKET", "tokenforge-benchmarks")
         self.results = {
             "run_id": run_id,
+            "trace_id": self.trace_id,
             "model": self.config["model"],
             "runtimes": self.config["runtimes"],
+            "quants": self.config.get("quants", {}),
             "timestamp": datetime.now().isoformat(),
             "workloads": {},
         }
     
+    def _request_headers(self, request_id: str) -> Dict[str, str]:
+        """Headers for a measured request, tagging it with the run and trace IDs so worker
+        logs can be correlated with this run."""
+        return {
+            **MEASUREMENT_HEADERS,
+            "X-Run-ID": self.run_id,
+            "X-Trace-ID": self.trace_id,
+            "X-Request-ID": request_id,
+            "traceparent": f"00-{self.trace_id}-{secrets.token_hex(8)}-01",
+        }
+
     def _load_config(self) -> Dict:
         """Load benchmark configuration from YAML file."""
         try:
@@ -69,17 +93,18 @@ class BenchmarkRunner:
             logger.error(f"Failed to initialize S3 client: {e}")
             return None
     
-    async def deploy_model(self, runtime: str) -> str:
-        """Deploy a model with the specified runtime."""
-        logger.info(f"Deploying model {self.config['model']} with runtime {runtime}")
+    async def deploy_model(self, runtime: str, quant: str = None) -> str:
+        """Deploy a model with the specified runtime and quantization."""
+        quant = quant or "fp16"  # Default to fp16
+        logger.info(f"Deploying model {self.config['model']} with runtime {runtime} ({quant})")
         
-        async with httpx.AsyncClient(timeout=600) as client:
+        async with httpx.AsyncClient(timeout=600, headers=API_HEADERS) as client:
             response = await client.post(
                 f"{self.api_url}/deploy",
                 json={
                     "model": self.config["model"],
                     "runtime": runtime,
-                    "quant": "fp16",  # Default to fp16
+                    "quant": quant,
                 }
             )
             
@@ -101,7 +126,7 @@ class BenchmarkRunner:
                     json={
                         "model": self.config["model"],
                         "runtime": runtime,
-                        "quant": "fp16",
+                        "quant": quant,
                     }
                 )
                 
@@ -114,6 +139,22 @@ class BenchmarkRunner:
             logger.info(f"Model deployed successfully at {endpoint}")
             return endpoint
     
+    async def teardown_model(self, runtime: str) -> bool:
+        """Tear down the model's worker for a runtime, so the next quant variant gets its own."""
+        logger.info(f"Tearing down model {self.config['model']} with runtime {runtime}")
+
+        # Model names such as org/name contain a slash, which must not split the path
+        model = urllib.parse.quote(self.config['model'], safe="")
+        async with httpx.AsyncClient(timeout=600, headers=API_HEADERS) as client:
+            response = await client.delete(
+                f"{self.api_url}/deployments/{model}",
+                params={"runtime": runtime},
+            )
+            if response.status_code != 200:
+                logger.error(f"Failed to tear down model: {response.text}")
+                return False
+            return True
+
     async def warmup(self, endpoint: str, count: int = 5) -> bool:
         """Perform warmup requests to the model."""
         logger.info(f"Warming up model with {count} requests")
@@ -141,9 +182,12 @@ class BenchmarkRunner:
         logger.info("Warmup complete")
         return True
     
-    async def run_workload(self, endpoint: str, runtime: str, workload: Dict) -> Dict:
+    async def run_workload(self, endpoint: str, runtime: str, workload: Dict, quant: str = None) -> Dict:
         """Run a single workload against a model endpoint."""
-        logger.info(f"Running workload {workload['name']} against {runtime}")
+        if workload.get("profile"):
+            return await self._run_ramp_workload(endpoint, runtime, workload, quant)
+        
+        logger.info(f"Running workload {workload['name']} against {runtime}" + (f" ({quant})" if quant else ""))
         
         # Generate prompts based on workload parameters
         prompts = self._generate_prompts(workload["name"], workload["prompt_len"], 100)
@@ -155,6 +199,7 @@ class BenchmarkRunner:
         results = {
             "name": workload["name"],
             "runtime": runtime,
+            "quant": quant,
             "qps": workload["qps"],
             "duration_s": workload["duration_s"],
             "prompt_len": workload["prompt_len"],
@@ -200,6 +245,42 @@ class BenchmarkRunner:
         
         return results
         
+    async def _run_ramp_workload(self, endpoint: str, runtime: str, workload: Dict, quant: str = None) -> Dict:
+        """Run a ramping workload as consecutive constant-QPS steps, keeping metrics per step."""
+        steps = []
+        requests = []
+        for step in workload["profile"]:
+            step_workload = {k: v for k, v in workload.items() if k not in ("profile", "ramp")}
+            step_workload["qps"] = step["qps"]
+            step_workload["duration_s"] = step["duration_s"]
+            
+            step_result = await self.run_workload(endpoint, runtime, step_workload, quant)
+            steps.append({
+                "qps": step["qps"],
+                "duration_s": step["duration_s"],
+                "summary": step_result["summary"],
+            })
+            requests.extend(step_result["requests"])
+        
+        is_streaming = workload.get("stream", False)
+        results = {
+            "name": workload["name"],
+            "runtime": runtime,
+            "quant": quant,
+            "qps": workload["profile"][-1]["qps"],
+            "duration_s": workload["duration_s"],
+            "prompt_len": workload["prompt_len"],
+            "gen_tokens": workload["gen_tokens"],
+            "stream": is_streaming,
+            "ramp": workload.get("ramp"),
+            "steps": steps,
+            "requests": requests,
+        }
+        
+        if is_streaming:
+            return self._calculate_streaming_metrics(results, workload)
+        return self._calculate_metrics(results, workload)
+    
     async def _run_regular_workload(self, endpoint: str, prompts: List[str], workload: Dict, results: Dict, delay: float, end_time: float) -> Dict:
         """Run a regular (non-streaming) workload."""
         request_count = 0
@@ -210,6 +291,8 @@ class BenchmarkRunner:
                 prompt_idx = request_count % len(prompts)
                 prompt = prompts[prompt_idx]
                 
+                request_id = f"{self.trace_id}-{request_count}"
+
                 # Record request start time
                 request_start = time.time()
                 
@@ -223,7 +306,8 @@ class BenchmarkRunner:
                             "temperature": 0.2,
                             "top_p": 0.95,
                             "stream": False,
-                        }
+                        },
+                        headers=self._request_headers(request_id),
                     )
                     
                     request_end = time.time()
@@ -266,6 +350,7 @@ class BenchmarkRunner:
                         # Record request metrics
                         request_data = {
                             "id": request_count,
+                            "request_id": request_id,
                             "latency_ms": data["latency_ms"],
                             "tokens_in": data["tokens_in"],
                             "tokens_out": data["tokens_out"],
@@ -285,6 +370,7 @@ class BenchmarkRunner:
                         # Record error
                         results["requests"].append({
                             "id": request_count,
+                            "request_id": request_id,
                             "latency_ms": int((request_end - request_start) * 1000),
                             "tokens_in": 0,
                             "tokens_out": 0,
@@ -296,6 +382,7 @@ class BenchmarkRunner:
                     # Record error
                     results["requests"].append({
                         "id": request_count,
+                        "request_id": request_id,
                         "latency_ms": int((request_end - request_start) * 1000),
                         "tokens_in": 0,
                         "tokens_out": 0,
@@ -322,6 +409,8 @@ class BenchmarkRunner:
                 prompt_idx = request_count % len(prompts)
                 prompt = prompts[prompt_idx]
                 
+                request_id = f"{self.trace_id}-{request_count}"
+
                 # Record request start time
                 request_start = time.time()
                 
@@ -337,16 +426,21 @@ class BenchmarkRunner:
                             "top_p": 0.95,
                             "stream": True,
                         },
+                        headers=self._request_headers(request_id),
                         timeout=60
                     ) as response:
                         if response.status_code != 200:
                             # Record error
                             results["requests"].append({
                                 "id": request_count,
+                                "request_id": request_id,
                                 "latency_ms": int((time.time() - request_start) * 1000),
                                 "tokens_in": 0,
                                 "tokens_out": 0,
                                 "ttft_ms": 0,
+                                "inter_token_latency_ms": 0,
+                                "inter_token_latencies_ms": [],
+                                "token_gen_rate": 0,
                                 "error": await response.text(),
                             })
                         else:
@@ -422,11 +516,13 @@ class BenchmarkRunner:
                             # Record request metrics
                             request_data = {
                                 "id": request_count,
+                                "request_id": request_id,
                                 "latency_ms": total_latency_ms,
                                 "ttft_ms": ttft_ms,
                                 "tokens_in": len(prompt.split()),
                                 "tokens_out": len(tokens),
                                 "inter_token_latency_ms": statistics.mean(inter_token_latencies) if inter_token_latencies else 0,
+                                "inter_token_latencies_ms": inter_token_latencies,
                                 "token_gen_rate": len(tokens) / (last_token_time - first_token_time) if first_token_time and last_token_time and first_token_time != last_token_time else 0,
                                 "error": None,
                             }
@@ -443,11 +539,13 @@ class BenchmarkRunner:
                     # Record error
                     results["requests"].append({
                         "id": request_count,
+                        "request_id": request_id,
                         "latency_ms": int((request_end - request_start) * 1000),
                         "ttft_ms": 0,
                         "tokens_in": 0,
                         "tokens_out": 0,
                         "inter_token_latency_ms": 0,
+                        "inter_token_latencies_ms": [],
                         "token_gen_rate": 0,
                         "error": str(e),
                     })
@@ -501,6 +599,9 @@ class BenchmarkRunner:
     
     def _calculate_streaming_metrics(self, results: Dict, workload: Dict) -> Dict:
         """Calculate metrics for streaming workloads."""
+        if not workload.get("stream", False):
+            raise ValueError(f"workload {workload['name']} is not a streaming workload; TTFT and inter-token latency need stream: true")
+        
         # Standard metrics
         latencies = [r["latency_ms"] for r in results["requests"] if r["error"] is None]
         tokens_in = sum(r["tokens_in"] for r in results["requests"] if r["error"] is None)
@@ -509,7 +610,8 @@ class BenchmarkRunner:
         
         # Streaming-specific metrics
         ttfts = [r["ttft_ms"] for r in results["requests"] if r["error"] is None and r["ttft_ms"] > 0]
-        inter_token_latencies = [r["inter_token_latency_ms"] for r in results["requests"] if r["error"] is None and r["inter_token_latency_ms"] > 0]
+        # Percentiles are taken over every gap between tokens, not the per-request means
+        inter_token_latencies = [gap for r in results["requests"] if r["error"] is None for gap in r.get("inter_token_latencies_ms", [])]
         token_gen_rates = [r["token_gen_rate"] for r in results["requests"] if r["error"] is None and r["token_gen_rate"] > 0]
         
         summary = {
@@ -527,7 +629,11 @@ class BenchmarkRunner:
             # Streaming-specific metrics
             "p50_ttft_ms": statistics.median(ttfts) if ttfts else 0,
             "p95_ttft_ms": statistics.quantiles(ttfts, n=20)[18] if len(ttfts) >= 20 else (max(ttfts) if ttfts else 0),
+            "p99_ttft_ms": statistics.quantiles(ttfts, n=100)[98] if len(ttfts) >= 100 else (max(ttfts) if ttfts else 0),
             "avg_ttft_ms": statistics.mean(ttfts) if ttfts else 0,
+            "p50_inter_token_latency_ms": statistics.median(inter_token_latencies) if inter_token_latencies else 0,
+            "p95_inter_token_latency_ms": statistics.quantiles(inter_token_latencies, n=20)[18] if len(inter_token_latencies) >= 20 else (max(inter_token_latencies) if inter_token_latencies else 0),
+            "p99_inter_token_latency_ms": statistics.quantiles(inter_token_latencies, n=100)[98] if len(inter_token_latencies) >= 100 else (max(inter_token_latencies) if inter_token_latencies else 0),
             "avg_inter_token_latency_ms": statistics.mean(inter_token_latencies) if inter_token_latencies else 0,
             "avg_token_gen_rate": statistics.mean(token_gen_rates) if token_gen_rates else 0,
         }
@@ -717,26 +823,34 @@ class BenchmarkRunner:
         output_dir = os.path.join("/tmp", self.run_id)
         os.makedirs(output_dir, exist_ok=True)
         
-        # Run benchmarks for each runtime
+        # Run benchmarks for each runtime and quantization variant
         for runtime in self.config["runtimes"]:
-            # Deploy the model
-            endpoint = await self.deploy_model(runtime)
-            if not endpoint:
-                logger.error(f"Failed to deploy model for runtime {runtime}")
-                continue
-            
-            # Warm up the model
-            await self.warmup(endpoint)
-            
-            # Run each workload
-            for workload in self.config["workloads"]:
-                workload_result = await self.run_workload(endpoint, runtime, workload)
+            quants = (self.config.get("quants") or {}).get(runtime) or [None]
+            for i, quant in enumerate(quants):
+                # A runtime has one worker per model, so the previous variant must go first or
+                # the deploy would adopt it and record its results under the wrong quant
+                if i > 0 and not await self.teardown_model(runtime):
+                    logger.error(f"Skipping remaining quants of runtime {runtime}")
+                    break
+
+                # Deploy the model
+                endpoint = await self.deploy_model(runtime, quant)
+                if not endpoint:
+                    logger.error(f"Failed to deploy model for runtime {runtime}" + (f" ({quant})" if quant else ""))
+                    continue
                 
-                # Store results
-                if workload["name"] not in self.results["workloads"]:
-                    self.results["workloads"][workload["name"]] = []
+                # Warm up the model
+                await self.warmup(endpoint)
                 
-                self.results["workloads"][workload["name"]].append(workload_result)
+                # Run each workload
+                for workload in self.config["workloads"]:
+                    workload_result = await self.run_workload(endpoint, runtime, workload, quant)
+                    
+                    # Store results
+                    if workload["name"] not in self.results["workloads"]:
+                        self.results["workloads"][workload["name"]] = []
+                    
+                    self.results["workloads"][workload["name"]].append(workload_result)
         
         # Save results
         self._save_results(output_dir)
@@ -762,13 +876,15 @@ class BenchmarkRunner:
         csv_path = os.path.join(output_dir, "summary.csv")
         with open(csv_path, "w") as f:
             # Write header
-            f.write("workload,runtime,p50_latency_ms,p95_latency_ms,p99_latency_ms,tokens_per_second,error_rate\n")
+            f.write("workload,runtime,quant,p50_latency_ms,p95_latency_ms,p99_latency_ms,tokens_per_second,error_rate,p50_ttft_ms,p95_ttft_ms,p50_inter_token_latency_ms,p95_inter_token_latency_ms\n")
             
-            # Write data
+            # Write data; streaming columns are left empty for non-streaming workloads
+            streaming_keys = ["p50_ttft_ms", "p95_ttft_ms", "p50_inter_token_latency_ms", "p95_inter_token_latency_ms"]
             for workload_name, workload_results in self.results["workloads"].items():
                 for result in workload_results:
                     summary = result["summary"]
-                    f.write(f"{workload_name},{result['runtime']},{summary['p50_latency_ms']},{summary['p95_latency_ms']},{summary['p99_latency_ms']},{summary['tokens_per_second']},{summary['error_rate']}\n")
+                    streaming = ",".join(str(summary.get(key, "")) if result.get("stream", False) else "" for key in streaming_keys)
+                    f.write(f"{workload_name},{result['runtime']},{result.get('quant') or ''},{summary['p50_latency_ms']},{summary['p95_latency_ms']},{summary['p99_latency_ms']},{summary['tokens_per_second']},{summary['error_rate']},{streaming}\n")
         
         logger.info(f"Results saved to {output_dir}")
     
@@ -811,9 +927,10 @@ async def main():
     parser = argparse.ArgumentParser(description="TokenForge Benchmark Runner")
     parser.add_argument("--run-id", type=str, default=f"run_{int(time.time())}", help="Unique ID for this benchmark run")
     parser.add_argument("--config", type=str, required=True, help="Path to benchmark configuration file")
+    parser.add_argument("--trace-id", type=str, default=None, help="Trace ID attached to every worker request (generated if omitted)")
     args = parser.parse_args()
     
-    runner = BenchmarkRunner(args.run_id, args.config)
+    runner = BenchmarkRunner(args.run_id, args.config, args.trace_id)
     await runner.run_benchmark()
 
 if __name__ == "__main__":
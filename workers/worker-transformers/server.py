@@ -8,9 +8,9 @@ from typing import Dict, List, Optional, Union
 import torch
 import uvicorn
 from fastapi import FastAPI, HTTPException
-from fastapi.responses import JSONResponse
+from fastapi.responses import JSONResponse, Response
 from pydantic import BaseModel, Field
-from prometheus_client import start_http_server, Counter, Histogram, Gauge
+from prometheus_client import start_http_server, Counter, Histogram, Gauge, generate_latest, CONTENT_TYPE_LATEST
 
 # Import Transformers components
 from transformers import AutoModelForCausalLM, AutoTokenizer, TextGenerationPipeline
@@ -25,6 +25,26 @@ logger = logging.getLogger(__name__)
 # Create FastAPI app
 app = FastAPI(title="Transformers Worker")
 
+# Requests the worker has accepted but not yet answered; scraped by the API for routing
+queue_depth = Gauge(
+    "tokenforge_worker_queue_depth", "Inference requests queued or in progress", ["engine"]
+)
+
+@app.middleware("http")
+async def track_queue_depth(request, call_next):
+    if request.url.path != "/infer":
+        return await call_next(request)
+    with queue_depth.labels(engine="transformers").track_inprogress():
+        response = await call_next(request)
+    # Benchmark runs tag each request so worker logs can be correlated with the run
+    trace_id = request.headers.get("x-trace-id")
+    if trace_id:
+        logger.info(
+            f"infer run_id={request.headers.get('x-run-id', '')} trace_id={trace_id} "
+            f"request_id={request.headers.get('x-request-id', '')} status={response.status_code}"
+        )
+    return response
+
 # Initialize metrics
 inference_requests = Counter(
     "inference_requests_total", "Total number of inference requests", ["engine"]
@@ -66,6 +86,8 @@ TOKENIZER = None
 PIPELINE = None
 MODEL_NAME = os.environ.get("MODEL_NAME", "meta-llama/Llama-3-8b-instruct")
 QUANT = os.environ.get("QUANT", "fp16")
+# CPU_ONLY is set by the control plane when a GPU runtime is deployed without accelerators
+USE_CUDA = torch.cuda.is_available() and os.environ.get("CPU_ONLY", "false").lower() != "true"
 
 @app.on_event("startup")
 async def startup_event():
@@ -78,7 +100,7 @@ async def startup_event():
         TOKENIZER = AutoTokenizer.from_pretrained(MODEL_NAME)
         
         # Initialize model with specified precision
-        if QUANT == "fp16" and torch.cuda.is_available():
+        if QUANT == "fp16" and USE_CUDA:
             MODEL = AutoModelForCausalLM.from_pretrained(
                 MODEL_NAME,
                 torch_dtype=torch.float16,
@@ -88,7 +110,7 @@ async def startup_event():
         else:
             MODEL = AutoModelForCausalLM.from_pretrained(
                 MODEL_NAME,
-                device_map="auto",
+                device_map="auto" if USE_CUDA else "cpu",
                 trust_remote_code=True,
             )
         
@@ -96,7 +118,7 @@ async def startup_event():
         PIPELINE = TextGenerationPipeline(
             model=MODEL,
             tokenizer=TOKENIZER,
-            device=0 if torch.cuda.is_available() else -1,
+            device=0 if USE_CUDA else -1,
         )
         
         # Start Prometheus metrics server on a different port
@@ -116,11 +138,27 @@ async def healthz():
         return JSONResponse(status_code=503, content={"status": "not_ready"})
     return {"status": "ok"}
 
+@app.get("/ready")
+async def ready():
+    # Only report ready once the model, tokenizer and pipeline are fully loaded
+    if MODEL is None or TOKENIZER is None or PIPELINE is None:
+        return JSONResponse(status_code=503, content={"status": "loading"})
+    return {"status": "ready", "model": MODEL_NAME}
+
+@app.get("/metadata")
+async def metadata():
+    if MODEL is None or TOKENIZER is None:
+        return JSONResponse(status_code=503, content={"status": "loading"})
+    # Prefer the model config; tokenizers use a huge sentinel when no limit is set
+    max_context = getattr(MODEL.config, "max_position_embeddings", None)
+    if max_context is None and TOKENIZER.model_max_length < 1_000_000:
+        max_context = TOKENIZER.model_max_length
+    return {"model": MODEL_NAME, "quant": QUANT, "max_context": max_context or 0}
+
 @app.get("/metrics")
 async def metrics():
-    # This endpoint is just for documentation
-    # The actual metrics are served by the Prometheus client on port 8001
-    return {"message": "Metrics available at :8001/metrics"}
+    # The same metrics are also served by the Prometheus client on port 8001
+    return Response(content=generate_latest(), media_type=CONTENT_TYPE_LATEST)
 
 @app.post("/infer")
 async def infer(request: InferenceRequest):
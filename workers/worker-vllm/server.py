@@ -9,9 +9,9 @@ from typing import Dict, List, Optional, Union
 import torch
 import uvicorn
 from fastapi import FastAPI, HTTPException
-from fastapi.responses import JSONResponse, StreamingResponse
+from fastapi.responses import JSONResponse, Response, StreamingResponse
 from pydantic import BaseModel, Field
-from prometheus_client import start_http_server, Counter, Histogram, Gauge
+from prometheus_client import start_http_server, Counter, Histogram, Gauge, generate_latest, CONTENT_TYPE_LATEST
 import asyncio
 
 # Import vLLM components
@@ -61,6 +61,26 @@ logger = logging.getLogger(__name__)
 # Create FastAPI app
 app = FastAPI(title="vLLM Worker")
 
+# Requests the worker has accepted but not yet answered; scraped by the API for routing
+queue_depth = Gauge(
+    "tokenforge_worker_queue_depth", "Inference requests queued or in progress", ["engine"]
+)
+
+@app.middleware("http")
+async def track_queue_depth(request, call_next):
+    if request.url.path != "/infer":
+        return await call_next(request)
+    with queue_depth.labels(engine="vllm").track_inprogress():
+        response = await call_next(request)
+    # Benchmark runs tag each request so worker logs can be correlated with the run
+    trace_id = request.headers.get("x-trace-id")
+    if trace_id:
+        logger.info(
+            f"infer run_id={request.headers.get('x-run-id', '')} trace_id={trace_id} "
+            f"request_id={request.headers.get('x-request-id', '')} status={response.status_code}"
+        )
+    return response
+
 # Initialize metrics
 inference_requests = Counter(
     "inference_requests_total", "Total number of inference requests", ["engine"]
@@ -131,11 +151,27 @@ async def healthz():
         return JSONResponse(status_code=503, content={"status": "not_ready"})
     return {"status": "ok"}
 
+@app.get("/ready")
+async def ready():
+    # Only report ready once the engine has finished loading the model
+    if ENGINE is None:
+        return JSONResponse(status_code=503, content={"status": "loading"})
+    return {"status": "ready", "model": MODEL_NAME}
+
+@app.get("/metadata")
+async def metadata():
+    if ENGINE is None:
+        return JSONResponse(status_code=503, content={"status": "loading"})
+    # Report the context window the engine actually loaded
+    max_context = getattr(getattr(ENGINE, "model_config", None), "max_model_len", None)
+    if max_context is None:
+        max_context = int(os.environ.get("MAX_MODEL_LEN", "8192"))
+    return {"model": MODEL_NAME, "quant": QUANT, "max_context": max_context}
+
 @app.get("/metrics")
 async def metrics():
-    # This endpoint is just for documentation
-    # The actual metrics are served by the Prometheus client on port 8001
-    return {"message": "Metrics available at :8001/metrics"}
+    # The same metrics are also served by the Prometheus client on port 8001
+    return Response(content=generate_latest(), media_type=CONTENT_TYPE_LATEST)
 
 @app.post("/infer")
 async def infer(request: InferenceRequest):